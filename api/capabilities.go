@@ -0,0 +1,124 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/debug"
+)
+
+const capabilitiesFilename = "capabilities.json"
+
+// Capabilities are the optional features advertised by an API.
+type Capabilities struct {
+	ChunkedUpload   bool `json:"chunked_upload"`
+	Gzip            bool `json:"gzip"`
+	PresignedUpload bool `json:"presigned_upload"`
+	PartialUpdate   bool `json:"partial_update"`
+	// GzipParts means that files may be gzipped individually,
+	// as indicated by a Content-Encoding header on their part.
+	GzipParts bool `json:"gzip_parts"`
+}
+
+// DefaultCapabilities are assumed when an API doesn't advertise its features.
+// They are deliberately conservative.
+var DefaultCapabilities = Capabilities{}
+
+// FetchCapabilities asks the API which optional features it supports.
+// APIs that predate the capabilities endpoint get the default capabilities.
+func (c *Client) FetchCapabilities() (Capabilities, error) {
+	url := fmt.Sprintf("%s/capabilities", c.APIBaseURL)
+	req, err := c.NewRequest("GET", url, nil)
+	if err != nil {
+		return DefaultCapabilities, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return DefaultCapabilities, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode == http.StatusNotFound {
+		return DefaultCapabilities, nil
+	}
+	if res.StatusCode != http.StatusOK {
+		return DefaultCapabilities, fmt.Errorf("API returned %s", res.Status)
+	}
+
+	var payload struct {
+		Capabilities Capabilities `json:"capabilities"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return DefaultCapabilities, fmt.Errorf("unable to parse API response - %s", err)
+	}
+	return payload.Capabilities, nil
+}
+
+// Capabilities returns the features supported by the client's API.
+// The result is cached per API base URL in the given directory, and is
+// only fetched again when refresh is true.
+// It never fails: if the capabilities can't be determined,
+// it falls back to the default capabilities.
+func (c *Client) Capabilities(dir string, refresh bool) Capabilities {
+	cache := readCapabilitiesCache(dir)
+	if caps, ok := cache[c.APIBaseURL]; ok && !refresh {
+		return caps
+	}
+
+	caps, err := c.FetchCapabilities()
+	if err != nil {
+		debug.Printf("Unable to determine API capabilities: %s\n", err)
+		return DefaultCapabilities
+	}
+
+	cache[c.APIBaseURL] = caps
+	if err := writeCapabilitiesCache(dir, cache); err != nil {
+		debug.Printf("Unable to cache API capabilities: %s\n", err)
+	}
+	return caps
+}
+
+// CachedCapabilities returns the cached features of the client's API,
+// or the default capabilities if they have never been fetched.
+// Unlike Capabilities, it never touches the network.
+func (c *Client) CachedCapabilities(dir string) Capabilities {
+	if caps, ok := readCapabilitiesCache(dir)[c.APIBaseURL]; ok {
+		return caps
+	}
+	return DefaultCapabilities
+}
+
+func readCapabilitiesCache(dir string) map[string]Capabilities {
+	cache := map[string]Capabilities{}
+	if dir == "" {
+		return cache
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, capabilitiesFilename))
+	if err != nil {
+		return cache
+	}
+	if err := json.Unmarshal(b, &cache); err != nil {
+		return map[string]Capabilities{}
+	}
+	return cache
+}
+
+func writeCapabilitiesCache(dir string, cache map[string]Capabilities) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	b, err := json.Marshal(cache)
+	if err != nil {
+		return err
+	}
+	// Other runs may be reading it at the same time, so it's replaced in one go.
+	return config.WriteFileAtomic(filepath.Join(dir, capabilitiesFilename), b, os.FileMode(0644))
+}
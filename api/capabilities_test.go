@@ -0,0 +1,79 @@
+package api
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCapabilitiesAreCachedPerBaseURL(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Regexp(t, "/capabilities$", r.URL.Path)
+		calls++
+		fmt.Fprint(w, `{"capabilities": {"gzip": true, "chunked_upload": false}}`)
+	}))
+	defer ts.Close()
+
+	dir, err := ioutil.TempDir("", "capabilities")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	// Nothing cached yet, and nothing fetched.
+	assert.Equal(t, DefaultCapabilities, client.CachedCapabilities(dir))
+	assert.Equal(t, 0, calls)
+
+	caps := client.Capabilities(dir, false)
+	assert.True(t, caps.Gzip)
+	assert.False(t, caps.ChunkedUpload)
+	assert.Equal(t, 1, calls)
+	assert.True(t, client.CachedCapabilities(dir).Gzip)
+	assert.Equal(t, 1, calls)
+
+	// Served from the cache.
+	caps = client.Capabilities(dir, false)
+	assert.True(t, caps.Gzip)
+	assert.Equal(t, 1, calls)
+
+	// Forced re-fetch.
+	caps = client.Capabilities(dir, true)
+	assert.True(t, caps.Gzip)
+	assert.Equal(t, 2, calls)
+
+	// A different API has its own entry.
+	other, err := NewClient("", ts.URL+"/other")
+	assert.NoError(t, err)
+	other.Capabilities(dir, false)
+	assert.Equal(t, 3, calls)
+}
+
+func TestCapabilitiesFallBackToDefaults(t *testing.T) {
+	testCases := []struct {
+		desc   string
+		status int
+	}{
+		{desc: "endpoint does not exist", status: http.StatusNotFound},
+		{desc: "server error", status: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.status)
+			}))
+			defer ts.Close()
+
+			client, err := NewClient("", ts.URL)
+			assert.NoError(t, err)
+			assert.Equal(t, DefaultCapabilities, client.Capabilities("", false))
+		})
+	}
+}
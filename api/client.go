@@ -1,9 +1,13 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/url"
+	"sync"
 	"time"
 
 	"github.com/exercism/cli/debug"
@@ -15,23 +19,93 @@ var (
 	UserAgent = "github.com/exercism/cli"
 
 	// TimeoutInSeconds is the timeout the default HTTP client will use.
+	// It covers the entire request, including uploading the body.
 	TimeoutInSeconds = 60
-	// HTTPClient is the client used to make HTTP calls in the cli package.
-	HTTPClient = &http.Client{Timeout: time.Duration(TimeoutInSeconds) * time.Second}
+	// ConnectTimeoutInSeconds limits how long establishing a connection may take.
+	ConnectTimeoutInSeconds = 10
+	// TLSTimeoutInSeconds limits how long the TLS handshake may take.
+	TLSTimeoutInSeconds = 10
+	// ProxyURL, if set, is the proxy that HTTP calls go through,
+	// rather than the one given in the environment.
+	ProxyURL string
+	// HTTPClient, if set, is the client used by API clients that don't have
+	// one of their own. Otherwise they get one like NewClient's.
+	HTTPClient *http.Client
 )
 
+// transportSettings are the settings that a transport is built with.
+type transportSettings struct {
+	connectTimeout int
+	tlsTimeout     int
+	proxy          string
+}
+
+var (
+	transportMu sync.Mutex
+	// transport is shared by the HTTP clients, so that they reuse connections.
+	// It's built on first use, when the settings are known, and again only
+	// if they change.
+	transport     *http.Transport
+	transportWith transportSettings
+)
+
+// sharedTransport returns the transport for the configured timeouts and proxy.
+func sharedTransport() *http.Transport {
+	settings := transportSettings{
+		connectTimeout: ConnectTimeoutInSeconds,
+		tlsTimeout:     TLSTimeoutInSeconds,
+		proxy:          ProxyURL,
+	}
+
+	transportMu.Lock()
+	defer transportMu.Unlock()
+	if transport != nil && transportWith == settings {
+		return transport
+	}
+
+	proxy := http.ProxyFromEnvironment
+	if u, err := url.Parse(settings.proxy); settings.proxy != "" && err == nil {
+		proxy = http.ProxyURL(u)
+	}
+	transport = &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   time.Duration(settings.connectTimeout) * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSHandshakeTimeout:   time.Duration(settings.tlsTimeout) * time.Second,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	transportWith = settings
+	return transport
+}
+
+// newHTTPClient returns an HTTP client that uses the configured timeouts
+// and proxy. A timeout of zero means no timeout.
+func newHTTPClient() *http.Client {
+	return &http.Client{
+		Timeout:   time.Duration(TimeoutInSeconds) * time.Second,
+		Transport: sharedTransport(),
+	}
+}
+
 // Client is an http client that is configured for Exercism.
 type Client struct {
 	*http.Client
 	ContentType string
 	Token       string
 	APIBaseURL  string
+	// RateLimiter, if set, waits out the API's rate limit and retries.
+	RateLimiter *RateLimiter
 }
 
 // NewClient returns an Exercism API client.
+// It picks up the timeouts as they are configured at the time of the call.
 func NewClient(token, baseURL string) (*Client, error) {
 	return &Client{
-		Client:     HTTPClient,
+		Client:     newHTTPClient(),
 		Token:      token,
 		APIBaseURL: baseURL,
 	}, nil
@@ -42,6 +116,9 @@ func (c *Client) NewRequest(method, url string, body io.Reader) (*http.Request,
 	if c.Client == nil {
 		c.Client = HTTPClient
 	}
+	if c.Client == nil {
+		c.Client = newHTTPClient()
+	}
 
 	req, err := http.NewRequest(method, url, body)
 	if err != nil {
@@ -65,7 +142,13 @@ func (c *Client) NewRequest(method, url string, body io.Reader) (*http.Request,
 func (c *Client) Do(req *http.Request) (*http.Response, error) {
 	debug.DumpRequest(req)
 
-	res, err := c.Client.Do(req)
+	var res *http.Response
+	var err error
+	if c.RateLimiter != nil {
+		res, err = c.RateLimiter.do(c.Client, req)
+	} else {
+		res, err = c.Client.Do(req)
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -76,16 +159,42 @@ func (c *Client) Do(req *http.Request) (*http.Response, error) {
 
 // TokenIsValid calls the API to determine whether the token is valid.
 func (c *Client) TokenIsValid() (bool, error) {
+	info, err := c.ValidateToken()
+	return info.Valid, err
+}
+
+// TokenInfo tells whether a token is valid, and whose it is.
+type TokenInfo struct {
+	Valid bool
+	// Handle is the account the token belongs to, if the API says.
+	Handle string
+}
+
+// ValidateToken calls the API to determine whether the token is valid,
+// and which account it belongs to.
+func (c *Client) ValidateToken() (TokenInfo, error) {
 	url := fmt.Sprintf("%s/validate_token", c.APIBaseURL)
 	req, err := c.NewRequest("GET", url, nil)
 	if err != nil {
-		return false, err
+		return TokenInfo{}, err
 	}
 	resp, err := c.Do(req)
 	if err != nil {
-		return false, err
+		return TokenInfo{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return TokenInfo{}, nil
+	}
+
+	// Older versions of the API don't say whose token it is.
+	var payload struct {
+		User struct {
+			Handle string `json:"handle"`
+		} `json:"user"`
 	}
-	return resp.StatusCode == http.StatusOK, nil
+	json.NewDecoder(resp.Body).Decode(&payload)
+	return TokenInfo{Valid: true, Handle: payload.User.Handle}, nil
 }
 
 // IsPingable calls the API /ping to determine whether the API can be reached.
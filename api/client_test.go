@@ -3,9 +3,11 @@ package api
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -79,3 +81,106 @@ func TestDo(t *testing.T) {
 
 	assert.Equal(t, "world", body.Hello)
 }
+
+func TestNewClientUsesConfiguredTimeouts(t *testing.T) {
+	oldTimeout, oldConnect, oldTLS := TimeoutInSeconds, ConnectTimeoutInSeconds, TLSTimeoutInSeconds
+	defer func() {
+		TimeoutInSeconds, ConnectTimeoutInSeconds, TLSTimeoutInSeconds = oldTimeout, oldConnect, oldTLS
+	}()
+
+	TimeoutInSeconds = 300
+	ConnectTimeoutInSeconds = 3
+	TLSTimeoutInSeconds = 1
+
+	client, err := NewClient("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, 300*time.Second, client.Timeout)
+	if transport, ok := client.Transport.(*http.Transport); assert.True(t, ok) {
+		assert.Equal(t, 1*time.Second, transport.TLSHandshakeTimeout)
+	}
+
+	// A server that accepts connections but never completes the handshake
+	// trips the TLS timeout long before the overall timeout.
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer ln.Close()
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			defer conn.Close()
+		}
+	}()
+
+	req, err := client.NewRequest("GET", "https://"+ln.Addr().String(), nil)
+	assert.NoError(t, err)
+
+	start := time.Now()
+	_, err = client.Do(req)
+	assert.Error(t, err)
+	assert.True(t, time.Since(start) < 10*time.Second, "took %s", time.Since(start))
+}
+
+func TestNewClientUsesConfiguredProxy(t *testing.T) {
+	defer func() { ProxyURL = "" }()
+	ProxyURL = "http://proxy.example.com:8080"
+
+	client, err := NewClient("", "")
+	assert.NoError(t, err)
+	req, err := client.NewRequest("GET", "https://api.exercism.io/v1/ping", nil)
+	assert.NoError(t, err)
+	if transport, ok := client.Transport.(*http.Transport); assert.True(t, ok) {
+		proxy, err := transport.Proxy(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "http://proxy.example.com:8080", proxy.String())
+	}
+}
+
+func TestNewClientSharesTransport(t *testing.T) {
+	oldConnect := ConnectTimeoutInSeconds
+	defer func() { ConnectTimeoutInSeconds = oldConnect }()
+
+	first, err := NewClient("", "")
+	assert.NoError(t, err)
+	second, err := NewClient("", "")
+	assert.NoError(t, err)
+	assert.True(t, first.Transport == second.Transport)
+
+	// The transport is built again once the settings change.
+	ConnectTimeoutInSeconds = oldConnect + 1
+	third, err := NewClient("", "")
+	assert.NoError(t, err)
+	assert.False(t, first.Transport == third.Transport)
+}
+
+func TestValidateToken(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/validate_token", r.URL.Path)
+		switch r.Header.Get("Authorization") {
+		case "Bearer alice-token":
+			fmt.Fprint(w, `{"user": {"handle": "alice"}}`)
+		case "Bearer old-api-token":
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	testCases := []struct {
+		token    string
+		expected TokenInfo
+	}{
+		{token: "alice-token", expected: TokenInfo{Valid: true, Handle: "alice"}},
+		{token: "old-api-token", expected: TokenInfo{Valid: true}},
+		{token: "bad-token", expected: TokenInfo{}},
+	}
+	for _, tc := range testCases {
+		client, err := NewClient(tc.token, ts.URL)
+		assert.NoError(t, err)
+		info, err := client.ValidateToken()
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, info, tc.token)
+	}
+}
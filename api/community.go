@@ -0,0 +1,50 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// CommunitySolution is a solution that its author has published.
+type CommunitySolution struct {
+	ID   string `json:"id"`
+	User struct {
+		Handle string `json:"handle"`
+	} `json:"user"`
+}
+
+// CommunitySolutions asks the API for up to limit of the published
+// solutions to an exercise.
+func (c *Client) CommunitySolutions(trackID, exerciseSlug string, limit int) ([]CommunitySolution, error) {
+	url := fmt.Sprintf("%s/tracks/%s/exercises/%s/community_solutions", c.APIBaseURL, trackID, exerciseSlug)
+	req, err := c.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	q := req.URL.Query()
+	q.Add("limit", strconv.Itoa(limit))
+	req.URL.RawQuery = q.Encode()
+
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %s", res.Status)
+	}
+
+	var payload struct {
+		Solutions []CommunitySolution `json:"solutions"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("unable to parse API response - %s", err)
+	}
+	if len(payload.Solutions) > limit {
+		payload.Solutions = payload.Solutions[:limit]
+	}
+	return payload.Solutions, nil
+}
@@ -0,0 +1,30 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommunitySolutions(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/tracks/go/exercises/bob/community_solutions", r.URL.Path)
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		fmt.Fprint(w, `{"solutions": [{"id": "a1", "user": {"handle": "alice"}}, {"id": "b2", "user": {"handle": "bob"}}, {"id": "c3", "user": {"handle": "carol"}}]}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	// More than were asked for are ignored.
+	solutions, err := client.CommunitySolutions("go", "bob", 2)
+	assert.NoError(t, err)
+	if assert.Len(t, solutions, 2) {
+		assert.Equal(t, "a1", solutions[0].ID)
+		assert.Equal(t, "bob", solutions[1].User.Handle)
+	}
+}
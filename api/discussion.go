@@ -0,0 +1,54 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Comment is part of the discussion between a student and their mentors.
+type Comment struct {
+	Author struct {
+		Handle string `json:"handle"`
+	} `json:"author"`
+	// Mentor tells whether the author was mentoring the solution.
+	Mentor    bool      `json:"is_mentor"`
+	CreatedAt time.Time `json:"created_at"`
+	// Content is Markdown.
+	Content string `json:"content"`
+	// Snippets are code that the author suggested.
+	Snippets []Snippet `json:"snippets"`
+}
+
+// Snippet is a piece of code suggested in a discussion.
+type Snippet struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// MentorComments asks the API for the discussion about a solution, oldest first.
+func (c *Client) MentorComments(solutionID string) ([]Comment, error) {
+	url := fmt.Sprintf("%s/solutions/%s/discussions", c.APIBaseURL, solutionID)
+	req, err := c.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %s", res.Status)
+	}
+
+	var payload struct {
+		Comments []Comment `json:"comments"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("unable to parse API response - %s", err)
+	}
+	return payload.Comments, nil
+}
@@ -0,0 +1,32 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMentorComments(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/solutions/abc/discussions", r.URL.Path)
+		fmt.Fprint(w, `{"comments": [{"author": {"handle": "alice"}, "is_mentor": true, "created_at": "2018-06-01T12:30:00Z", "content": "Nice!", "snippets": [{"language": "go", "code": "return nil"}]}]}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	comments, err := client.MentorComments("abc")
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 1) {
+		assert.Equal(t, "alice", comments[0].Author.Handle)
+		assert.True(t, comments[0].Mentor)
+		assert.Equal(t, time.Date(2018, 6, 1, 12, 30, 0, 0, time.UTC), comments[0].CreatedAt)
+		assert.Equal(t, "Nice!", comments[0].Content)
+		assert.Equal(t, []Snippet{{Language: "go", Code: "return nil"}}, comments[0].Snippets)
+	}
+}
@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// RateLimiter holds back the requests of a client while the API says there
+// have been too many, with 429 Too Many Requests, and retries the ones it
+// turned away. The requests that are made concurrently share it, so that
+// they all wait, rather than each of them running into the limit.
+type RateLimiter struct {
+	// Retries is how many times a request that was turned away is retried.
+	Retries int
+	// DefaultWait is how long to wait when the API doesn't say, with a
+	// Retry-After header.
+	DefaultWait time.Duration
+	// MaxWait is the longest the limiter is willing to wait. If the API
+	// asks for longer, its response is returned instead. Zero means no limit.
+	MaxWait time.Duration
+	// OnLimit, if set, is called when the requests are held back,
+	// with how long they wait.
+	OnLimit func(wait time.Duration)
+
+	mu      sync.Mutex
+	until   time.Time
+	retried int
+	waited  time.Duration
+}
+
+// Retried tells how many requests were retried, and how long the requests
+// were held back for altogether.
+func (l *RateLimiter) Retried() (int, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.retried, l.waited
+}
+
+// do performs the request with the client, waiting and retrying as needed.
+// Requests with a body can only be retried if it can be read again.
+func (l *RateLimiter) do(client *http.Client, req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(req.Context()); err != nil {
+			return nil, err
+		}
+		res, err := client.Do(req)
+		if err != nil || res.StatusCode != http.StatusTooManyRequests || attempt >= l.Retries {
+			return res, err
+		}
+		if req.Body != nil && req.GetBody == nil {
+			return res, nil
+		}
+		wait := retryAfter(res.Header.Get("Retry-After"), time.Now())
+		if wait <= 0 {
+			wait = l.DefaultWait
+		}
+		if l.MaxWait > 0 && wait > l.MaxWait {
+			return res, nil
+		}
+		res.Body.Close()
+		if req.GetBody != nil {
+			if req.Body, err = req.GetBody(); err != nil {
+				return nil, err
+			}
+		}
+		l.hold(wait)
+	}
+}
+
+// hold makes the requests wait, unless they're already held back for longer.
+func (l *RateLimiter) hold(wait time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.retried++
+	until := time.Now().Add(wait)
+	if !until.After(l.until) {
+		return
+	}
+	if l.until.After(time.Now()) {
+		l.waited += until.Sub(l.until)
+	} else {
+		l.waited += wait
+	}
+	l.until = until
+	if l.OnLimit != nil {
+		l.OnLimit(wait)
+	}
+}
+
+// wait blocks while the requests are held back.
+func (l *RateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	until := l.until
+	l.mu.Unlock()
+	wait := time.Until(until)
+	if wait <= 0 {
+		return nil
+	}
+	select {
+	case <-time.After(wait):
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// retryAfter parses a Retry-After header, which is either a number of
+// seconds or a date. It's zero if there is no header, or it's invalid.
+func retryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		calls++
+		switch {
+		case r.URL.Path == "/later":
+			w.Header().Set("Retry-After", "3600")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case calls <= 2:
+			w.WriteHeader(http.StatusTooManyRequests)
+		default:
+			w.Write([]byte(r.Method))
+		}
+	}))
+	defer ts.Close()
+
+	var waits []time.Duration
+	limiter := &RateLimiter{
+		Retries:     3,
+		DefaultWait: time.Millisecond,
+		MaxWait:     time.Minute,
+		OnLimit: func(wait time.Duration) {
+			waits = append(waits, wait)
+		},
+	}
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+	client.RateLimiter = limiter
+
+	// Bodies are sent again with each retry.
+	req, err := client.NewRequest("POST", ts.URL+"/now", strings.NewReader("body"))
+	assert.NoError(t, err)
+	res, err := client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.Equal(t, 3, calls)
+	assert.Equal(t, []time.Duration{time.Millisecond, time.Millisecond}, waits)
+	retried, _ := limiter.Retried()
+	assert.Equal(t, 2, retried)
+
+	// Waiting longer than the limiter is willing to isn't worth it.
+	req, err = client.NewRequest("GET", ts.URL+"/later", nil)
+	assert.NoError(t, err)
+	res, err = client.Do(req)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusTooManyRequests, res.StatusCode)
+	assert.Equal(t, 4, calls)
+}
+
+func TestRateLimiterHoldsBackAllRequests(t *testing.T) {
+	limiter := &RateLimiter{}
+	limiter.hold(50 * time.Millisecond)
+	// A shorter wait doesn't cut the hold short.
+	limiter.hold(time.Millisecond)
+
+	start := time.Now()
+	assert.NoError(t, limiter.wait(context.Background()))
+	assert.True(t, time.Since(start) >= 40*time.Millisecond)
+
+	retried, waited := limiter.Retried()
+	assert.Equal(t, 2, retried)
+	assert.Equal(t, 50*time.Millisecond, waited)
+}
+
+func TestRetryAfter(t *testing.T) {
+	now := time.Date(2018, 7, 1, 12, 0, 0, 0, time.UTC)
+	assert.Equal(t, 2*time.Minute, retryAfter("120", now))
+	assert.Equal(t, 30*time.Second, retryAfter("Sun, 01 Jul 2018 12:00:30 GMT", now))
+	assert.Equal(t, time.Duration(0), retryAfter("", now))
+	assert.Equal(t, time.Duration(0), retryAfter("soon", now))
+}
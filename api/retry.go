@@ -0,0 +1,100 @@
+package api
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// RetryPolicy decides how often requests that fail for transient reasons are retried.
+type RetryPolicy struct {
+	// Retries is how many times to retry after the first attempt.
+	Retries int
+	// Backoff is how long to wait before the first retry.
+	// It doubles for each retry after that.
+	Backoff time.Duration
+	// OnlyUnsent limits the retries to requests that never reached the API,
+	// because no connection could be made, or that a gateway in front of it
+	// turned away, without passing them on. Requests that aren't idempotent
+	// need it, since the API may have acted on one whose response was lost.
+	OnlyUnsent bool
+	// OnRetry, if set, is called before waiting to retry,
+	// with the number of the retry and the reason for it.
+	OnRetry func(retry int, wait time.Duration, reason error)
+}
+
+// isTransientStatus determines whether a response status is likely to be
+// temporary, e.g. because the API is being deployed or is overloaded.
+func isTransientStatus(code int) bool {
+	switch code {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// isUnsent determines whether a request failed before it was sent,
+// e.g. because the API couldn't be resolved or refused the connection.
+func isUnsent(err error) bool {
+	if urlErr, ok := err.(*url.Error); ok {
+		err = urlErr.Err
+	}
+	opErr, ok := err.(*net.OpError)
+	return ok && (opErr.Op == "dial" || opErr.Op == "proxyconnect")
+}
+
+// isUnprocessed determines whether a transient status comes from a gateway
+// or proxy that didn't pass the request on to the API. Those say so with an
+// empty response, or by asking to retry later with a Retry-After header,
+// whereas an error the API sends back comes with a body.
+func isUnprocessed(res *http.Response) bool {
+	return isTransientStatus(res.StatusCode) && (res.ContentLength == 0 || res.Header.Get("Retry-After") != "")
+}
+
+// DoWithRetry performs the request created by newRequest, retrying it according
+// to the policy when it fails because of the network or a transient status.
+// A request body can only be sent once, so newRequest is called for each attempt.
+// The outcome of the final attempt is returned, whether it succeeded or not.
+// Cancelling the request's context stops the retries.
+func (c *Client) DoWithRetry(newRequest func() (*http.Request, error), policy RetryPolicy) (*http.Response, error) {
+	wait := policy.Backoff
+	for retry := 1; ; retry++ {
+		req, err := newRequest()
+		if err != nil {
+			return nil, err
+		}
+		ctx := req.Context()
+
+		res, err := c.Do(req)
+		if retry > policy.Retries || ctx.Err() != nil {
+			return res, err
+		}
+
+		var reason error
+		switch {
+		case policy.OnlyUnsent && err != nil && !isUnsent(err):
+			return res, err
+		case policy.OnlyUnsent && err == nil && !isUnprocessed(res):
+			return res, nil
+		case err != nil:
+			reason = err
+		case isTransientStatus(res.StatusCode):
+			reason = fmt.Errorf("API returned %s", res.Status)
+			res.Body.Close()
+		default:
+			return res, nil
+		}
+
+		if policy.OnRetry != nil {
+			policy.OnRetry(retry, wait, reason)
+		}
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		wait *= 2
+	}
+}
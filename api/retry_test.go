@@ -0,0 +1,216 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDoWithRetry(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		statuses []int
+		retries  int
+		status   int
+		calls    int
+		waits    []time.Duration
+	}{
+		{
+			desc:     "succeeds at once",
+			statuses: []int{http.StatusOK},
+			retries:  3,
+			status:   http.StatusOK,
+			calls:    1,
+		},
+		{
+			desc:     "recovers from transient failures",
+			statuses: []int{http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusOK},
+			retries:  3,
+			status:   http.StatusOK,
+			calls:    3,
+			waits:    []time.Duration{time.Millisecond, 2 * time.Millisecond},
+		},
+		{
+			desc:     "gives up after the last retry",
+			statuses: []int{http.StatusGatewayTimeout, http.StatusGatewayTimeout, http.StatusGatewayTimeout},
+			retries:  2,
+			status:   http.StatusGatewayTimeout,
+			calls:    3,
+			waits:    []time.Duration{time.Millisecond, 2 * time.Millisecond},
+		},
+		{
+			desc:     "doesn't retry other errors",
+			statuses: []int{http.StatusInternalServerError, http.StatusOK},
+			retries:  3,
+			status:   http.StatusInternalServerError,
+			calls:    1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var calls int
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tc.statuses[calls])
+				calls++
+			}))
+			defer ts.Close()
+
+			client, err := NewClient("", ts.URL)
+			assert.NoError(t, err)
+
+			var waits []time.Duration
+			policy := RetryPolicy{
+				Retries: tc.retries,
+				Backoff: time.Millisecond,
+				OnRetry: func(retry int, wait time.Duration, reason error) {
+					assert.Equal(t, len(waits)+1, retry)
+					assert.Regexp(t, "API returned", reason.Error())
+					waits = append(waits, wait)
+				},
+			}
+			newRequest := func() (*http.Request, error) {
+				return client.NewRequest("GET", ts.URL, nil)
+			}
+
+			res, err := client.DoWithRetry(newRequest, policy)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.status, res.StatusCode)
+			assert.Equal(t, tc.calls, calls)
+			assert.Equal(t, tc.waits, waits)
+		})
+	}
+}
+
+func TestDoWithRetryNetworkError(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	url := ts.URL
+	ts.Close()
+
+	client, err := NewClient("", url)
+	assert.NoError(t, err)
+
+	var retries int
+	policy := RetryPolicy{
+		Retries: 2,
+		Backoff: time.Millisecond,
+		OnRetry: func(retry int, wait time.Duration, reason error) { retries++ },
+	}
+	_, err = client.DoWithRetry(func() (*http.Request, error) {
+		return client.NewRequest("GET", url, nil)
+	}, policy)
+	assert.Error(t, err)
+	assert.Equal(t, 2, retries)
+}
+
+func TestDoWithRetryOnlyUnsent(t *testing.T) {
+	var calls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, `{"error": {"type": "upstream_failure", "message": "the submission may have been saved"}}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	var retries int
+	policy := RetryPolicy{
+		Retries:    2,
+		Backoff:    time.Millisecond,
+		OnlyUnsent: true,
+		OnRetry:    func(retry int, wait time.Duration, reason error) { retries++ },
+	}
+	newRequest := func(url string) func() (*http.Request, error) {
+		return func() (*http.Request, error) {
+			return client.NewRequest("PATCH", url, nil)
+		}
+	}
+
+	// The API got the request, so it may have acted on it.
+	res, err := client.DoWithRetry(newRequest(ts.URL), policy)
+	assert.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, res.StatusCode)
+	assert.Equal(t, 1, calls)
+	assert.Equal(t, 0, retries)
+
+	// Nothing could be sent, so it's safe to try again.
+	closed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	closed.Close()
+	_, err = client.DoWithRetry(newRequest(closed.URL), policy)
+	assert.Error(t, err)
+	assert.Equal(t, 2, retries)
+
+	// A gateway that turns the request away without a body, or asks to retry
+	// later, didn't pass it on.
+	testCases := []struct {
+		desc    string
+		respond func(w http.ResponseWriter)
+	}{
+		{
+			desc: "empty",
+			respond: func(w http.ResponseWriter) {
+				w.WriteHeader(http.StatusServiceUnavailable)
+			},
+		},
+		{
+			desc: "retry after",
+			respond: func(w http.ResponseWriter) {
+				w.Header().Set("Retry-After", "1")
+				w.WriteHeader(http.StatusGatewayTimeout)
+				fmt.Fprint(w, "<html>Gateway Timeout</html>")
+			},
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			calls, retries = 0, 0
+			gateway := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				calls++
+				if calls == 1 {
+					tc.respond(w)
+					return
+				}
+				w.WriteHeader(http.StatusOK)
+			}))
+			defer gateway.Close()
+
+			res, err := client.DoWithRetry(newRequest(gateway.URL), policy)
+			assert.NoError(t, err)
+			assert.Equal(t, http.StatusOK, res.StatusCode)
+			assert.Equal(t, 2, calls)
+			assert.Equal(t, 1, retries)
+		})
+	}
+}
+
+func TestDoWithRetryCancelled(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := RetryPolicy{
+		Retries: 5,
+		Backoff: time.Hour,
+		OnRetry: func(retry int, wait time.Duration, reason error) { cancel() },
+	}
+	_, err = client.DoWithRetry(func() (*http.Request, error) {
+		req, err := client.NewRequest("GET", fmt.Sprintf("%s/solutions", ts.URL), nil)
+		if err != nil {
+			return nil, err
+		}
+		return req.WithContext(ctx), nil
+	}, policy)
+	assert.Equal(t, context.Canceled, err)
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Iteration describes the latest iteration of a solution.
+type Iteration struct {
+	// Number counts the iterations of the solution, starting at 1.
+	Number      int        `json:"number"`
+	SubmittedAt *time.Time `json:"submitted_at"`
+	// TestsStatus is the state of the automated test run, such as
+	// "queued", "passed", or "failed". It's empty if the tests aren't run.
+	TestsStatus string `json:"tests_status"`
+}
+
+// TestsPending determines whether the automated tests have yet to finish.
+func (it Iteration) TestsPending() bool {
+	return it.TestsStatus == "queued" || it.TestsStatus == "running"
+}
+
+// LatestIteration asks the API about the latest iteration of a solution.
+// If nothing has been submitted yet, the iteration is empty.
+func (c *Client) LatestIteration(solutionID string) (*Iteration, error) {
+	url := fmt.Sprintf("%s/solutions/%s", c.APIBaseURL, solutionID)
+	req, err := c.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %s", res.Status)
+	}
+
+	var payload struct {
+		Solution struct {
+			Iteration Iteration `json:"iteration"`
+		} `json:"solution"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("unable to parse API response - %s", err)
+	}
+	return &payload.Solution.Iteration, nil
+}
+
+// LastSubmittedAt asks the API when the latest iteration of a solution was submitted.
+// It returns nil if nothing has been submitted yet.
+func (c *Client) LastSubmittedAt(solutionID string) (*time.Time, error) {
+	iteration, err := c.LatestIteration(solutionID)
+	if err != nil {
+		return nil, err
+	}
+	return iteration.SubmittedAt, nil
+}
+
+// Iterations asks the API for all the iterations of a solution, oldest first.
+func (c *Client) Iterations(solutionID string) ([]Iteration, error) {
+	url := fmt.Sprintf("%s/solutions/%s/iterations", c.APIBaseURL, solutionID)
+	req, err := c.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %s", res.Status)
+	}
+
+	var payload struct {
+		Iterations []Iteration `json:"iterations"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("unable to parse API response - %s", err)
+	}
+	return payload.Iterations, nil
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLastSubmittedAt(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		status   int
+		body     string
+		expected *time.Time
+		err      bool
+	}{
+		{
+			desc:     "submitted",
+			status:   http.StatusOK,
+			body:     `{"solution": {"id": "abc", "iteration": {"submitted_at": "2018-08-20T10:11:12Z"}}}`,
+			expected: timePtr(time.Date(2018, 8, 20, 10, 11, 12, 0, time.UTC)),
+		},
+		{
+			desc:   "never submitted",
+			status: http.StatusOK,
+			body:   `{"solution": {"id": "abc", "iteration": {"submitted_at": null}}}`,
+		},
+		{
+			desc:   "error",
+			status: http.StatusInternalServerError,
+			err:    true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "GET", r.Method)
+				assert.Equal(t, "/solutions/abc", r.URL.Path)
+				w.WriteHeader(tc.status)
+				fmt.Fprint(w, tc.body)
+			}))
+			defer ts.Close()
+
+			client, err := NewClient("", ts.URL)
+			assert.NoError(t, err)
+
+			submittedAt, err := client.LastSubmittedAt("abc")
+			if tc.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			if tc.expected == nil {
+				assert.Nil(t, submittedAt)
+				return
+			}
+			if assert.NotNil(t, submittedAt) {
+				assert.True(t, tc.expected.Equal(*submittedAt))
+			}
+		})
+	}
+}
+
+func timePtr(t time.Time) *time.Time {
+	return &t
+}
+
+func TestLatestIteration(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/solutions/abc", r.URL.Path)
+		fmt.Fprint(w, `{"solution": {"id": "abc", "iteration": {"number": 3, "submitted_at": "2018-08-20T10:11:12Z", "tests_status": "queued"}}}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	iteration, err := client.LatestIteration("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, 3, iteration.Number)
+	assert.Equal(t, "queued", iteration.TestsStatus)
+	assert.True(t, iteration.TestsPending())
+	assert.True(t, time.Date(2018, 8, 20, 10, 11, 12, 0, time.UTC).Equal(*iteration.SubmittedAt))
+
+	iteration.TestsStatus = "passed"
+	assert.False(t, iteration.TestsPending())
+}
+
+func TestIterations(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		assert.Equal(t, "/solutions/abc/iterations", r.URL.Path)
+		fmt.Fprint(w, `{"iterations": [{"number": 1, "tests_status": "failed"}, {"number": 2, "tests_status": "passed"}]}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	iterations, err := client.Iterations("abc")
+	assert.NoError(t, err)
+	assert.Equal(t, []Iteration{{Number: 1, TestsStatus: "failed"}, {Number: 2, TestsStatus: "passed"}}, iterations)
+}
@@ -0,0 +1,48 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// Exercise describes an exercise on a track, as far as the user is concerned.
+type Exercise struct {
+	ID string `json:"id"`
+	// Locked exercises can't be downloaded until others are completed.
+	Locked bool `json:"locked"`
+}
+
+// Exercises asks the API for the exercises of a track, in the track's order.
+func (c *Client) Exercises(trackID string) ([]Exercise, error) {
+	return c.exercises(fmt.Sprintf("%s/tracks/%s/exercises", c.APIBaseURL, trackID))
+}
+
+// TeamExercises lists the exercises of a team's track, which may be private to the team.
+func (c *Client) TeamExercises(team, trackID string) ([]Exercise, error) {
+	return c.exercises(fmt.Sprintf("%s/teams/%s/tracks/%s/exercises", c.APIBaseURL, team, trackID))
+}
+
+func (c *Client) exercises(url string) ([]Exercise, error) {
+	req, err := c.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := c.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("API returned %s", res.Status)
+	}
+
+	var payload struct {
+		Exercises []Exercise `json:"exercises"`
+	}
+	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("unable to parse API response - %s", err)
+	}
+	return payload.Exercises, nil
+}
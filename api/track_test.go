@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExercises(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "GET", r.Method)
+		if r.URL.Path != "/tracks/go/exercises" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"exercises": [{"id": "hello-world"}, {"id": "hamming", "locked": true}]}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	exercises, err := client.Exercises("go")
+	assert.NoError(t, err)
+	assert.Equal(t, []Exercise{{ID: "hello-world"}, {ID: "hamming", Locked: true}}, exercises)
+
+	_, err = client.Exercises("bogus")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "404")
+	}
+}
+
+func TestTeamExercises(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/teams/my-class/tracks/go/exercises" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"exercises": [{"id": "class-assignment"}]}`)
+	}))
+	defer ts.Close()
+
+	client, err := NewClient("", ts.URL)
+	assert.NoError(t, err)
+
+	exercises, err := client.TeamExercises("my-class", "go")
+	assert.NoError(t, err)
+	assert.Equal(t, []Exercise{{ID: "class-assignment"}}, exercises)
+}
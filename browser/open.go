@@ -1,6 +1,7 @@
 package browser
 
 import (
+	"fmt"
 	"os/exec"
 	"runtime"
 	"strings"
@@ -26,6 +27,8 @@ func Open(url string) error {
 		cmd = exec.Command("xdg-open", url)
 	case "windows":
 		cmd = exec.Command("cmd", "/c", "start", url)
+	default:
+		return fmt.Errorf("don't know how to open a browser on %s", runtime.GOOS)
 	}
 
 	return cmd.Run()
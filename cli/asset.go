@@ -22,7 +22,8 @@ func (a *Asset) download() (*bytes.Reader, error) {
 	}
 	// https://developer.github.com/v3/repos/releases/#get-a-single-release-asset
 	req.Header.Set("Accept", "application/octet-stream")
-	res, err := http.DefaultClient.Do(req)
+	// The download has no timeout, since the build may take a while to arrive.
+	res, err := (&http.Client{Transport: transport}).Do(req)
 	if err != nil {
 		return nil, err
 	}
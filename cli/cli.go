@@ -9,7 +9,9 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net"
 	"net/http"
+	"net/url"
 	"runtime"
 	"strings"
 	"time"
@@ -45,12 +47,35 @@ var (
 var (
 	// TimeoutInSeconds is the timeout the default HTTP client will use.
 	TimeoutInSeconds = 60
+	// ProxyURL, if set, is the proxy that HTTP calls go through,
+	// rather than the one given in the environment.
+	ProxyURL string
+	// transport is like http.DefaultTransport, but it goes through ProxyURL.
+	transport = &http.Transport{
+		Proxy: proxy,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
 	// HTTPClient is the client used to make HTTP calls in the cli package.
-	HTTPClient = &http.Client{Timeout: time.Duration(TimeoutInSeconds) * time.Second}
+	HTTPClient = &http.Client{Timeout: time.Duration(TimeoutInSeconds) * time.Second, Transport: transport}
 	// ReleaseURL is the endpoint that provides information about cli releases.
 	ReleaseURL = "https://api.github.com/repos/exercism/cli/releases"
 )
 
+// proxy picks the proxy for a request, which is ProxyURL if it's set.
+func proxy(req *http.Request) (*url.URL, error) {
+	if u, err := url.Parse(ProxyURL); ProxyURL != "" && err == nil {
+		return u, nil
+	}
+	return http.ProxyFromEnvironment(req)
+}
+
 // Updater is a simple upgradable file interface.
 type Updater interface {
 	IsUpToDate() (bool, error)
@@ -56,6 +56,22 @@ func TestIsUpToDate(t *testing.T) {
 	}
 }
 
+func TestHTTPClientUsesConfiguredProxy(t *testing.T) {
+	var proxied string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxied = r.URL.String()
+	}))
+	defer proxy.Close()
+
+	defer func() { ProxyURL = "" }()
+	ProxyURL = proxy.URL
+
+	res, err := HTTPClient.Get("http://releases.example.com/latest")
+	assert.NoError(t, err)
+	res.Body.Close()
+	assert.Equal(t, "http://releases.example.com/latest", proxied)
+}
+
 func TestIsUpToDateWithoutRelease(t *testing.T) {
 	fakeEndpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Checking for the latest release should call latestReleaseURL endpoint.
@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/exercism/cli/workspace"
+)
+
+// archiveTimeFormat names the archive folders, so that they sort chronologically.
+const archiveTimeFormat = "20060102T150405.000Z"
+
+// archiveSubmission copies the submitted documents into a folder named after
+// the time of the submission, under <dir>/<track>/<exercise>.
+// The documents keep their paths relative to the exercise.
+// It returns the folder that the documents were copied into.
+func archiveSubmission(dir string, solution *workspace.Solution, docs []workspace.Document, at time.Time) (string, error) {
+	dst := filepath.Join(dir, solution.Track, solution.Exercise, at.UTC().Format(archiveTimeFormat))
+	for _, doc := range docs {
+		if err := copyFile(doc.Filepath(), filepath.Join(dst, doc.RelativePath)); err != nil {
+			return dst, err
+		}
+	}
+	return dst, nil
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	if err := os.MkdirAll(filepath.Dir(dst), os.FileMode(0755)); err != nil {
+		return err
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, os.FileMode(0644))
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	// Closing reports write errors that were deferred, e.g. when the disk is full.
+	return out.Close()
+}
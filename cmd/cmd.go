@@ -1,5 +1,15 @@
 package cmd
 
+import (
+	"fmt"
+	"os"
+
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/debug"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/viper"
+)
+
 const msgWelcomePleaseConfigure = `
 
     Welcome to Exercism!
@@ -33,3 +43,40 @@ const msgMissingMetadata = `
     Please see https://exercism.io/cli-v1-to-v2 for instructions on how to fix it.
 
 `
+
+// warnIfNetworkPath lets people know, in verbose mode, when their files live
+// on a network filesystem, since that can make commands appear to hang.
+func warnIfNetworkPath(paths ...string) {
+	if !debug.Verbose {
+		return
+	}
+	for _, path := range paths {
+		if path != "" && workspace.IsNetworkPath(path) {
+			debug.Printf("\n    WARNING: %s is on a network drive. Reading and writing files may be slow.\n\n", path)
+		}
+	}
+}
+
+// readConfigFile reads a JSON config file from the config dir into v.
+// If the file can't be read, v is left empty.
+// A hand-edited file with comments or trailing commas is read anyway, with a warning.
+func readConfigFile(v *viper.Viper, dir, basename string) {
+	lenient, err := config.ReadInConfig(v, dir, basename)
+	if err != nil {
+		if !os.IsNotExist(err) {
+			debug.Printf("Unable to read the %s config: %s\n", basename, err)
+		}
+		return
+	}
+	if lenient {
+		msg := `
+
+    WARNING: The config file %s.json in %s
+             contains comments or trailing commas, which aren't valid JSON.
+             It was read without them. Run the configure command to
+             rewrite it as plain JSON.
+
+`
+		fmt.Fprintf(Err, msg, basename, dir)
+	}
+}
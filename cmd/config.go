@@ -0,0 +1,412 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/cli"
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// configKeys are the user config keys that can be managed individually.
+var configKeys = map[string]bool{
+	"token":       true,
+	"workspace":   true,
+	"apibaseurl":  true,
+	"metadatadir": true,
+	"team":        true,
+	"archivedir":  true,
+	"normalize":   true,
+	"gzip":        true,
+	"timeout":     true,
+	"proxy":       true,
+}
+
+// configCmd manages individual keys in the user config.
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Get, set, or unset individual configuration values.",
+	Long: `Get, set, or unset individual configuration values.
+
+This is a scriptable alternative to the configure command.
+Each subcommand reads or writes a single key in the user config
+(user.json in the config dir), leaving the rest of the
+configuration untouched.
+
+The list subcommand shows the values in effect, including the
+defaults used for keys that aren't set.
+
+The following keys are supported:
+
+    token       authentication token used to connect to the site
+    workspace   directory for exercism exercises
+    apibaseurl  API base url
+    metadatadir name of the directory within each exercise where the CLI
+                keeps its own data and the exercise config
+                (default: .exercism)
+    team        slug of the team to submit solutions to
+    archivedir  directory to keep a copy of each submission in
+    normalize   true to submit text files with LF line endings and no
+                byte order mark
+    gzip        whether to compress submissions: auto (if the API
+                supports it), always, or never
+    timeout     HTTP timeout for submissions, in seconds; 0 means none
+    proxy       URL of the proxy to connect through, instead of the one
+                in the HTTPS_PROXY or HTTP_PROXY environment variables
+`,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get KEY",
+	Short: "Print the value of a configuration key.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		return runConfigGet(cfg, args[0])
+	},
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set KEY VALUE",
+	Short: "Set the value of a configuration key.",
+	Args:  cobra.ExactArgs(2),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		return runConfigSet(cfg, args[0], args[1])
+	},
+}
+
+var configUnsetCmd = &cobra.Command{
+	Use:   "unset KEY",
+	Short: "Remove a configuration key.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		return runConfigUnset(cfg, args[0])
+	},
+}
+
+var configListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configuration values.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		return runConfigList(cfg, format)
+	},
+}
+
+// stdinConfig keeps the config read from standard input with --config -,
+// since it can only be read once, and both the root command and the
+// command that runs read the config.
+var stdinConfig struct {
+	in io.Reader
+	b  []byte
+}
+
+// loadUserConfig reads the user config from the config dir,
+// or from the source given with the --config flag.
+func loadUserConfig() (config.Config, error) {
+	switch configSource {
+	case "":
+	case "-":
+		if stdinConfig.in != In {
+			b, err := ioutil.ReadAll(In)
+			if err != nil {
+				return config.Config{}, err
+			}
+			stdinConfig.in, stdinConfig.b = In, b
+		}
+		cfg, err := config.NewConfigFromReader(bytes.NewReader(stdinConfig.b))
+		if err != nil {
+			return config.Config{}, err
+		}
+		// A run configured from standard input leaves nothing on disk,
+		// so there's no config dir for caches or the history.
+		cfg.Dir = ""
+		return cfg, nil
+	default:
+		f, err := os.Open(configSource)
+		if err != nil {
+			return config.Config{}, err
+		}
+		defer f.Close()
+		return config.NewConfigFromReader(f)
+	}
+
+	cfg, err := newConfig(true)
+	if err != nil {
+		return config.Config{}, err
+	}
+
+	v := viper.New()
+	v.AddConfigPath(cfg.Dir)
+	v.SetConfigName("user")
+	v.SetConfigType("json")
+	// A missing file is fine.
+	readConfigFile(v, cfg.Dir, "user")
+	cfg.UserViperConfig = v
+
+	return cfg, nil
+}
+
+// applyUserSettings applies the settings in the user config that hold for
+// every command, whether or not it reads the config otherwise: the HTTP calls,
+// both to the API and for the releases of the client, go through the
+// configured proxy, and the workspace uses the configured name for the
+// metadata directory.
+func applyUserSettings(cfg config.Config) error {
+	api.ProxyURL = cfg.UserViperConfig.GetString("proxy")
+	cli.ProxyURL = api.ProxyURL
+	return workspace.SetMetadataDirName(cfg.UserViperConfig.GetString("metadatadir"))
+}
+
+// checkConfigWritable refuses to run a command that changes the user config
+// when it was read with --config, since the changes couldn't be saved.
+func checkConfigWritable(command string) error {
+	if configSource == "" {
+		return nil
+	}
+	source := configSource
+	if source == "-" {
+		source = "standard input"
+	}
+	msg := `
+
+    The config was read from %s with --config,
+    so the %s command has nowhere to save it.
+    Call the command without --config to change the config in the config dir.
+
+`
+	return fmt.Errorf(msg, source, command)
+}
+
+// canPrompt tells if there's anyone to answer questions on standard input.
+// With --config -, it's taken up by the config.
+func canPrompt() bool {
+	return configSource != "-" && isInteractive(In)
+}
+
+// newConfig provides the default config, pointed at the profile to use.
+// Unless the profile is being set up, it has to exist.
+func newConfig(mustExist bool) (config.Config, error) {
+	cfg := config.NewConfig()
+	root := cfg.Dir
+	if err := cfg.UseProfile(profileName); err != nil {
+		return config.Config{}, err
+	}
+	if mustExist && !config.ProfileExists(root, cfg.Profile) {
+		msg := `
+
+    There is no profile named '%s'. To set it up, call
+
+        %s configure --profile %s
+
+`
+		return config.Config{}, fmt.Errorf(msg, cfg.Profile, BinaryName, cfg.Profile)
+	}
+	return cfg, nil
+}
+
+// configEntry is a single key in effect.
+// Its source is either the user config, or the default used when the key isn't set.
+type configEntry struct {
+	Key    string `json:"key" yaml:"key"`
+	Value  string `json:"value" yaml:"value"`
+	Source string `json:"source" yaml:"source"`
+}
+
+type configEntries []configEntry
+
+// Columns implements tabular.
+func (e configEntries) Columns() []string {
+	return []string{"key", "value", "source"}
+}
+
+// Rows implements tabular.
+func (e configEntries) Rows() [][]string {
+	rows := make([][]string, 0, len(e))
+	for _, entry := range e {
+		rows = append(rows, []string{entry.Key, entry.Value, entry.Source})
+	}
+	return rows
+}
+
+// configDefaults are the values used for the keys that aren't set.
+// Keys without a default are left out.
+func configDefaults(cfg config.Config) map[string]string {
+	defaults := map[string]string{
+		"apibaseurl":  cfg.DefaultBaseURL,
+		"workspace":   config.DefaultWorkspaceDir(cfg),
+		"gzip":        "auto",
+		"normalize":   "false",
+		"metadatadir": workspace.DefaultMetadataDirName,
+	}
+	if cfg.Home == "" {
+		delete(defaults, "workspace")
+	}
+	for key, value := range defaults {
+		if value == "" {
+			delete(defaults, key)
+		}
+	}
+	return defaults
+}
+
+func runConfigList(cfg config.Config, format string) error {
+	f, err := newFormatter(format)
+	if err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(configKeys))
+	for k := range configKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	defaults := configDefaults(cfg)
+	entries := configEntries{}
+	for _, key := range keys {
+		if !cfg.UserViperConfig.IsSet(key) {
+			if value, ok := defaults[key]; ok {
+				entries = append(entries, configEntry{Key: key, Value: value, Source: "default"})
+			}
+			continue
+		}
+		value := cfg.UserViperConfig.GetString(key)
+		if key == "token" {
+			value = redact(value)
+		}
+		entries = append(entries, configEntry{Key: key, Value: value, Source: "user"})
+	}
+	return f.Format(Out, entries)
+}
+
+func runConfigGet(cfg config.Config, key string) error {
+	if err := validateConfigKey(key); err != nil {
+		return err
+	}
+
+	value := cfg.UserViperConfig.GetString(key)
+	if key == "token" {
+		value = redact(value)
+	}
+	fmt.Fprintln(Out, value)
+	return nil
+}
+
+func runConfigSet(cfg config.Config, key, value string) error {
+	if err := checkConfigWritable("config set"); err != nil {
+		return err
+	}
+	if err := validateConfigKey(key); err != nil {
+		return err
+	}
+	if value == "" {
+		return fmt.Errorf("the value for '%s' cannot be empty. To remove it, call\n\n    %s config unset %s", key, BinaryName, key)
+	}
+
+	if key == "workspace" || key == "archivedir" {
+		value = config.Resolve(value, cfg.Home)
+	}
+	if key == "team" {
+		if err := config.ValidateTeamSlug(value); err != nil {
+			return err
+		}
+	}
+	if key == "normalize" {
+		if _, err := strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("invalid value '%s' for normalize, expected true or false", value)
+		}
+	}
+	if key == "timeout" {
+		if n, err := strconv.Atoi(value); err != nil || n < 0 {
+			return fmt.Errorf("invalid value '%s' for timeout, expected a number of seconds", value)
+		}
+	}
+	if key == "proxy" {
+		if u, err := url.Parse(value); err != nil || u.Scheme == "" || u.Host == "" {
+			return fmt.Errorf("invalid value '%s' for proxy, expected a URL such as http://proxy.example.com:8080", value)
+		}
+	}
+	if key == "metadatadir" {
+		if err := workspace.ValidateMetadataDirName(value); err != nil {
+			return err
+		}
+	}
+	if key == "gzip" && value != "auto" && value != "always" && value != "never" {
+		return fmt.Errorf("invalid value '%s' for gzip, expected auto, always, or never", value)
+	}
+
+	cfg.UserViperConfig.Set(key, value)
+	return cfg.Save("user")
+}
+
+func runConfigUnset(cfg config.Config, key string) error {
+	if err := checkConfigWritable("config unset"); err != nil {
+		return err
+	}
+	if err := validateConfigKey(key); err != nil {
+		return err
+	}
+
+	// Viper can't delete a key, so copy everything else into a fresh config.
+	v := viper.New()
+	for k, value := range cfg.UserViperConfig.AllSettings() {
+		if k != key {
+			v.Set(k, value)
+		}
+	}
+	cfg.UserViperConfig = v
+	return cfg.Save("user")
+}
+
+func validateConfigKey(key string) error {
+	if configKeys[key] {
+		return nil
+	}
+
+	keys := make([]string, 0, len(configKeys))
+	for k := range configKeys {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return fmt.Errorf("unknown config key '%s'. Valid keys are: %s", key, strings.Join(keys, ", "))
+}
+
+func init() {
+	RootCmd.AddCommand(configCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configUnsetCmd)
+	configCmd.AddCommand(configListCmd)
+	setupFormatFlag(configListCmd.Flags(), "table")
+}
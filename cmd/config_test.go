@@ -0,0 +1,407 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/cli"
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConfigGet(t *testing.T) {
+	oldOut := Out
+	defer func() {
+		Out = oldOut
+	}()
+
+	v := viper.New()
+	v.Set("token", "abcdefghijklmnop")
+	v.Set("workspace", "/home/alice/exercism")
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	var buf bytes.Buffer
+	Out = &buf
+	err := runConfigGet(cfg, "workspace")
+	assert.NoError(t, err)
+	assert.Equal(t, "/home/alice/exercism\n", buf.String())
+
+	buf.Reset()
+	err = runConfigGet(cfg, "token")
+	assert.NoError(t, err)
+	assert.Equal(t, "abcd*********nop\n", buf.String())
+
+	err = runConfigGet(cfg, "bogus")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "unknown config key 'bogus'", err.Error())
+	}
+}
+
+func TestConfigSetAndUnset(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "config-set")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("apibaseurl", "http://example.com")
+
+	cfg := config.Config{
+		Persister:       config.FilePersister{Dir: tmpDir},
+		UserViperConfig: v,
+		Home:            "/home/alice",
+	}
+
+	err = runConfigSet(cfg, "workspace", "~/exercism")
+	assert.NoError(t, err)
+
+	saved := readUserConfig(t, tmpDir)
+	assert.Equal(t, "abc123", saved.GetString("token"))
+	assert.Equal(t, filepath.Join("/home/alice", "exercism"), saved.GetString("workspace"))
+
+	err = runConfigUnset(cfg, "apibaseurl")
+	assert.NoError(t, err)
+
+	saved = readUserConfig(t, tmpDir)
+	assert.Equal(t, "abc123", saved.GetString("token"))
+	assert.False(t, saved.IsSet("apibaseurl"))
+
+	err = runConfigSet(cfg, "editor", "vim")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "unknown config key", err.Error())
+	}
+
+	err = runConfigSet(cfg, "proxy", "http://proxy.example.com:8080")
+	assert.NoError(t, err)
+	saved = readUserConfig(t, tmpDir)
+	assert.Equal(t, "http://proxy.example.com:8080", saved.GetString("proxy"))
+
+	err = runConfigUnset(cfg, "proxy")
+	assert.NoError(t, err)
+	saved = readUserConfig(t, tmpDir)
+	assert.False(t, saved.IsSet("proxy"))
+
+	err = runConfigSet(cfg, "proxy", "proxy.example.com")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "invalid value", err.Error())
+	}
+
+	err = runConfigSet(cfg, "token", "")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "cannot be empty", err.Error())
+	}
+}
+
+func readUserConfig(t *testing.T, dir string) *viper.Viper {
+	v := viper.New()
+	v.AddConfigPath(dir)
+	v.SetConfigName("user")
+	v.SetConfigType("json")
+	assert.NoError(t, v.ReadInConfig())
+	return v
+}
+
+func TestConfigSetMetadataDir(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "config-metadatadir")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	cfg := config.Config{
+		Persister:       config.FilePersister{Dir: tmpDir},
+		UserViperConfig: viper.New(),
+	}
+
+	err = runConfigSet(cfg, "metadatadir", ".exercism-cli")
+	assert.NoError(t, err)
+	assert.Equal(t, ".exercism-cli", readUserConfig(t, tmpDir).GetString("metadatadir"))
+
+	err = runConfigSet(cfg, "metadatadir", "../elsewhere")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "invalid metadata directory name", err.Error())
+	}
+}
+
+func TestConfigSetNormalize(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "config-normalize")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	cfg := config.Config{
+		Persister:       config.FilePersister{Dir: tmpDir},
+		UserViperConfig: viper.New(),
+	}
+
+	err = runConfigSet(cfg, "normalize", "true")
+	assert.NoError(t, err)
+	assert.True(t, readUserConfig(t, tmpDir).GetBool("normalize"))
+
+	err = runConfigSet(cfg, "normalize", "sometimes")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "expected true or false", err.Error())
+	}
+}
+
+func TestConfigSetGzip(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "config-gzip")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	cfg := config.Config{
+		Persister:       config.FilePersister{Dir: tmpDir},
+		UserViperConfig: viper.New(),
+	}
+
+	err = runConfigSet(cfg, "gzip", "never")
+	assert.NoError(t, err)
+	assert.Equal(t, "never", readUserConfig(t, tmpDir).GetString("gzip"))
+
+	err = runConfigSet(cfg, "gzip", "sometimes")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "expected auto, always, or never", err.Error())
+	}
+}
+
+func TestConfigSetTimeout(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "config-timeout")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	cfg := config.Config{
+		Persister:       config.FilePersister{Dir: tmpDir},
+		UserViperConfig: viper.New(),
+	}
+
+	err = runConfigSet(cfg, "timeout", "300")
+	assert.NoError(t, err)
+	assert.Equal(t, 300, readUserConfig(t, tmpDir).GetInt("timeout"))
+
+	for _, value := range []string{"-1", "5m"} {
+		err = runConfigSet(cfg, "timeout", value)
+		if assert.Error(t, err) {
+			assert.Regexp(t, "expected a number of seconds", err.Error())
+		}
+	}
+}
+
+func TestConfigList(t *testing.T) {
+	oldOut := Out
+	defer func() {
+		Out = oldOut
+	}()
+
+	v := viper.New()
+	v.Set("token", "abcdefghijklmnop")
+	v.Set("workspace", "/home/alice/exercism")
+	v.Set("gzip", "never")
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+		DefaultBaseURL:  "http://example.com/v1",
+	}
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format:   "table",
+			expected: "KEY          VALUE                  SOURCE\napibaseurl   http://example.com/v1  default\ngzip         never                  user\nmetadatadir  .exercism              default\nnormalize    false                  default\ntoken        abcd*********nop       user\nworkspace    /home/alice/exercism   user\n",
+		},
+		{
+			format:   "json",
+			expected: "[\n  {\n    \"key\": \"apibaseurl\",\n    \"value\": \"http://example.com/v1\",\n    \"source\": \"default\"\n  },\n  {\n    \"key\": \"gzip\",\n    \"value\": \"never\",\n    \"source\": \"user\"\n  },\n  {\n    \"key\": \"metadatadir\",\n    \"value\": \".exercism\",\n    \"source\": \"default\"\n  },\n  {\n    \"key\": \"normalize\",\n    \"value\": \"false\",\n    \"source\": \"default\"\n  },\n  {\n    \"key\": \"token\",\n    \"value\": \"abcd*********nop\",\n    \"source\": \"user\"\n  },\n  {\n    \"key\": \"workspace\",\n    \"value\": \"/home/alice/exercism\",\n    \"source\": \"user\"\n  }\n]\n",
+		},
+		{
+			format:   "yaml",
+			expected: "- key: apibaseurl\n  value: http://example.com/v1\n  source: default\n- key: gzip\n  value: never\n  source: user\n- key: metadatadir\n  value: .exercism\n  source: default\n- key: normalize\n  value: \"false\"\n  source: default\n- key: token\n  value: abcd*********nop\n  source: user\n- key: workspace\n  value: /home/alice/exercism\n  source: user\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			Out = &buf
+			err := runConfigList(cfg, tc.format)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+}
+
+func TestConfigListDefaultWorkspace(t *testing.T) {
+	oldOut := Out
+	defer func() {
+		Out = oldOut
+	}()
+
+	cfg := config.Config{
+		OS:              "linux",
+		Home:            "/home/alice",
+		DefaultDirName:  "exercism",
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: viper.New(),
+	}
+
+	var buf bytes.Buffer
+	Out = &buf
+	err := runConfigList(cfg, "table")
+	assert.NoError(t, err)
+	assert.Regexp(t, "workspace +/home/alice/exercism +default", buf.String())
+}
+
+func TestLoadUserConfigFromStdin(t *testing.T) {
+	oldIn := In
+	oldSource := configSource
+	defer func() {
+		In = oldIn
+		configSource = oldSource
+	}()
+
+	configSource = "-"
+	In = strings.NewReader(`{"token": "abc123", "workspace": "/tmp/exercism"}`)
+
+	cfg, err := loadUserConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", cfg.UserViperConfig.GetString("token"))
+	assert.Equal(t, "/tmp/exercism", cfg.UserViperConfig.GetString("workspace"))
+
+	// Saving doesn't touch the disk, and neither do the caches or the history.
+	assert.Equal(t, config.InMemoryPersister{}, cfg.Persister)
+	assert.Equal(t, "", cfg.Dir)
+
+	In = strings.NewReader(`{"token": `)
+	_, err = loadUserConfig()
+	if assert.Error(t, err) {
+		assert.Regexp(t, "not valid JSON", err.Error())
+	}
+}
+
+func TestRootCommandAppliesUserSettings(t *testing.T) {
+	oldIn := In
+	oldSource := configSource
+	oldProxy, oldCLIProxy := api.ProxyURL, cli.ProxyURL
+	defer func() {
+		In = oldIn
+		configSource = oldSource
+		api.ProxyURL, cli.ProxyURL = oldProxy, oldCLIProxy
+		workspace.SetMetadataDirName("")
+	}()
+
+	configSource = "-"
+	In = strings.NewReader(`{"token": "abc123", "metadatadir": ".exercism-cli", "proxy": "http://proxy.example.com:8080"}`)
+
+	// The open command never reads the config itself.
+	err := RootCmd.PersistentPreRunE(openCmd, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, ".exercism-cli", workspace.MetadataDirName)
+	assert.Equal(t, "http://proxy.example.com:8080", api.ProxyURL)
+	assert.Equal(t, "http://proxy.example.com:8080", cli.ProxyURL)
+
+	// Standard input is only read once, but the command still gets the config.
+	cfg, err := loadUserConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", cfg.UserViperConfig.GetString("token"))
+
+	In = strings.NewReader(`{"metadatadir": "../elsewhere"}`)
+	err = RootCmd.PersistentPreRunE(openCmd, []string{})
+	if assert.Error(t, err) {
+		assert.Regexp(t, "invalid metadata directory name", err.Error())
+	}
+
+	// The config command still runs, so that the setting can be fixed.
+	err = RootCmd.PersistentPreRunE(configSetCmd, []string{})
+	assert.NoError(t, err)
+}
+
+func TestConfigWithConfigFromStdin(t *testing.T) {
+	oldSource := configSource
+	defer func() {
+		configSource = oldSource
+	}()
+	configSource = "-"
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: viper.New(),
+	}
+
+	// Changes that would go nowhere fail, rather than seem to work.
+	err := runConfigSet(cfg, "workspace", "/tmp/exercism")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "read from standard input with --config", err.Error())
+		assert.Regexp(t, "config set command has nowhere to save it", err.Error())
+	}
+	err = runConfigUnset(cfg, "workspace")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "config unset command", err.Error())
+	}
+
+	configSource = "/tmp/config.json"
+	err = runConfigSet(cfg, "workspace", "/tmp/exercism")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "read from /tmp/config.json with --config", err.Error())
+	}
+
+	// Standard input is taken up by the config, so nothing can be asked.
+	oldIn := In
+	defer func() {
+		In = oldIn
+	}()
+	In = strings.NewReader("y\n")
+	configSource = "-"
+	assert.False(t, canPrompt())
+	configSource = ""
+	assert.True(t, canPrompt())
+}
+
+func TestReadConfigFileLeniently(t *testing.T) {
+	oldErr := Err
+	var buf bytes.Buffer
+	Err = &buf
+	defer func() {
+		Err = oldErr
+	}()
+
+	dir, err := ioutil.TempDir("", "read-config-file")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	contents := `{
+  // Find the token on the website.
+  "token": "abc123",
+}`
+	err = ioutil.WriteFile(filepath.Join(dir, "user.json"), []byte(contents), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	readConfigFile(v, dir, "user")
+	assert.Equal(t, "abc123", v.GetString("token"))
+	assert.Regexp(t, "comments or trailing commas", buf.String())
+
+	// Saving it writes plain JSON.
+	err = config.FilePersister{Dir: dir}.Save(v, "user")
+	assert.NoError(t, err)
+	b, err := ioutil.ReadFile(filepath.Join(dir, "user.json"))
+	assert.NoError(t, err)
+	var settings map[string]interface{}
+	assert.NoError(t, json.Unmarshal(b, &settings))
+	assert.Equal(t, "abc123", settings["token"])
+
+	// A missing file is quietly ignored.
+	buf.Reset()
+	readConfigFile(viper.New(), filepath.Join(dir, "missing"), "user")
+	assert.Empty(t, buf.String())
+}
@@ -29,17 +29,48 @@ and tells the CLI about your setup so it puts things in the right
 places.
 
 You can also override certain default settings to suit your preferences.
+
+The API URL and the token are checked with the API before they're saved,
+and you're told which account the token belongs to. To save them without
+checking, e.g. while offline, pass --skip-verification.
+
+Without any flags, in a terminal, it asks for the settings one by one,
+like the setup command.
+
+To keep separate settings, e.g. for a self-hosted instance, configure a
+profile with --profile. Each profile has its own token, workspace, and
+API base URL. Pass --profile to any command to use it, or make it the
+default with the profile command.
+
+If you switch between APIs, e.g. production and staging, pass --per-api
+along with --api and --workspace to keep a separate workspace for that API.
+It is used whenever that API is the configured one.
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		configuration := config.NewConfig()
+		if err := checkConfigWritable("configure"); err != nil {
+			return err
+		}
+		configuration, err := newConfig(false)
+		if err != nil {
+			return err
+		}
 
 		viperConfig.AddConfigPath(configuration.Dir)
 		viperConfig.SetConfigName("user")
 		viperConfig.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = viperConfig.ReadInConfig()
+		// A missing file is fine.
+		readConfigFile(viperConfig, configuration.Dir, "user")
 		configuration.UserViperConfig = viperConfig
 
+		// Don't leave people who just call the command to figure out the flags.
+		// Picking a profile still counts as calling it without flags.
+		bare := true
+		cmd.LocalFlags().VisitAll(func(f *pflag.Flag) {
+			bare = bare && !f.Changed
+		})
+		if bare && isInteractive(In) {
+			return runSetup(configuration, In)
+		}
 		return runConfigure(configuration, cmd.Flags())
 	},
 }
@@ -79,7 +110,7 @@ func runConfigure(configuration config.Config, flags *pflag.FlagSet) error {
 	// By default we verify that
 	// - the configured API URL is reachable.
 	// - the configured token is valid.
-	skipVerification, err := flags.GetBool("no-verify")
+	skipVerification, err := flags.GetBool("skip-verification")
 	if err != nil {
 		return err
 	}
@@ -120,13 +151,21 @@ func runConfigure(configuration config.Config, flags *pflag.FlagSet) error {
 		if err != nil {
 			return err
 		}
-		ok, err := client.TokenIsValid()
+		info, err := client.ValidateToken()
 		if err != nil {
-			return err
+			msg := `
+
+    Unable to check the token with the API - %s
+
+    To save it without checking, call the command again with --skip-verification.
+
+`
+			return fmt.Errorf(msg, err)
 		}
-		if !ok {
+		if !info.Valid {
 			return fmt.Errorf("The token '%s' is invalid. Find your token on %s.", token, tokenURL)
 		}
+		reportTokenOwner(info)
 	}
 
 	// Finally, configure the token.
@@ -137,8 +176,12 @@ func runConfigure(configuration config.Config, flags *pflag.FlagSet) error {
 	if err != nil {
 		return err
 	}
+	perAPI, err := flags.GetBool("per-api")
+	if err != nil {
+		return err
+	}
 	if workspace == "" {
-		workspace = cfg.GetString("workspace")
+		workspace = config.WorkspaceFor(cfg)
 	}
 	workspace = config.Resolve(workspace, configuration.Home)
 
@@ -184,8 +227,12 @@ func runConfigure(configuration config.Config, flags *pflag.FlagSet) error {
 			return fmt.Errorf(msg, workspace, BinaryName, commandify(flags), workspace)
 		}
 	}
-	// Configure the workspace.
-	cfg.Set("workspace", workspace)
+	// Configure the workspace, either for this API only, or as the default.
+	if perAPI {
+		config.SetWorkspaceFor(cfg, baseURL, workspace)
+	} else {
+		cfg.Set("workspace", workspace)
+	}
 
 	// Persist the new configuration.
 	if err := configuration.Save("user"); err != nil {
@@ -196,6 +243,15 @@ func runConfigure(configuration config.Config, flags *pflag.FlagSet) error {
 	return nil
 }
 
+// reportTokenOwner confirms that the token works, and whose it is.
+func reportTokenOwner(info api.TokenInfo) {
+	if info.Handle == "" {
+		fmt.Fprintln(Err, "\nThe token is valid.")
+		return
+	}
+	fmt.Fprintf(Err, "\nThe token is valid, and belongs to %s.\n", info.Handle)
+}
+
 func printCurrentConfig(configuration config.Config) {
 	w := tabwriter.NewWriter(Err, 0, 0, 2, ' ', 0)
 	defer w.Flush()
@@ -203,9 +259,12 @@ func printCurrentConfig(configuration config.Config) {
 	v := configuration.UserViperConfig
 
 	fmt.Fprintln(w, "")
+	if configuration.Profile != "" && configuration.Profile != config.DefaultProfileName {
+		fmt.Fprintln(w, fmt.Sprintf("Profile:\t(--profile)\t%s", configuration.Profile))
+	}
 	fmt.Fprintln(w, fmt.Sprintf("Config dir:\t\t%s", configuration.Dir))
 	fmt.Fprintln(w, fmt.Sprintf("Token:\t(-t, --token)\t%s", v.GetString("token")))
-	fmt.Fprintln(w, fmt.Sprintf("Workspace:\t(-w, --workspace)\t%s", v.GetString("workspace")))
+	fmt.Fprintln(w, fmt.Sprintf("Workspace:\t(-w, --workspace)\t%s", config.WorkspaceFor(v)))
 	fmt.Fprintln(w, fmt.Sprintf("API Base URL:\t(-a, --api)\t%s", v.GetString("apibaseurl")))
 	fmt.Fprintln(w, "")
 }
@@ -231,7 +290,11 @@ func setupConfigureFlags(flags *pflag.FlagSet) {
 	flags.StringP("workspace", "w", "", "directory for exercism exercises")
 	flags.StringP("api", "a", "", "API base url")
 	flags.BoolP("show", "s", false, "show the current configuration")
-	flags.BoolP("no-verify", "", false, "skip online token authorization check")
+	flags.BoolP("skip-verification", "", false, "save the settings without checking the API URL and the token with the API")
+	flags.BoolP("per-api", "", false, "use the workspace only while talking to this API base url")
+	renameFlags(flags, map[string]renamedFlag{
+		"no-verify": {NewName: "skip-verification"},
+	})
 }
 
 func init() {
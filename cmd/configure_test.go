@@ -4,6 +4,7 @@ package cmd
 
 import (
 	"bytes"
+	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
@@ -100,25 +101,31 @@ func TestConfigureToken(t *testing.T) {
 		{
 			desc:       "It doesn't lose a configured value",
 			configured: "existing-token",
-			args:       []string{"--no-verify"},
+			args:       []string{"--skip-verification"},
 			expected:   "existing-token",
 		},
 		{
 			desc:       "It writes a token when passed as a flag",
 			configured: "",
-			args:       []string{"--no-verify", "--token", "a-token"},
+			args:       []string{"--skip-verification", "--token", "a-token"},
 			expected:   "a-token",
 		},
 		{
 			desc:       "It overwrites the token",
 			configured: "old-token",
-			args:       []string{"--no-verify", "--token", "replacement-token"},
+			args:       []string{"--skip-verification", "--token", "replacement-token"},
 			expected:   "replacement-token",
 		},
+		{
+			desc:       "It skips verification with --skip-verification",
+			configured: "",
+			args:       []string{"--skip-verification", "--token", "unverified-token"},
+			expected:   "unverified-token",
+		},
 		{
 			desc:       "It complains when token is neither configured nor passed",
 			configured: "",
-			args:       []string{"--no-verify"},
+			args:       []string{"--skip-verification"},
 			expected:   "",
 			err:        true,
 			message:    "no token configured",
@@ -184,6 +191,41 @@ func TestConfigureToken(t *testing.T) {
 	}
 }
 
+func TestConfigureReportsTokenOwner(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/validate_token" {
+			fmt.Fprint(w, `{"user": {"handle": "alice"}}`)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "configure-owner")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupConfigureFlags(flags)
+	err = flags.Parse([]string{"--token", "a-token", "--api", ts.URL, "--workspace", tmpDir})
+	assert.NoError(t, err)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: viper.New(),
+	}
+	var buf bytes.Buffer
+	Err = &buf
+	assert.NoError(t, runConfigure(cfg, flags))
+	assert.Contains(t, buf.String(), "The token is valid, and belongs to alice.")
+}
+
 func TestConfigureAPIBaseURL(t *testing.T) {
 	endpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.URL.Path == "/ping" {
@@ -204,19 +246,19 @@ func TestConfigureAPIBaseURL(t *testing.T) {
 		{
 			desc:       "It doesn't lose a configured value",
 			configured: "http://example.com",
-			args:       []string{"--no-verify"},
+			args:       []string{"--skip-verification"},
 			expected:   "http://example.com",
 		},
 		{
 			desc:       "It writes a base url when passed as a flag",
 			configured: "",
-			args:       []string{"--no-verify", "--api", "http://api.example.com"},
+			args:       []string{"--skip-verification", "--api", "http://api.example.com"},
 			expected:   "http://api.example.com",
 		},
 		{
 			desc:       "It overwrites the base url",
 			configured: "http://old.example.com",
-			args:       []string{"--no-verify", "--api", "http://replacement.example.com"},
+			args:       []string{"--skip-verification", "--api", "http://replacement.example.com"},
 			expected:   "http://replacement.example.com",
 		},
 		{
@@ -290,19 +332,19 @@ func TestConfigureWorkspace(t *testing.T) {
 		{
 			desc:       "It doesn't lose a configured value",
 			configured: "/the-workspace",
-			args:       []string{"--no-verify"},
+			args:       []string{"--skip-verification"},
 			expected:   "/the-workspace",
 		},
 		{
 			desc:       "It writes a workspace when passed as a flag",
 			configured: "",
-			args:       []string{"--no-verify", "--workspace", "/new-workspace"},
+			args:       []string{"--skip-verification", "--workspace", "/new-workspace"},
 			expected:   "/new-workspace",
 		},
 		{
 			desc:       "It overwrites the configured workspace",
 			configured: "/configured-workspace",
-			args:       []string{"--no-verify", "--workspace", "/replacement-workspace"},
+			args:       []string{"--skip-verification", "--workspace", "/replacement-workspace"},
 			expected:   "/replacement-workspace",
 		},
 		{
@@ -402,6 +444,42 @@ func TestConfigureDefaultWorkspaceWithoutClobbering(t *testing.T) {
 	assert.Regexp(t, "already something", err.Error())
 }
 
+func TestConfigurePerAPIWorkspace(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", "/default")
+	v.Set("apibaseurl", "http://production.example.com")
+
+	cfg := config.Config{
+		OS:              "linux",
+		UserViperConfig: v,
+		Persister:       config.InMemoryPersister{},
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupConfigureFlags(flags)
+	err := flags.Parse([]string{"--skip-verification", "--per-api", "--api", "http://staging.example.com", "--workspace", "/staging"})
+	assert.NoError(t, err)
+
+	err = runConfigure(cfg, flags)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "/default", v.GetString("workspace"))
+	assert.Equal(t, "/staging", config.WorkspaceFor(v))
+
+	v.Set("apibaseurl", "http://production.example.com")
+	assert.Equal(t, "/default", config.WorkspaceFor(v))
+}
+
 func TestConfigureExplicitWorkspaceWithoutClobberingNonDirectory(t *testing.T) {
 	oldOut := Out
 	oldErr := Err
@@ -434,7 +512,7 @@ func TestConfigureExplicitWorkspaceWithoutClobberingNonDirectory(t *testing.T) {
 
 	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
 	setupConfigureFlags(flags)
-	err = flags.Parse([]string{"--no-verify", "--workspace", config.DefaultWorkspaceDir(cfg)})
+	err = flags.Parse([]string{"--skip-verification", "--workspace", config.DefaultWorkspaceDir(cfg)})
 	assert.NoError(t, err)
 
 	err = runConfigure(cfg, flags)
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/spf13/pflag"
+)
+
+// renamedFlag points from a deprecated flag name to the one that replaced it.
+type renamedFlag struct {
+	NewName string
+	// Message is an optional note for people migrating, e.g. about changed semantics.
+	Message string
+}
+
+var (
+	// renamedFlags maps the deprecated flag names of each flag set to their
+	// replacements. They're kept by flag set, since the same name may mean
+	// something else to another command.
+	renamedFlags   = map[*pflag.FlagSet]map[string]renamedFlag{}
+	renamedFlagsMu sync.Mutex
+
+	warnedFlags   = map[string]bool{}
+	warnedFlagsMu sync.Mutex
+)
+
+// renameFlags keeps the old names of a command's renamed flags working.
+// The old names print a warning the first time they're used, and aren't
+// offered in the help. For example:
+//
+//	renameFlags(flags, map[string]renamedFlag{
+//		"api": {NewName: "api-base-url"},
+//	})
+func renameFlags(flags *pflag.FlagSet, renames map[string]renamedFlag) {
+	renamedFlagsMu.Lock()
+	defer renamedFlagsMu.Unlock()
+	renamedFlags[flags] = renames
+}
+
+// normalizeRenamedFlags maps deprecated flag names to their new names.
+// It is the flag name normalization function of all the commands.
+func normalizeRenamedFlags(f *pflag.FlagSet, name string) pflag.NormalizedName {
+	renamedFlagsMu.Lock()
+	renamed, ok := renamedFlags[f][name]
+	renamedFlagsMu.Unlock()
+	if !ok {
+		return pflag.NormalizedName(name)
+	}
+	warnRenamedFlag(name, renamed)
+	return pflag.NormalizedName(renamed.NewName)
+}
+
+func warnRenamedFlag(name string, renamed renamedFlag) {
+	warnedFlagsMu.Lock()
+	defer warnedFlagsMu.Unlock()
+	if warnedFlags[name] {
+		return
+	}
+	warnedFlags[name] = true
+
+	msg := `
+
+    WARNING: --%s is deprecated. Use --%s instead.
+`
+	fmt.Fprintf(Err, msg, name, renamed.NewName)
+	if renamed.Message != "" {
+		fmt.Fprintf(Err, "             %s\n", renamed.Message)
+	}
+	fmt.Fprintln(Err)
+}
@@ -0,0 +1,101 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/spf13/pflag"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRenamedFlag(t *testing.T) {
+	oldErr := Err
+	var buf bytes.Buffer
+	Err = &buf
+	defer func() {
+		Err = oldErr
+		delete(warnedFlags, "no-verify")
+	}()
+	// Other tests may have used it already.
+	delete(warnedFlags, "no-verify")
+
+	parse := func(args ...string) *pflag.FlagSet {
+		flags := pflag.NewFlagSet("fake", pflag.ContinueOnError)
+		flags.SetNormalizeFunc(normalizeRenamedFlags)
+		setupConfigureFlags(flags)
+		assert.NoError(t, flags.Parse(args))
+		return flags
+	}
+
+	// The new name doesn't warn.
+	flags := parse("--skip-verification")
+	skip, err := flags.GetBool("skip-verification")
+	assert.NoError(t, err)
+	assert.True(t, skip)
+	assert.Empty(t, buf.String())
+
+	// The old name still works, and warns.
+	flags = parse("--no-verify", "--token", "a-token")
+	skip, err = flags.GetBool("skip-verification")
+	assert.NoError(t, err)
+	assert.True(t, skip)
+	assert.True(t, flags.Changed("skip-verification"))
+	assert.Regexp(t, "--no-verify is deprecated. Use --skip-verification instead.", buf.String())
+	// Suggested commands use the new name.
+	assert.Equal(t, "--skip-verification=true --token=a-token", commandify(flags))
+
+	// The warning is only printed once.
+	flags = parse("--no-verify=false")
+	skip, err = flags.GetBool("skip-verification")
+	assert.NoError(t, err)
+	assert.False(t, skip)
+	assert.Equal(t, 1, strings.Count(buf.String(), "deprecated"))
+
+	// The old name isn't offered in the help.
+	assert.NotContains(t, flags.FlagUsages(), "no-verify")
+}
+
+func TestRenamedFlagsAreWiredIntoTheCommands(t *testing.T) {
+	oldErr := Err
+	Err = &bytes.Buffer{}
+	defer func() {
+		Err = oldErr
+		delete(warnedFlags, "no-verify")
+	}()
+
+	// The root command hands the renames to configure.
+	f := configureCmd.Flags().Lookup("no-verify")
+	if assert.NotNil(t, f) {
+		assert.Equal(t, "skip-verification", f.Name)
+	}
+
+	// Submit has a --no-verify of its own, which is left alone.
+	f = submitCmd.Flags().Lookup("no-verify")
+	if assert.NotNil(t, f) {
+		assert.Equal(t, "no-verify", f.Name)
+	}
+}
+
+func TestRenamedFlagMessage(t *testing.T) {
+	oldErr := Err
+	var buf bytes.Buffer
+	Err = &buf
+	defer func() {
+		Err = oldErr
+		delete(warnedFlags, "old-name")
+	}()
+
+	flags := pflag.NewFlagSet("fake", pflag.ContinueOnError)
+	flags.SetNormalizeFunc(normalizeRenamedFlags)
+	flags.String("new-name", "", "")
+	renameFlags(flags, map[string]renamedFlag{
+		"old-name": {NewName: "new-name", Message: "It means the same thing."},
+	})
+	assert.NoError(t, flags.Parse([]string{"--old-name", "a"}))
+	value, err := flags.GetString("new-name")
+	assert.NoError(t, err)
+	assert.Equal(t, "a", value)
+	assert.Regexp(t, "--old-name is deprecated. Use --new-name instead.", buf.String())
+	assert.Regexp(t, "It means the same thing.", buf.String())
+}
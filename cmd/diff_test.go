@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			desc:     "identical",
+			old:      "a\nb\n",
+			new:      "a\nb\n",
+			expected: "",
+		},
+		{
+			desc: "changed line",
+			old:  "one\ntwo\nthree\n",
+			new:  "one\n2\nthree\n",
+			expected: `--- a/file
++++ b/file
+@@ -1,3 +1,3 @@
+ one
+-two
++2
+ three
+`,
+		},
+		{
+			desc: "new file",
+			old:  "",
+			new:  "hello\n",
+			expected: `--- a/file
++++ b/file
+@@ -0,0 +1,1 @@
++hello
+`,
+		},
+		{
+			desc: "separate hunks",
+			old:  "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n",
+			new:  "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\ntwelve\n",
+			expected: `--- a/file
++++ b/file
+@@ -1,4 +1,4 @@
+-1
++one
+ 2
+ 3
+ 4
+@@ -9,4 +9,4 @@
+ 9
+ 10
+ 11
+-12
++twelve
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, unifiedDiff("a/file", "b/file", []byte(tc.old), []byte(tc.new)))
+		})
+	}
+}
+
+func TestDiffLinesLargeFiles(t *testing.T) {
+	var a, b []string
+	for i := 0; i < 20000; i++ {
+		a = append(a, fmt.Sprintf("old %d", i))
+		b = append(b, fmt.Sprintf("new %d", i))
+	}
+	a = append([]string{"same"}, append(a, "end")...)
+	b = append([]string{"same"}, append(b, "end")...)
+
+	// Too many lines changed to compare them, so they're replaced as a block.
+	ops := diffLines(a, b)
+	assert.Equal(t, 40002, len(ops))
+	assert.Equal(t, diffOp{' ', "same"}, ops[0])
+	assert.Equal(t, diffOp{'-', "old 0"}, ops[1])
+	assert.Equal(t, diffOp{'+', "new 0"}, ops[20001])
+	assert.Equal(t, diffOp{' ', "end"}, ops[40001])
+}
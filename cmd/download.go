@@ -1,16 +1,20 @@
 package cmd
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"io"
+	"io/ioutil"
 	"net/http"
 	netURL "net/url"
 	"os"
 	"path/filepath"
 	"regexp"
 	"strings"
+	"time"
 
 	"github.com/exercism/cli/api"
 	"github.com/exercism/cli/config"
@@ -32,17 +36,81 @@ started working on it, the command will also download your
 latest solution.
 
 Download other people's solutions by providing the UUID.
+
+Instead of the flags, you can pass the address of an exercise or
+solution, as copied from the website:
+
+    exercism download https://exercism.org/tracks/go/exercises/hamming
+
+Local files that differ from the downloaded ones are kept as they are,
+unless you pass --force to replace them, or --backup to replace them
+after making a copy in the exercise's .exercism/backups directory.
+
+To get back the files of your latest iteration, e.g. on another computer,
+call the command with --latest from within the exercise. Your local
+changes are kept, unless you pass --force or --backup.
+
+To study how others solved an exercise, pass --community with the number
+of published solutions to download, from within the exercise or along
+with its --exercise and --track. They go into its community directory,
+which is never submitted.
+
+To read the feedback on your solution offline, call the command with
+--mentor-notes from within the exercise. The comments of your mentors,
+and the code they suggested, are written to MENTOR_NOTES.md.
+
+To pick up changes to the tests of an exercise you've already downloaded,
+pass --update-tests. Only the test and editor files named in the exercise's
+config are downloaded, and they replace your copies. Your solution is left
+alone.
+
+The files are only put in place once all of them have arrived. If a
+download is interrupted, downloading again fetches just the files that
+are still missing. In a terminal, a progress bar shows how many of the
+files have arrived, and any that fail are reported right away, while the
+rest keep downloading.
+
+Downloads are cached in the config directory. Downloading an exercise
+again only fetches its files if they changed, and works offline. Pass
+--no-cache to skip the cache, and call "exercism cache clear" to empty it.
+
+A command to run in the exercise once it's downloaded, such as installing
+its dependencies, can be set for a track or a single exercise in the hooks
+section of the config, e.g.
+
+    "hooks": {"postdownload": [{"track": "go", "command": "go mod tidy"}]}
+
+If it fails, the exercise is still downloaded. Pass --no-hooks to skip it.
+It's not run for --all or --community.
+
+Once an exercise is downloaded, its instructions are shown, through your
+$PAGER if there is one. Pass --no-instructions to skip them.
+
+If the metadata of an exercise got lost or broken, so that it can no
+longer be submitted, pass --repair along with its --exercise and --track,
+or from within its directory. Only the metadata is downloaded again, and
+your files are left alone.
+
+To download all the exercises of a track that are available to you,
+pass --all along with the --track. If that goes over the API's rate
+limit, the requests wait as long as the API asks, and are retried.
+
+To download an exercise of a team's track, e.g. for a class, pass the
+--team slug. The exercise goes into the teams directory of the workspace,
+and is submitted to the team. Along with --all, it downloads the exercises
+of the team's track, which may be private to the team.
+
+To keep exercises outside the workspace, e.g. in a repository per track,
+pass the --output directory. The exercise goes into a directory of its
+own in there, and the other commands find it by its metadata.
+
+    exercism download --exercise=hamming --track=go --output=~/code/exercism-go
 `,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg := config.NewConfig()
-
-		v := viper.New()
-		v.AddConfigPath(cfg.Dir)
-		v.SetConfigName("user")
-		v.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = v.ReadInConfig()
-		cfg.UserViperConfig = v
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
 
 		return runDownload(cfg, cmd.Flags(), args)
 	},
@@ -50,10 +118,14 @@ Download other people's solutions by providing the UUID.
 
 func runDownload(cfg config.Config, flags *pflag.FlagSet, args []string) error {
 	usrCfg := cfg.UserViperConfig
-	if usrCfg.GetString("token") == "" {
+	token, err := config.ResolveToken("", cfg)
+	if err != nil {
+		return err
+	}
+	if token == "" {
 		return fmt.Errorf(msgWelcomePleaseConfigure, config.SettingsURL(usrCfg.GetString("apibaseurl")), BinaryName)
 	}
-	if usrCfg.GetString("workspace") == "" || usrCfg.GetString("apibaseurl") == "" {
+	if config.WorkspaceFor(usrCfg) == "" || usrCfg.GetString("apibaseurl") == "" {
 		return fmt.Errorf(msgRerunConfigure, BinaryName)
 	}
 
@@ -65,71 +137,458 @@ func runDownload(cfg config.Config, flags *pflag.FlagSet, args []string) error {
 	if err != nil {
 		return err
 	}
-	if uuid != "" && slug != "" || uuid == slug {
-		return errors.New("need an --exercise name or a solution --uuid")
+	track, err := flags.GetString("track")
+	if err != nil {
+		return err
+	}
+	workers, err := flags.GetInt("workers")
+	if err != nil {
+		return err
+	}
+	if workers < 1 {
+		return fmt.Errorf("invalid --workers '%d'. Use at least 1", workers)
 	}
 
-	track, err := flags.GetString("track")
+	all, err := flags.GetBool("all")
+	if err != nil {
+		return err
+	}
+	latest, err := flags.GetBool("latest")
+	if err != nil {
+		return err
+	}
+	community, err := flags.GetInt("community")
 	if err != nil {
 		return err
 	}
+	if community < 0 {
+		return fmt.Errorf("invalid --community '%d'. Use the number of solutions to download", community)
+	}
+	mentorNotes, err := flags.GetBool("mentor-notes")
+	if err != nil {
+		return err
+	}
+	if latest && community > 0 {
+		return errors.New("use only one of --latest and --community")
+	}
+	output, err := flags.GetString("output")
+	if err != nil {
+		return err
+	}
+	repair, err := flags.GetBool("repair")
+	if err != nil {
+		return err
+	}
+	if repair && (latest || community > 0 || mentorNotes || all) {
+		return errors.New("--repair fixes the metadata of a single exercise, so it can't be combined with --latest, --community, --mentor-notes, or --all")
+	}
+	var repairDir string
+	if output != "" && (latest || community > 0 || mentorNotes) {
+		return errors.New("--output can't be combined with --latest, --community, or --mentor-notes, which work with the exercise you're in")
+	}
+	if mentorNotes {
+		if latest || community > 0 || all || uuid != "" || slug != "" || track != "" || len(args) > 0 {
+			return errors.New("--mentor-notes fetches the discussion of the exercise you're in, so it can't be combined with other ways of picking what to download")
+		}
+		loc, err := currentExercise("--mentor-notes")
+		if err != nil {
+			return err
+		}
+		client, err := api.NewClient(token, usrCfg.GetString("apibaseurl"))
+		if err != nil {
+			return err
+		}
+		return downloadMentorNotes(client, loc)
+	}
+	var params downloadParams
+	if latest {
+		if all || uuid != "" || slug != "" || track != "" || len(args) > 0 {
+			return errors.New("--latest downloads the exercise you're in, so it can't be combined with --all, an exercise, or a --uuid")
+		}
+		loc, err := currentExercise("--latest")
+		if err != nil {
+			return err
+		}
+		params = downloadParams{dir: loc.Dir}
+		if loc.Solution.ID != "" {
+			params.uuid = loc.Solution.ID
+		} else {
+			params.track = loc.Solution.Track
+			params.exercise = loc.Solution.Exercise
+			params.team = loc.Solution.Team
+		}
+	} else if community > 0 {
+		if all || uuid != "" || len(args) > 0 {
+			return errors.New("--community downloads solutions to one exercise, so it can't be combined with --all, the address of an exercise, or a --uuid")
+		}
+		if slug != "" && track == "" {
+			return errors.New("--community needs the --track of the --exercise")
+		}
+		if slug != "" {
+			params = downloadParams{track: track, exercise: slug, dir: filepath.Join(config.WorkspaceFor(usrCfg), track, slug)}
+		} else {
+			loc, err := currentExercise("--community")
+			if err != nil {
+				return err
+			}
+			params = downloadParams{track: loc.Solution.Track, exercise: loc.Solution.Exercise, dir: loc.Dir}
+		}
+	} else if all {
+		if uuid != "" || slug != "" || len(args) > 0 {
+			return errors.New("--all downloads every exercise of a --track, so it can't be combined with an exercise or a --uuid")
+		}
+		if track == "" {
+			return errors.New("--all needs a --track to download the exercises of")
+		}
+	} else {
+		if repair && len(args) == 0 && uuid == "" && slug == "" && track == "" {
+			// The metadata is what says which exercise this is,
+			// so go by where the directory is in the workspace.
+			dir, err := findRepairDir(config.WorkspaceFor(usrCfg))
+			if err != nil {
+				return err
+			}
+			exercise := workspace.NewExerciseFromDir(dir)
+			track, slug, repairDir = exercise.Track, exercise.Slug, dir
+		}
+		if len(args) > 0 {
+			if len(args) > 1 || uuid != "" || slug != "" || track != "" {
+				return errors.New("give either the address of an exercise, or the --exercise, --track, and --uuid flags")
+			}
+			if track, slug, uuid, err = parseExerciseURL(args[0]); err != nil {
+				return err
+			}
+		}
+		if uuid != "" && slug != "" || uuid == slug {
+			return errors.New("need an --exercise name or a solution --uuid")
+		}
+	}
 
 	team, err := flags.GetString("team")
 	if err != nil {
 		return err
 	}
+	if team != "" {
+		if err := config.ValidateTeamSlug(team); err != nil {
+			return err
+		}
+	}
 
-	param := "latest"
-	if uuid != "" {
-		param = uuid
+	onConflict, err := flags.GetString("on-conflict")
+	if err != nil {
+		return err
+	}
+	switch onConflict {
+	case conflictKeep, conflictOverwrite, conflictMerge, conflictBackup:
+	default:
+		return fmt.Errorf("invalid --on-conflict strategy '%s'. Use one of: %s, %s, %s, %s", onConflict, conflictKeep, conflictOverwrite, conflictMerge, conflictBackup)
+	}
+	// --force and --backup are shorthands for the strategies.
+	for flag, strategy := range map[string]string{"force": conflictOverwrite, "backup": conflictBackup} {
+		ok, err := flags.GetBool(flag)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if flags.Changed("on-conflict") || onConflict != conflictKeep {
+			return errors.New("use only one of --on-conflict, --force, and --backup")
+		}
+		onConflict = strategy
 	}
-	url := fmt.Sprintf("%s/solutions/%s", usrCfg.GetString("apibaseurl"), param)
 
-	client, err := api.NewClient(usrCfg.GetString("token"), usrCfg.GetString("apibaseurl"))
+	client, err := api.NewClient(token, usrCfg.GetString("apibaseurl"))
 	if err != nil {
 		return err
 	}
 
-	req, err := client.NewRequest("GET", url, nil)
+	updateTests, err := flags.GetBool("update-tests")
 	if err != nil {
 		return err
 	}
+	if repair && (updateTests || onConflict != conflictKeep) {
+		return errors.New("--repair leaves the files alone, so it can't be combined with --update-tests, --on-conflict, --force, or --backup")
+	}
+	if updateTests && onConflict != conflictKeep {
+		return errors.New("--update-tests always replaces the test files, so it can't be combined with --on-conflict, --force, or --backup")
+	}
 
-	if uuid == "" {
-		q := req.URL.Query()
-		q.Add("exercise_id", slug)
-		if track != "" {
-			q.Add("track_id", track)
+	noCache, err := flags.GetBool("no-cache")
+	if err != nil {
+		return err
+	}
+
+	opts := downloadOptions{onConflict: onConflict, workers: workers, updateTests: updateTests, outputDir: config.Resolve(output, cfg.Home)}
+	if !noCache && cfg.Dir != "" {
+		opts.cacheDir = filepath.Join(cfg.Dir, downloadCacheDirName)
+	}
+	defer removeEmptyStagingDirs(config.WorkspaceFor(usrCfg))
+	if all {
+		return downloadTrack(client, usrCfg, track, team, opts)
+	}
+	if community > 0 {
+		return downloadCommunity(client, usrCfg, params, community, opts)
+	}
+
+	if !latest {
+		params = downloadParams{uuid: uuid, track: track, exercise: slug, team: team, dir: repairDir}
+	}
+	if repair {
+		opts.metadataOnly = true
+		solution, err := downloadExercise(client, usrCfg, params, opts)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(Err, "\nRepaired the metadata of\n")
+		fmt.Fprintf(Out, "%s\n", solution.Dir)
+		return nil
+	}
+	opts.showProgress = true
+	solution, err := downloadExercise(client, usrCfg, params, opts)
+	if err != nil {
+		return err
+	}
+	noHooks, err := flags.GetBool("no-hooks")
+	if err != nil {
+		return err
+	}
+	if !noHooks {
+		runPostDownloadHook(usrCfg, solution)
+	}
+	noInstructions, err := flags.GetBool("no-instructions")
+	if err != nil {
+		return err
+	}
+	if !noInstructions && !updateTests {
+		if err := showInstructions(solution.Dir); err != nil {
+			fmt.Fprintf(Err, "\nWARNING: Unable to show the instructions - %s\n", err)
 		}
-		if team != "" {
-			q.Add("team_id", team)
+	}
+	fmt.Fprintf(Err, "\nDownloaded to\n")
+	fmt.Fprintf(Out, "%s\n", solution.Dir)
+	return nil
+}
+
+// findRepairDir finds the directory of the exercise that the working
+// directory is in, for --repair. That's the nearest one with metadata,
+// however broken, or else the track and exercise level of the workspace.
+func findRepairDir(workspaceDir string) (string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	if resolved, err := filepath.EvalSymlinks(workspaceDir); err == nil {
+		workspaceDir = resolved
+	}
+	if resolved, err := filepath.EvalSymlinks(cwd); err == nil {
+		cwd = resolved
+	}
+
+	// The workspace itself has a metadata dir, for downloads in progress.
+	for dir := cwd; dir != workspaceDir; dir = filepath.Dir(dir) {
+		if ok, _ := workspace.NewExerciseFromDir(dir).HasMetadata(); ok {
+			return dir, nil
+		}
+		if _, err := os.Stat(workspace.ExerciseConfigPath(dir)); err == nil {
+			return dir, nil
+		}
+		if filepath.Dir(dir) == dir {
+			break
 		}
-		req.URL.RawQuery = q.Encode()
 	}
 
-	res, err := client.Do(req)
+	rel, err := filepath.Rel(workspaceDir, cwd)
+	if err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		segments := strings.Split(rel, string(filepath.Separator))
+		// Team exercises, and other people's solutions, are a level further down.
+		depth := 2
+		if segments[0] == "teams" || segments[0] == "users" {
+			depth = 4
+		}
+		if len(segments) >= depth {
+			return filepath.Join(workspaceDir, filepath.Join(segments[:depth]...)), nil
+		}
+	}
+	msg := `
+
+    There is no exercise to repair in %s.
+    Call the command with --repair from within the exercise's directory,
+    or give its --exercise and --track.
+
+`
+	return "", fmt.Errorf(msg, cwd)
+}
+
+// currentExercise finds the exercise that the working directory is in.
+// The flag that needs it is named in the error if there is none.
+func currentExercise(flag string) (workspace.Location, error) {
+	cwd, err := os.Getwd()
 	if err != nil {
-		return err
+		return workspace.Location{}, err
+	}
+	loc, err := workspace.DiscoverExercise(cwd, "", "")
+	if workspace.IsMissingMetadata(err) {
+		msg := `
+
+    Call the command with %s from within an exercise directory.
+
+`
+		return workspace.Location{}, fmt.Errorf(msg, flag)
+	}
+	return loc, err
+}
+
+// parseExerciseURL finds what to download from the address of an exercise,
+// e.g. https://exercism.org/tracks/go/exercises/hamming, or of a solution,
+// e.g. https://exercism.org/mentor/solutions/a1b2c3.
+func parseExerciseURL(rawURL string) (track, exercise, uuid string, err error) {
+	u, err := netURL.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", "", "", fmt.Errorf("'%s' is not the address of an exercise", rawURL)
+	}
+
+	segments := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i+1 < len(segments); i++ {
+		switch {
+		case segments[i] == "tracks" && i+3 < len(segments) && segments[i+2] == "exercises":
+			return segments[i+1], segments[i+3], "", nil
+		case segments[i] == "solutions" && segments[i+1] != "":
+			return "", "", segments[i+1], nil
+		}
+	}
+	return "", "", "", fmt.Errorf("'%s' is not the address of an exercise or a solution", rawURL)
+}
+
+// downloadParams say which solution to download: either the one with the
+// UUID, or the user's own solution to the exercise.
+type downloadParams struct {
+	uuid     string
+	track    string
+	exercise string
+	team     string
+	// dir is where the exercise goes, if not where it belongs in the workspace.
+	dir string
+}
+
+// downloadOptions say how to go about downloading.
+type downloadOptions struct {
+	// onConflict is the strategy for local files that differ from the download.
+	onConflict string
+	// workers is how many requests are made at the same time.
+	workers int
+	// updateTests limits the download to the exercise's test and editor
+	// files, which replace the local ones.
+	updateTests bool
+	// cacheDir is where downloads are cached. There's no caching without it.
+	cacheDir string
+	// outputDir holds the exercises, if not the workspace.
+	outputDir string
+	// metadataOnly writes just the solution metadata, into an exercise
+	// that's already there, and doesn't touch its files.
+	metadataOnly bool
+	// showProgress draws the progress of the files on terminals. It's off
+	// when several exercises are downloaded at once.
+	showProgress bool
+}
+
+// downloadExercise fetches a solution and writes its files and metadata
+// into the workspace.
+func downloadExercise(client *api.Client, usrCfg *viper.Viper, params downloadParams, opts downloadOptions) (*workspace.Solution, error) {
+	param := "latest"
+	if params.uuid != "" {
+		param = params.uuid
+	}
+	url := fmt.Sprintf("%s/solutions/%s", usrCfg.GetString("apibaseurl"), param)
+	if params.team != "" {
+		url = config.TeamSolutionURL(usrCfg.GetString("apibaseurl"), params.team, param)
+	}
+
+	req, err := client.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if params.uuid == "" {
+		q := req.URL.Query()
+		q.Add("exercise_id", params.exercise)
+		if params.track != "" {
+			q.Add("track_id", params.track)
+		}
+		req.URL.RawQuery = q.Encode()
+	}
+
+	// A cached solution is only sent again if it changed since.
+	var cache *downloadCache
+	var cached *cachedPayload
+	if opts.cacheDir != "" {
+		cache = &downloadCache{dir: opts.cacheDir}
+		if cached = cache.lookup(req.URL.String()); cached != nil {
+			req.Header.Set("If-None-Match", cached.ETag)
+		}
 	}
 
 	var payload downloadPayload
-	defer res.Body.Close()
-	if err := json.NewDecoder(res.Body).Decode(&payload); err != nil {
-		return fmt.Errorf("unable to parse API response - %s", err)
+	res, err := client.Do(req)
+	if err != nil && cached == nil {
+		return nil, err
 	}
+	if err != nil {
+		msg := `
 
-	if res.StatusCode == http.StatusUnauthorized {
-		siteURL := config.InferSiteURL(usrCfg.GetString("apibaseurl"))
-		return fmt.Errorf("unauthorized request. Please run the configure command. You can find your API token at %s/my/settings", siteURL)
+    WARNING: Unable to reach the API - %s
+             Using the copy from the download cache.
+
+`
+		fmt.Fprintf(Err, msg, err)
+	} else {
+		defer res.Body.Close()
 	}
+	if err == nil && (res.StatusCode != http.StatusNotModified || cached == nil) {
+		body, err := ioutil.ReadAll(res.Body)
+		if err != nil {
+			return nil, err
+		}
+		parseErr := json.Unmarshal(body, &payload)
+		if params.team != "" && res.StatusCode == http.StatusNotFound && payload.Error.Message == "" {
+			msg := `
 
-	if res.StatusCode != http.StatusOK {
-		switch payload.Error.Type {
-		case "track_ambiguous":
-			return fmt.Errorf("%s: %s", payload.Error.Message, strings.Join(payload.Error.PossibleTrackIDs, ", "))
-		default:
-			return errors.New(payload.Error.Message)
+    Unable to download from the team '%s'.
+    Either the team doesn't exist, or you are not a member of it.
+
+`
+			return nil, fmt.Errorf(msg, params.team)
+		}
+		if parseErr != nil {
+			return nil, fmt.Errorf("unable to parse API response - %s", parseErr)
 		}
+
+		if res.StatusCode == http.StatusUnauthorized {
+			siteURL := config.InferSiteURL(usrCfg.GetString("apibaseurl"))
+			return nil, fmt.Errorf("unauthorized request. Please run the configure command. You can find your API token at %s/my/settings", siteURL)
+		}
+
+		if res.StatusCode != http.StatusOK {
+			switch payload.Error.Type {
+			case "track_ambiguous":
+				return nil, fmt.Errorf("%s: %s", payload.Error.Message, strings.Join(payload.Error.PossibleTrackIDs, ", "))
+			default:
+				return nil, errors.New(payload.Error.Message)
+			}
+		}
+
+		cached = nil
+		etag := res.Header.Get("ETag")
+		if cache != nil && etag != "" && payload.Solution.ID != "" {
+			entry := cachedPayload{SolutionID: payload.Solution.ID, ETag: etag, Payload: body}
+			if err := cache.store(req.URL.String(), entry); err != nil {
+				fmt.Fprintf(Err, "\nWARNING: Unable to cache the download - %s\n", err)
+			} else {
+				cached = &entry
+			}
+		}
+	} else if err := json.Unmarshal(cached.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("unable to parse cached API response - %s", err)
 	}
 
 	solution := workspace.Solution{
@@ -141,9 +600,12 @@ func runDownload(cfg config.Config, flags *pflag.FlagSet, args []string) error {
 		URL:         payload.Solution.URL,
 		Handle:      payload.Solution.User.Handle,
 		IsRequester: payload.Solution.User.IsRequester,
+		Difficulty:  payload.Solution.Exercise.Difficulty,
+		Blurb:       payload.Solution.Exercise.Blurb,
+		Status:      payload.Solution.Status,
 	}
 
-	root := usrCfg.GetString("workspace")
+	root := config.WorkspaceFor(usrCfg)
 	if solution.Team != "" {
 		root = filepath.Join(root, "teams", solution.Team)
 	}
@@ -158,85 +620,407 @@ func runDownload(cfg config.Config, flags *pflag.FlagSet, args []string) error {
 	}
 
 	dir := exercise.MetadataDir()
-
-	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
-		return err
+	if params.dir != "" {
+		dir = params.dir
+	} else if opts.outputDir != "" {
+		dir = filepath.Join(opts.outputDir, solution.Exercise)
+	}
+	if opts.metadataOnly {
+		if _, err := os.Stat(dir); err != nil {
+			return nil, fmt.Errorf("there is no exercise to repair in %s. Download it without --repair", dir)
+		}
+		if err := solution.Write(dir); err != nil {
+			return nil, err
+		}
+		return &solution, nil
 	}
 
-	err = solution.Write(dir)
-	if err != nil {
-		return err
+	// The files are fetched into a staging area, and only moved into place
+	// once all of them have arrived. If some fail, the ones that didn't are
+	// kept there for the next attempt.
+	key := solution.ID
+	if key == "" {
+		key = solution.Track + "-" + solution.Exercise
+	}
+	staging := openStagingArea(filepath.Join(config.WorkspaceFor(usrCfg), workspace.MetadataDirName, stagingDirName, key))
+	var cachedFiles *stagingArea
+	if cached != nil {
+		cachedFiles = cache.files(cached.SolutionID)
 	}
 
-	for _, file := range payload.Solution.Files {
-		unparsedURL := fmt.Sprintf("%s%s", payload.Solution.FileDownloadBaseURL, file)
-		parsedURL, err := netURL.ParseRequestURI(unparsedURL)
+	files := payload.Solution.Files
+	strategy := opts.onConflict
+	if opts.updateTests {
+		if files, err = upstreamFiles(dir, files, solution.Exercise); err != nil {
+			return nil, err
+		}
+		strategy = conflictOverwrite
+	}
+	remote := make([]remoteFile, 0, len(files))
+	paths := map[string]bool{}
+	for _, file := range files {
+		remote = append(remote, remoteFile{key: file, name: file, baseURL: payload.Solution.FileDownloadBaseURL, checksum: payload.Solution.FileChecksums[file]})
+		paths[downloadPath(file, solution.Exercise)] = true
+	}
+	if !opts.updateTests {
+		// The exercise's own files take precedence over the track's templates.
+		track := payload.Solution.Exercise.Track
+		for _, file := range track.TemplateFiles {
+			if paths[downloadPath(file, solution.Exercise)] {
+				continue
+			}
+			remote = append(remote, remoteFile{key: "template:" + file, name: file, baseURL: track.TemplatesDownloadBaseURL, checksum: track.TemplateChecksums[file], template: true})
+		}
+	}
 
+	statuses := make([]string, len(remote))
+	errs := make([]error, len(remote))
+	var progress *downloadProgress
+	if opts.showProgress && isTerminal(Err) {
+		progress = newDownloadProgress(Err, len(remote))
+	}
+	fetch := func(i int) {
+		if staging.has(remote[i].key) {
+			return
+		}
+		if cachedFiles != nil {
+			if download, ok := cachedFiles.get(remote[i].key); ok {
+				errs[i] = staging.add(remote[i].key, download, remote[i].template)
+				return
+			}
+		}
+		download, err := fetchVerifiedFile(client, remote[i], solution.Exercise)
 		if err != nil {
-			return err
+			errs[i] = err
+			return
+		}
+		if download.status != "" {
+			statuses[i] = download.status
+			return
 		}
+		errs[i] = staging.add(remote[i].key, download, remote[i].template)
+		if errs[i] == nil && cachedFiles != nil {
+			// The download doesn't depend on the cache, so failing to fill it is fine.
+			cachedFiles.add(remote[i].key, download, remote[i].template)
+		}
+	}
+	forEachConcurrently(len(remote), opts.workers, func(i int) error {
+		fetch(i)
+		progress.add(remote[i].name, errs[i])
+		return nil
+	})
+	progress.Stop()
 
-		url := parsedURL.String()
+	var failed []string
+	keys := make([]string, 0, len(remote))
+	for i, r := range remote {
+		file := r.name
+		keys = append(keys, r.key)
+		if errs[i] != nil {
+			failed = append(failed, fmt.Sprintf("%s - %s", file, errs[i]))
+			continue
+		}
+		if statuses[i] != "" {
+			msg := `
 
-		req, err := client.NewRequest("GET", url, nil)
-		if err != nil {
-			return err
+    WARNING: Unable to download %s
+             API returned %s
+
+`
+			fmt.Fprintf(Err, msg, file, statuses[i])
 		}
+	}
+	if len(failed) > 0 {
+		return nil, fmt.Errorf("unable to download %d of the files of %s:\n    %s\nDownload it again to fetch the rest", len(failed), solution.Exercise, strings.Join(failed, "\n    "))
+	}
 
-		res, err := client.Do(req)
-		if err != nil {
-			return err
+	backupDir := filepath.Join(dir, workspace.MetadataDirName, backupsDir, time.Now().Format("20060102-150405"))
+	// Editor files support the solution, and aren't meant to be changed,
+	// so they're kept up to date regardless of the strategy.
+	exerciseConfig := staging.exerciseConfig(dir)
+	strategyFor := func(rel string) string {
+		if exerciseConfig != nil && exerciseConfig.IsEditor(rel) {
+			return conflictOverwrite
 		}
-		defer res.Body.Close()
+		return strategy
+	}
+	if err := staging.install(dir, keys, strategyFor, backupDir); err != nil {
+		return nil, err
+	}
+	// The metadata goes in last, so that an exercise is only recognized
+	// as such once it's complete.
+	if err := solution.Write(dir); err != nil {
+		return nil, err
+	}
+	if err := staging.remove(); err != nil {
+		return nil, err
+	}
+	return &solution, nil
+}
 
-		if res.StatusCode != http.StatusOK {
-			// TODO: deal with it
-			continue
+// remoteFile is a file to download, and where to get it.
+type remoteFile struct {
+	// key identifies the file in the staging area.
+	key     string
+	name    string
+	baseURL string
+	// checksum is the SHA-256 of the file, if the API provided one.
+	checksum string
+	// template files come with the track rather than the exercise.
+	template bool
+}
+
+// downloadedFile is a file of a solution, as fetched from the API.
+type downloadedFile struct {
+	// path is where the file goes, relative to the exercise, with forward slashes.
+	path string
+	// content is nil if the file is empty.
+	content []byte
+	// status is set if the API didn't return the file.
+	status string
+}
+
+// upstreamFiles picks the files that the exercise config in dir says are
+// tests or editor files. Those belong to the track rather than the user.
+func upstreamFiles(dir string, files []string, exerciseSlug string) ([]string, error) {
+	exerciseConfig, err := workspace.NewExerciseConfig(dir)
+	if os.IsNotExist(err) {
+		msg := `
+
+    There is no exercise config in
+
+        %s
+
+    so it's not known which of the files are tests. Download the exercise
+    without --update-tests instead.
+
+`
+		return nil, fmt.Errorf(msg, dir)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var picked []string
+	for _, file := range files {
+		rel := strings.TrimPrefix(downloadPath(file, exerciseSlug), "/")
+		if exerciseConfig.IsTest(rel) || exerciseConfig.IsEditor(rel) {
+			picked = append(picked, file)
 		}
-		// Don't bother with empty files.
-		if res.Header.Get("Content-Length") == "0" {
-			continue
+	}
+	if len(picked) == 0 {
+		return nil, fmt.Errorf("none of the files of %s are tests or editor files, according to %s", exerciseSlug, workspace.ExerciseConfigPath(dir))
+	}
+	return picked, nil
+}
+
+// downloadPath works out where a file of a solution goes, relative to the
+// exercise, with forward slashes.
+func downloadPath(file, exerciseSlug string) string {
+	// Work around a path bug due to an early design decision (later reversed) to
+	// allow numeric suffixes for exercise directories, allowing people to have
+	// multiple parallel versions of an exercise.
+	pattern := fmt.Sprintf(`\A.*[/\\]%s-\d*/`, exerciseSlug)
+	rgxNumericSuffix := regexp.MustCompile(pattern)
+	if rgxNumericSuffix.MatchString(file) {
+		file = string(rgxNumericSuffix.ReplaceAll([]byte(file), []byte("")))
+	}
+
+	// Rewrite paths submitted with an older, buggy client where the Windows path is being treated as part of the filename.
+	file = strings.Replace(file, "\\", "/", -1)
+
+	// The track ships the exercise config in .exercism, which goes in the
+	// metadata directory, whatever it's called.
+	for _, prefix := range []string{"/", ""} {
+		dir := prefix + workspace.DefaultMetadataDirName + "/"
+		if strings.HasPrefix(file, dir) {
+			return prefix + workspace.MetadataDirName + "/" + strings.TrimPrefix(file, dir)
 		}
+	}
+	return file
+}
+
+// fetchSolutionFile fetches one of the files of a solution.
+func fetchSolutionFile(client *api.Client, baseURL, file, exerciseSlug string) (downloadedFile, error) {
+	parsedURL, err := netURL.ParseRequestURI(fmt.Sprintf("%s%s", baseURL, file))
+	if err != nil {
+		return downloadedFile{}, err
+	}
 
-		// TODO: if there's a collision, interactively resolve (show diff, ask if overwrite).
-		// TODO: handle --force flag to overwrite without asking.
+	req, err := client.NewRequest("GET", parsedURL.String(), nil)
+	if err != nil {
+		return downloadedFile{}, err
+	}
+	res, err := client.Do(req)
+	if err != nil {
+		return downloadedFile{}, err
+	}
+	defer res.Body.Close()
 
-		// Work around a path bug due to an early design decision (later reversed) to
-		// allow numeric suffixes for exercise directories, allowing people to have
-		// multiple parallel versions of an exercise.
-		pattern := fmt.Sprintf(`\A.*[/\\]%s-\d*/`, solution.Exercise)
-		rgxNumericSuffix := regexp.MustCompile(pattern)
-		if rgxNumericSuffix.MatchString(file) {
-			file = string(rgxNumericSuffix.ReplaceAll([]byte(file), []byte("")))
+	if res.StatusCode != http.StatusOK {
+		return downloadedFile{status: res.Status}, nil
+	}
+
+	file = downloadPath(file, exerciseSlug)
+	if res.Header.Get("Content-Length") == "0" {
+		return downloadedFile{path: file}, nil
+	}
+	content, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return downloadedFile{}, err
+	}
+	if len(content) == 0 {
+		content = nil
+	}
+	return downloadedFile{path: file, content: content}, nil
+}
+
+// downloadAttempts is how many times a file that doesn't match its checksum
+// is fetched before giving up on it.
+const downloadAttempts = 3
+
+// fetchVerifiedFile fetches a file, and checks it against its checksum,
+// if there is one. A corrupted file is fetched again, a few times.
+func fetchVerifiedFile(client *api.Client, file remoteFile, exerciseSlug string) (downloadedFile, error) {
+	var err error
+	for attempt := 0; attempt < downloadAttempts; attempt++ {
+		var download downloadedFile
+		download, err = fetchSolutionFile(client, file.baseURL, file.name, exerciseSlug)
+		if err != nil || download.status != "" {
+			return download, err
+		}
+		if err = verifyChecksum(download.content, file.checksum); err == nil {
+			return download, nil
 		}
+	}
+	return downloadedFile{}, err
+}
 
-		// Rewrite paths submitted with an older, buggy client where the Windows path is being treated as part of the filename.
-		file = strings.Replace(file, "\\", "/", -1)
+// checksum is the hex encoded SHA-256 digest of content.
+func checksum(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// verifyChecksum checks content against the expected checksum.
+// The API may prefix it with the name of the algorithm.
+func verifyChecksum(content []byte, expected string) error {
+	expected = strings.ToLower(strings.TrimPrefix(expected, "sha256:"))
+	if expected == "" {
+		return nil
+	}
+	if actual := checksum(content); actual != expected {
+		return fmt.Errorf("checksum mismatch: expected %s, got %s", expected, actual)
+	}
+	return nil
+}
 
-		relativePath := filepath.FromSlash(file)
-		dir := filepath.Join(solution.Dir, filepath.Dir(relativePath))
-		os.MkdirAll(dir, os.FileMode(0755))
+const (
+	// conflictKeep leaves local changes alone.
+	conflictKeep = "keep"
+	// conflictOverwrite replaces local changes with the downloaded file.
+	conflictOverwrite = "overwrite"
+	// conflictMerge writes both versions into the file, separated by conflict markers.
+	conflictMerge = "merge"
+	// conflictBackup keeps a copy of local changes before replacing them.
+	conflictBackup = "backup"
+)
 
-		f, err := os.Create(filepath.Join(solution.Dir, relativePath))
-		if err != nil {
+// backupsDir is where local changes are backed up to, within the metadata
+// directory, in a directory named after the time of the download.
+const backupsDir = "backups"
+
+// writeDownloadedFile writes the downloaded content to path.
+// If there is already a file there with different contents,
+// the strategy determines which version wins. With backups,
+// the local version is copied to backupPath first.
+func writeDownloadedFile(path string, content []byte, strategy, backupPath string) error {
+	local, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) || strategy == conflictOverwrite {
+		return ioutil.WriteFile(path, content, os.FileMode(0644))
+	}
+	if err != nil {
+		return err
+	}
+	if bytes.Equal(local, content) {
+		return nil
+	}
+
+	if strategy == conflictBackup {
+		if err := os.MkdirAll(filepath.Dir(backupPath), os.FileMode(0755)); err != nil {
 			return err
 		}
-		defer f.Close()
-		_, err = io.Copy(f, res.Body)
-		if err != nil {
+		if err := ioutil.WriteFile(backupPath, local, os.FileMode(0644)); err != nil {
 			return err
 		}
+		msg := `
+
+    WARNING: Replaced your local changes to
+
+        %s
+
+    with the downloaded file. Your version has been backed up to
+
+        %s
+
+`
+		fmt.Fprintf(Err, msg, path, backupPath)
+		return ioutil.WriteFile(path, content, os.FileMode(0644))
 	}
-	fmt.Fprintf(Err, "\nDownloaded to\n")
-	fmt.Fprintf(Out, "%s\n", solution.Dir)
+
+	if strategy == conflictMerge {
+		msg := `
+
+    WARNING: Your local changes conflict with the downloaded file.
+             Both versions have been written to
+
+        %s
+
+    Please resolve the conflict markers before submitting.
+
+`
+		fmt.Fprintf(Err, msg, path)
+		return ioutil.WriteFile(path, mergeConflict(local, content), os.FileMode(0644))
+	}
+
+	msg := `
+
+    WARNING: Keeping your local changes to
+
+        %s
+
+    To replace them with the downloaded version, call the command again with --force,
+    or with --backup to keep a copy of your changes
+
+`
+	fmt.Fprintf(Err, msg, path)
 	return nil
 }
 
+// mergeConflict combines the local and downloaded versions of a file
+// using git-style conflict markers.
+func mergeConflict(local, downloaded []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("<<<<<<< local\n")
+	buf.Write(local)
+	if len(local) > 0 && local[len(local)-1] != '\n' {
+		buf.WriteString("\n")
+	}
+	buf.WriteString("=======\n")
+	buf.Write(downloaded)
+	if len(downloaded) > 0 && downloaded[len(downloaded)-1] != '\n' {
+		buf.WriteString("\n")
+	}
+	buf.WriteString(">>>>>>> downloaded\n")
+	return buf.Bytes()
+}
+
 type downloadPayload struct {
 	Solution struct {
-		ID   string `json:"id"`
-		URL  string `json:"url"`
-		Team struct {
+		ID     string `json:"id"`
+		URL    string `json:"url"`
+		Status string `json:"status"`
+		Team   struct {
 			Name string `json:"name"`
 			Slug string `json:"slug"`
 		} `json:"team"`
@@ -248,14 +1032,24 @@ type downloadPayload struct {
 			ID              string `json:"id"`
 			InstructionsURL string `json:"instructions_url"`
 			AutoApprove     bool   `json:"auto_approve"`
+			Difficulty      int    `json:"difficulty"`
+			Blurb           string `json:"blurb"`
 			Track           struct {
 				ID       string `json:"id"`
 				Language string `json:"language"`
+				// TemplateFiles are shared by the exercises of the track,
+				// such as .editorconfig or build files.
+				TemplateFiles            []string `json:"template_files"`
+				TemplatesDownloadBaseURL string   `json:"templates_download_base_url"`
+				// TemplateChecksums are the SHA-256 of the templates, by name.
+				TemplateChecksums map[string]string `json:"template_checksums"`
 			} `json:"track"`
 		} `json:"exercise"`
 		FileDownloadBaseURL string   `json:"file_download_base_url"`
 		Files               []string `json:"files"`
-		Iteration           struct {
+		// FileChecksums are the SHA-256 of the files, by name.
+		FileChecksums map[string]string `json:"file_checksums"`
+		Iteration     struct {
 			SubmittedAt *string `json:"submitted_at"`
 		}
 	} `json:"solution"`
@@ -271,6 +1065,20 @@ func setupDownloadFlags(flags *pflag.FlagSet) {
 	flags.StringP("track", "t", "", "the track ID")
 	flags.StringP("exercise", "e", "", "the exercise slug")
 	flags.StringP("team", "T", "", "the team slug")
+	flags.StringP("output", "o", "", "the directory to put the exercise in, instead of the workspace")
+	flags.IntP("workers", "", defaultDownloadWorkers, "how many files, or exercises with --all or solutions with --community, to download at the same time")
+	flags.BoolP("update-tests", "", false, "only download the test and editor files listed in the exercise config, replacing the local ones")
+	flags.BoolP("latest", "", false, "download the latest iteration of the exercise you're in")
+	flags.IntP("community", "", 0, "download up to this many published solutions to the exercise into its community directory")
+	flags.BoolP("mentor-notes", "", false, "write the discussion with your mentors about the exercise you're in to "+mentorNotesFilename)
+	flags.BoolP("repair", "", false, "write the metadata of an exercise that's already downloaded, when it's missing or broken, without touching its files")
+	flags.BoolP("all", "", false, "download all the exercises of the --track that are available to you")
+	flags.StringP("on-conflict", "", conflictKeep, "what to do with local files that differ from the download: keep, overwrite, merge, or backup")
+	flags.BoolP("force", "", false, "replace local files that differ from the download, same as --on-conflict=overwrite")
+	flags.BoolP("backup", "", false, "back up local files that differ from the download before replacing them, same as --on-conflict=backup")
+	flags.BoolP("no-instructions", "", false, "don't show the instructions of the exercise once it's downloaded")
+	flags.BoolP("no-hooks", "", false, "don't run the post-download command from the config")
+	flags.BoolP("no-cache", "", false, "fetch everything from the API, without using or filling the download cache")
 }
 
 func init() {
@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/cobra"
+)
+
+// downloadCacheDirName is where downloaded exercises are cached, within the
+// config dir, so that downloading them again works offline.
+const downloadCacheDirName = "download-cache"
+
+// downloadCacheIndexFilename maps the addresses that solutions were
+// requested from to their IDs.
+const downloadCacheIndexFilename = "index.json"
+
+// downloadCachePayloadFilename holds the API's response about a solution.
+const downloadCachePayloadFilename = "payload.json"
+
+// downloadCacheMu guards the index, as exercises can be downloaded concurrently.
+var downloadCacheMu sync.Mutex
+
+// cacheCmd manages the CLI's caches.
+var cacheCmd = &cobra.Command{
+	Use:   "cache",
+	Short: "Manage the cache of downloaded exercises.",
+	Long: `Manage the cache of downloaded exercises.
+
+Downloaded exercises are cached in the config directory, so that
+downloading one again works without a connection, and doesn't fetch
+its files again unless they changed.
+`,
+}
+
+var cacheClearCmd = &cobra.Command{
+	Use:   "clear",
+	Short: "Remove all cached downloads.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		return runCacheClear(cfg)
+	},
+}
+
+func runCacheClear(cfg config.Config) error {
+	if cfg.Dir == "" {
+		return errors.New("there is no download cache without a config dir, e.g. with --config -")
+	}
+	dir := filepath.Join(cfg.Dir, downloadCacheDirName)
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	fmt.Fprintf(Err, "Cleared the download cache in\n")
+	fmt.Fprintf(Out, "%s\n", dir)
+	return nil
+}
+
+// downloadCache keeps the downloaded solutions by ID, along with the ETag
+// the API sent with them.
+type downloadCache struct {
+	dir string
+}
+
+// cachedPayload is the API's response about a solution.
+type cachedPayload struct {
+	SolutionID string          `json:"solution_id"`
+	ETag       string          `json:"etag"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// lookup finds the solution that was last downloaded from url, if any.
+func (c downloadCache) lookup(url string) *cachedPayload {
+	downloadCacheMu.Lock()
+	id, ok := c.readIndex()[url]
+	downloadCacheMu.Unlock()
+	if !ok {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(c.dir, id, downloadCachePayloadFilename))
+	if err != nil {
+		return nil
+	}
+	var cached cachedPayload
+	if err := json.Unmarshal(b, &cached); err != nil || cached.ETag == "" {
+		return nil
+	}
+	return &cached
+}
+
+// store keeps the solution downloaded from url. The files of an earlier
+// version of the solution, with another ETag, are dropped.
+func (c downloadCache) store(url string, cached cachedPayload) error {
+	dir := filepath.Join(c.dir, cached.SolutionID)
+	b, err := ioutil.ReadFile(filepath.Join(dir, downloadCachePayloadFilename))
+	var previous cachedPayload
+	if err == nil && json.Unmarshal(b, &previous) == nil && previous.ETag != cached.ETag {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	if b, err = json.Marshal(cached); err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(filepath.Join(dir, downloadCachePayloadFilename), b, os.FileMode(0644)); err != nil {
+		return err
+	}
+
+	downloadCacheMu.Lock()
+	defer downloadCacheMu.Unlock()
+	index := c.readIndex()
+	index[url] = cached.SolutionID
+	if b, err = json.Marshal(index); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(c.dir, downloadCacheIndexFilename), b, os.FileMode(0644))
+}
+
+// files holds the cached files of a solution, laid out like a staging area.
+func (c downloadCache) files(solutionID string) *stagingArea {
+	return openStagingArea(filepath.Join(c.dir, solutionID))
+}
+
+func (c downloadCache) readIndex() map[string]string {
+	index := map[string]string{}
+	b, err := ioutil.ReadFile(filepath.Join(c.dir, downloadCacheIndexFilename))
+	if err != nil {
+		return index
+	}
+	if err := json.Unmarshal(b, &index); err != nil {
+		return map[string]string{}
+	}
+	return index
+}
+
+func init() {
+	RootCmd.AddCommand(cacheCmd)
+	cacheCmd.AddCommand(cacheClearCmd)
+}
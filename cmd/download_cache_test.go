@@ -0,0 +1,102 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadCache(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var mu sync.Mutex
+	fetches := 0
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == `"v1"` {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprintf(w, `{"solution": {"id": "bogus-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "bogus-exercise", "track": {"id": "bogus-track"}}, "file_download_base_url": "%s/files/", "files": ["file-1.txt"]}}`, ts.URL)
+	})
+	mux.HandleFunc("/files/file-1.txt", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		fetches++
+		mu.Unlock()
+		fmt.Fprint(w, "this is file 1")
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-cache")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", filepath.Join(tmpDir, "workspace"))
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		Dir:             filepath.Join(tmpDir, "config"),
+		UserViperConfig: v,
+	}
+	dir := filepath.Join(tmpDir, "workspace", "bogus-track", "bogus-exercise")
+	download := func(args ...string) error {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		flags.Set("exercise", "bogus-exercise")
+		for _, flag := range args {
+			flags.Set(flag, "true")
+		}
+		os.RemoveAll(dir)
+		return runDownload(cfg, flags, []string{})
+	}
+	assertDownloaded := func() {
+		b, err := ioutil.ReadFile(filepath.Join(dir, "file-1.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "this is file 1", string(b))
+	}
+
+	Err = ioutil.Discard
+	assert.NoError(t, download())
+	assertDownloaded()
+	assert.Equal(t, 1, fetches)
+
+	// The solution is unchanged, so the files come from the cache.
+	assert.NoError(t, download())
+	assertDownloaded()
+	assert.Equal(t, 1, fetches)
+
+	ts.Close()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+	assert.NoError(t, download())
+	assertDownloaded()
+	assert.Regexp(t, "Unable to reach the API", errBuf.String())
+
+	assert.Error(t, download("no-cache"))
+
+	Err = ioutil.Discard
+	assert.NoError(t, runCacheClear(cfg))
+	_, err = os.Stat(filepath.Join(cfg.Dir, downloadCacheDirName))
+	assert.True(t, os.IsNotExist(err))
+	assert.Error(t, download())
+}
@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+
+	"github.com/exercism/cli/api"
+	"github.com/spf13/viper"
+)
+
+// communityDirName is where published solutions go, within the exercise.
+const communityDirName = "community"
+
+// downloadCommunity downloads up to n published solutions to the exercise,
+// each into a directory named after its author.
+func downloadCommunity(client *api.Client, usrCfg *viper.Viper, exercise downloadParams, n int, opts downloadOptions) error {
+	solutions, err := client.CommunitySolutions(exercise.track, exercise.exercise, n)
+	if err != nil {
+		return fmt.Errorf("unable to list the published solutions to %s - %s", exercise.exercise, err)
+	}
+	if len(solutions) == 0 {
+		fmt.Fprintf(Err, "\nNo one has published a solution to %s yet.\n", exercise.exercise)
+		return nil
+	}
+
+	// As with --all, the solutions are what's downloaded several at a time.
+	solutionOpts := opts
+	solutionOpts.workers = 1
+
+	var mu sync.Mutex
+	var failed []string
+	forEachConcurrently(len(solutions), opts.workers, func(i int) error {
+		author := solutions[i].User.Handle
+		if author == "" {
+			author = solutions[i].ID
+		}
+		params := downloadParams{
+			uuid: solutions[i].ID,
+			dir:  filepath.Join(exercise.dir, communityDirName, author),
+		}
+		solution, err := downloadExercise(client, usrCfg, params, solutionOpts)
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			failed = append(failed, author)
+			fmt.Fprintf(Err, "Unable to download the solution by %s - %s\n", author, err)
+			return nil
+		}
+		fmt.Fprintf(Out, "%s\n", solution.Dir)
+		return nil
+	})
+
+	fmt.Fprintf(Err, "\nDownloaded %d of %d published solutions to %s.\n", len(solutions)-len(failed), len(solutions), exercise.exercise)
+	if len(failed) > 0 {
+		return fmt.Errorf("unable to download the solutions by %d of the authors", len(failed))
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadCommunity(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/tracks/bogus-track/exercises/bogus-exercise/community_solutions", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.FormValue("limit"))
+		fmt.Fprint(w, `{"solutions": [{"id": "alice-id", "user": {"handle": "alice"}}, {"id": "bob-id", "user": {"handle": "bob"}}]}`)
+	})
+	mux.HandleFunc("/solutions/", func(w http.ResponseWriter, r *http.Request) {
+		id := filepath.Base(r.URL.Path)
+		handle := strings.TrimSuffix(id, "-id")
+		fmt.Fprintf(w, `{"solution": {"id": "%s", "user": {"handle": "%s", "is_requester": false}, "exercise": {"id": "bogus-exercise", "track": {"id": "bogus-track"}}, "file_download_base_url": "%s/files/%s/", "files": ["bogus.go"]}}`, id, handle, ts.URL, handle)
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "solved by %s", filepath.Base(filepath.Dir(r.URL.Path)))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-community")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+	flags.Set("track", "bogus-track")
+	flags.Set("community", "2")
+
+	var out bytes.Buffer
+	Out = &out
+	err = runDownload(cfg, flags, []string{})
+	assert.NoError(t, err)
+
+	community := filepath.Join(tmpDir, "bogus-track", "bogus-exercise", "community")
+	dirs := strings.Fields(out.String())
+	sort.Strings(dirs)
+	assert.Equal(t, []string{filepath.Join(community, "alice"), filepath.Join(community, "bob")}, dirs)
+	for _, handle := range []string{"alice", "bob"} {
+		b, err := ioutil.ReadFile(filepath.Join(community, handle, "bogus.go"))
+		assert.NoError(t, err)
+		assert.Equal(t, "solved by "+handle, string(b))
+	}
+	// They don't end up among the other people's solutions in the workspace.
+	_, err = os.Stat(filepath.Join(tmpDir, "users"))
+	assert.True(t, os.IsNotExist(err))
+
+	flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+	flags.Set("community", "2")
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "--community needs the --track")
+	}
+}
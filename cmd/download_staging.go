@@ -0,0 +1,192 @@
+package cmd
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/exercism/cli/workspace"
+)
+
+// stagingDirName is where downloads are put together, within the metadata
+// directory at the root of the workspace, before they're moved into place.
+const stagingDirName = "downloads"
+
+// stagingManifestFilename lists the files that have been fetched so far,
+// so that an interrupted download can pick up where it stopped.
+const stagingManifestFilename = "staged.json"
+
+// stagedFile is a file of a solution that has been fetched.
+type stagedFile struct {
+	// Path is where the file goes, relative to the exercise, with forward slashes.
+	Path string `json:"path"`
+	// Empty files are recorded, but not written.
+	Empty bool `json:"empty,omitempty"`
+	// Template files come with the track, and don't replace local copies.
+	Template bool `json:"template,omitempty"`
+	// Checksum is the SHA-256 of the file, to tell if it got corrupted
+	// while it waited for the rest of the download.
+	Checksum string `json:"checksum,omitempty"`
+}
+
+// stagingArea holds the files of a download until all of them have arrived.
+type stagingArea struct {
+	dir   string
+	mu    sync.Mutex
+	files map[string]stagedFile
+}
+
+// openStagingArea picks up the files fetched into dir by an earlier attempt,
+// if there was one.
+func openStagingArea(dir string) *stagingArea {
+	s := &stagingArea{dir: dir, files: map[string]stagedFile{}}
+	b, err := ioutil.ReadFile(filepath.Join(dir, stagingManifestFilename))
+	if err != nil {
+		return s
+	}
+	if err := json.Unmarshal(b, &s.files); err != nil {
+		s.files = map[string]stagedFile{}
+	}
+	return s
+}
+
+// filesDir is where the staged files are laid out as in the exercise.
+func (s *stagingArea) filesDir() string {
+	return filepath.Join(s.dir, "files")
+}
+
+// has tells if a file has been fetched already, and is still intact.
+func (s *stagingArea) has(file string) bool {
+	s.mu.Lock()
+	staged, ok := s.files[file]
+	s.mu.Unlock()
+	if !ok || staged.Empty || staged.Checksum == "" {
+		return ok
+	}
+	content, err := ioutil.ReadFile(filepath.Join(s.filesDir(), filepath.FromSlash(staged.Path)))
+	return err == nil && verifyChecksum(content, staged.Checksum) == nil
+}
+
+// get reads back a file that has been fetched, if it's still intact.
+func (s *stagingArea) get(file string) (downloadedFile, bool) {
+	if !s.has(file) {
+		return downloadedFile{}, false
+	}
+	s.mu.Lock()
+	staged := s.files[file]
+	s.mu.Unlock()
+	download := downloadedFile{path: staged.Path}
+	if staged.Empty {
+		return download, true
+	}
+	content, err := ioutil.ReadFile(filepath.Join(s.filesDir(), filepath.FromSlash(staged.Path)))
+	if err != nil {
+		return downloadedFile{}, false
+	}
+	download.content = content
+	return download, true
+}
+
+// add stores a fetched file, and records that it no longer needs fetching.
+func (s *stagingArea) add(file string, download downloadedFile, template bool) error {
+	staged := stagedFile{Path: download.path, Empty: download.content == nil, Template: template}
+	if !staged.Empty {
+		staged.Checksum = checksum(download.content)
+		path := filepath.Join(s.filesDir(), filepath.FromSlash(staged.Path))
+		if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, download.content, os.FileMode(0644)); err != nil {
+			return err
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[file] = staged
+	b, err := json.Marshal(s.files)
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(filepath.Join(s.dir, stagingManifestFilename), b, os.FileMode(0644))
+}
+
+// exerciseConfig reads the exercise config that came with the download,
+// or else the one that's already in dir, if there is one.
+func (s *stagingArea) exerciseConfig(dir string) *workspace.ExerciseConfig {
+	if c, err := workspace.NewExerciseConfig(s.filesDir()); err == nil {
+		return c
+	}
+	if c, err := workspace.NewExerciseConfig(dir); err == nil {
+		return c
+	}
+	return nil
+}
+
+// install puts the staged files into dir. A new exercise directory is moved
+// into place in one go. Otherwise the files are written one by one, in the
+// given order, and the strategy for each file resolves conflicts with local
+// changes. Template files are only written if there's no local copy.
+func (s *stagingArea) install(dir string, order []string, strategyFor func(rel string) string, backupDir string) error {
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := os.MkdirAll(filepath.Dir(dir), os.FileMode(0755)); err != nil {
+			return err
+		}
+		if _, err := os.Stat(s.filesDir()); os.IsNotExist(err) {
+			return os.MkdirAll(dir, os.FileMode(0755))
+		}
+		if err := os.Rename(s.filesDir(), dir); err == nil {
+			return nil
+		}
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+
+	for _, file := range order {
+		staged, ok := s.files[file]
+		// Don't bother with empty files.
+		if !ok || staged.Empty {
+			continue
+		}
+		relativePath := filepath.FromSlash(staged.Path)
+		path := filepath.Join(dir, relativePath)
+		if staged.Template {
+			if _, err := os.Lstat(path); err == nil {
+				continue
+			}
+		}
+		content, err := ioutil.ReadFile(filepath.Join(s.filesDir(), relativePath))
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+			return err
+		}
+		strategy := strategyFor(strings.TrimPrefix(staged.Path, "/"))
+		if err := writeDownloadedFile(path, content, strategy, filepath.Join(backupDir, relativePath)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// remove cleans up after a download has been installed.
+func (s *stagingArea) remove() error {
+	return os.RemoveAll(s.dir)
+}
+
+// removeEmptyStagingDirs cleans up the directories that hold the staging
+// areas in the workspace root, unless there are downloads left in them.
+// Other downloads may need them while they're running, so it's only safe
+// once they've all finished.
+func removeEmptyStagingDirs(root string) {
+	dir := filepath.Join(root, workspace.MetadataDirName, stagingDirName)
+	// Removing a directory fails while there's anything left in it.
+	if os.Remove(dir) == nil {
+		os.Remove(filepath.Dir(dir))
+	}
+}
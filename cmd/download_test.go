@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -8,7 +9,10 @@ import (
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"sort"
 	"strconv"
+	"strings"
+	"sync"
 	"testing"
 
 	"github.com/exercism/cli/config"
@@ -154,6 +158,1106 @@ func TestDownload(t *testing.T) {
 	}
 }
 
+func TestDownloadTeamErrors(t *testing.T) {
+	ts := fakeDownloadServer("true", "bogus-team")
+	defer ts.Close()
+
+	v := viper.New()
+	v.Set("workspace", "/tmp")
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+
+	testCases := []struct {
+		team string
+		err  string
+	}{
+		{team: "Bogus Team", err: "invalid team slug"},
+		{team: "other-team", err: "Either the team doesn't exist, or you are not a member of it"},
+	}
+	for _, tc := range testCases {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		flags.Set("exercise", "bogus-exercise")
+		flags.Set("track", "bogus-track")
+		flags.Set("team", tc.team)
+
+		err := runDownload(cfg, flags, []string{})
+		if assert.Error(t, err, tc.team) {
+			assert.Contains(t, err.Error(), tc.err)
+		}
+	}
+}
+
+func TestDownloadOnConflict(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	testCases := []struct {
+		strategy string
+		expected string
+	}{
+		{
+			strategy: "keep",
+			expected: "local changes",
+		},
+		{
+			strategy: "overwrite",
+			expected: "this is file 1",
+		},
+		{
+			strategy: "merge",
+			expected: "<<<<<<< local\nlocal changes\n=======\nthis is file 1\n>>>>>>> downloaded\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.strategy, func(t *testing.T) {
+			tmpDir, err := ioutil.TempDir("", "download-conflict")
+			defer os.RemoveAll(tmpDir)
+			assert.NoError(t, err)
+
+			ts := fakeDownloadServer("true", "")
+			defer ts.Close()
+
+			dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+			err = os.MkdirAll(dir, os.FileMode(0755))
+			assert.NoError(t, err)
+
+			path := filepath.Join(dir, "file-1.txt")
+			err = ioutil.WriteFile(path, []byte("local changes"), os.FileMode(0644))
+			assert.NoError(t, err)
+
+			v := viper.New()
+			v.Set("workspace", tmpDir)
+			v.Set("apibaseurl", ts.URL)
+			v.Set("token", "abc123")
+
+			cfg := config.Config{
+				UserViperConfig: v,
+			}
+			flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+			setupDownloadFlags(flags)
+			flags.Set("exercise", "bogus-exercise")
+			flags.Set("on-conflict", tc.strategy)
+
+			err = runDownload(cfg, flags, []string{})
+			assert.NoError(t, err)
+
+			b, err := ioutil.ReadFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, string(b))
+
+			// Files without local changes are written regardless.
+			b, err = ioutil.ReadFile(filepath.Join(dir, "subdir", "file-2.txt"))
+			assert.NoError(t, err)
+			assert.Equal(t, "this is file 2", string(b))
+		})
+	}
+}
+
+func TestDownloadBackup(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	tmpDir, err := ioutil.TempDir("", "download-backup")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	ts := fakeDownloadServer("true", "")
+	defer ts.Close()
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	err = os.MkdirAll(filepath.Join(dir, "subdir"), os.FileMode(0755))
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "file-1.txt"), []byte("local changes"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, "subdir", "file-2.txt"), []byte("this is file 2"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+	flags.Set("backup", "true")
+
+	var errBuf bytes.Buffer
+	Err = &errBuf
+	err = runDownload(cfg, flags, []string{})
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "file-1.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "this is file 1", string(b))
+
+	// Only the file with local changes is backed up.
+	backups, err := filepath.Glob(filepath.Join(dir, ".exercism", "backups", "*", "*"))
+	assert.NoError(t, err)
+	if assert.Len(t, backups, 1) {
+		assert.Equal(t, "file-1.txt", filepath.Base(backups[0]))
+		b, err = ioutil.ReadFile(backups[0])
+		assert.NoError(t, err)
+		assert.Equal(t, "local changes", string(b))
+		assert.Contains(t, errBuf.String(), backups[0])
+	}
+
+	testCases := []map[string]string{
+		{"force": "true", "backup": "true"},
+		{"force": "true", "on-conflict": "keep"},
+		{"backup": "true", "on-conflict": "merge"},
+	}
+	for _, tc := range testCases {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		flags.Set("exercise", "bogus-exercise")
+		for name, value := range tc {
+			flags.Set(name, value)
+		}
+		err = runDownload(cfg, flags, []string{})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "use only one of --on-conflict, --force, and --backup")
+		}
+	}
+}
+
+func TestDownloadUpdateTests(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var requested []string
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"solution": {"id": "bogus-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "bogus-exercise", "track": {"id": "bogus-track"}}, "file_download_base_url": "%s/files/", "files": ["bogus.c", "test/test_bogus.c", "include/bogus.h", "README.md"]}}`, ts.URL)
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, strings.TrimPrefix(r.URL.Path, "/files/"))
+		fmt.Fprint(w, "upstream "+filepath.Base(r.URL.Path))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-update-tests")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+	flags.Set("update-tests", "true")
+
+	// Without an exercise config, the tests can't be told apart.
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "There is no exercise config")
+	}
+
+	for name, content := range map[string]string{
+		"bogus.c":               "my solution",
+		"test/test_bogus.c":     "old tests",
+		".exercism/config.json": `{"files": {"solution": ["bogus.c"], "test": ["test/*.c"], "editor": ["include/*.h"]}}`,
+	} {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.FileMode(0755)))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(content), os.FileMode(0644)))
+	}
+
+	requested = nil
+	err = runDownload(cfg, flags, []string{})
+	assert.NoError(t, err)
+	sort.Strings(requested)
+	assert.Equal(t, []string{"include/bogus.h", "test/test_bogus.c"}, requested)
+
+	for name, expected := range map[string]string{
+		"bogus.c":           "my solution",
+		"test/test_bogus.c": "upstream test_bogus.c",
+		"include/bogus.h":   "upstream bogus.h",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(b), name)
+	}
+	_, err = os.Stat(filepath.Join(dir, "README.md"))
+	assert.True(t, os.IsNotExist(err))
+
+	flags.Set("force", "true")
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can't be combined")
+	}
+}
+
+func TestDownloadLatest(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var requested []string
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/solutions/", func(w http.ResponseWriter, r *http.Request) {
+		requested = append(requested, r.URL.Path)
+		fmt.Fprintf(w, `{"solution": {"id": "bogus-solution-uuid", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "bogus-exercise", "track": {"id": "bogus-track"}}, "file_download_base_url": "%s/files/", "files": ["bogus.go", "lib/helper.go"]}}`, ts.URL)
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "submitted "+filepath.Base(r.URL.Path))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-latest")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+	tmpDir, err = filepath.EvalSymlinks(tmpDir)
+	assert.NoError(t, err)
+
+	// The exercise is kept outside of the workspace.
+	dir := filepath.Join(tmpDir, "elsewhere", "bogus-exercise")
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "lib"), os.FileMode(0755)))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+	err = ioutil.WriteFile(filepath.Join(dir, "bogus.go"), []byte("local changes"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", filepath.Join(tmpDir, "workspace"))
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+	assert.NoError(t, os.Chdir(filepath.Join(dir, "lib")))
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("latest", "true")
+	err = runDownload(cfg, flags, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/solutions/bogus-solution-uuid"}, requested)
+
+	// Local changes are kept, and missing files restored.
+	for name, expected := range map[string]string{
+		"bogus.go":      "local changes",
+		"lib/helper.go": "submitted helper.go",
+	} {
+		b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		assert.NoError(t, err)
+		assert.Equal(t, expected, string(b), name)
+	}
+	_, err = os.Stat(filepath.Join(tmpDir, "workspace", "bogus-track"))
+	assert.True(t, os.IsNotExist(err))
+
+	flags.Set("exercise", "bogus-exercise")
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can't be combined")
+	}
+
+	assert.NoError(t, os.Chdir(tmpDir))
+	flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("latest", "true")
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "Call the command with --latest from within an exercise directory")
+	}
+}
+
+func TestDownloadTemplatesAndEditorFiles(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"solution": {"id": "bogus-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "bogus-exercise", "track": {"id": "bogus-track", "template_files": [".editorconfig", "Makefile"], "templates_download_base_url": "%[1]s/templates/"}}, "file_download_base_url": "%[1]s/files/", "files": ["bogus.c", "include/bogus.h", "Makefile", ".exercism/config.json"]}}`, ts.URL)
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/files/.exercism/config.json" {
+			fmt.Fprint(w, `{"files": {"solution": ["bogus.c"], "editor": ["include/*.h"]}}`)
+			return
+		}
+		fmt.Fprint(w, "exercise "+strings.TrimPrefix(r.URL.Path, "/files/"))
+	})
+	mux.HandleFunc("/templates/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "template "+strings.TrimPrefix(r.URL.Path, "/templates/"))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-templates")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	download := func() {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		flags.Set("exercise", "bogus-exercise")
+		assert.NoError(t, runDownload(cfg, flags, []string{}))
+	}
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	assertFiles := func(expected map[string]string) {
+		for name, content := range expected {
+			b, err := ioutil.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			assert.NoError(t, err)
+			assert.Equal(t, content, string(b), name)
+		}
+	}
+
+	// The exercise's own files take precedence over the track's templates.
+	download()
+	assertFiles(map[string]string{
+		"bogus.c":         "exercise bogus.c",
+		"include/bogus.h": "exercise include/bogus.h",
+		"Makefile":        "exercise Makefile",
+		".editorconfig":   "template .editorconfig",
+	})
+
+	for _, name := range []string{"bogus.c", "include/bogus.h", ".editorconfig"} {
+		err = ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte("local changes"), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	// Editor files are kept up to date, but local templates and solutions are left alone.
+	download()
+	assertFiles(map[string]string{
+		"bogus.c":         "local changes",
+		"include/bogus.h": "exercise include/bogus.h",
+		".editorconfig":   "local changes",
+	})
+}
+
+func TestDownloadWithCustomMetadataDir(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	defer workspace.SetMetadataDirName("")
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"solution": {"id": "bogus-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "bogus-exercise", "track": {"id": "bogus-track"}}, "file_download_base_url": "%s/files/", "files": ["bogus.c", "include/bogus.h", ".exercism/config.json"]}}`, ts.URL)
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/files/.exercism/config.json" {
+			fmt.Fprint(w, `{"files": {"solution": ["bogus.c"], "editor": ["include/*.h"]}}`)
+			return
+		}
+		fmt.Fprint(w, "exercise "+strings.TrimPrefix(r.URL.Path, "/files/"))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-metadata-dir")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+	v.Set("metadatadir", ".exercism-cli")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	assert.NoError(t, applyUserSettings(cfg))
+	download := func() {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		flags.Set("exercise", "bogus-exercise")
+		flags.Set("backup", "true")
+		assert.NoError(t, runDownload(cfg, flags, []string{}))
+	}
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+
+	// The exercise config goes in the configured metadata directory.
+	download()
+	c, err := workspace.NewExerciseConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bogus.c"}, c.Files.Solution)
+	assert.Equal(t, filepath.Join(dir, ".exercism-cli", "config.json"), workspace.ExerciseConfigPath(dir))
+	_, err = os.Stat(filepath.Join(dir, ".exercism"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Nothing is left of the staging area, in either directory.
+	for _, name := range []string{".exercism", ".exercism-cli"} {
+		_, err = os.Stat(filepath.Join(tmpDir, name))
+		assert.True(t, os.IsNotExist(err), name)
+	}
+
+	// The editor file is recognized by the exercise config, and overwritten,
+	// while the local changes to the solution are backed up.
+	for _, name := range []string{"bogus.c", "include/bogus.h"} {
+		err = ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte("local changes"), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+	download()
+	b, err := ioutil.ReadFile(filepath.Join(dir, "include", "bogus.h"))
+	assert.NoError(t, err)
+	assert.Equal(t, "exercise include/bogus.h", string(b))
+	backups, err := filepath.Glob(filepath.Join(dir, ".exercism-cli", "backups", "*", "bogus.c"))
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+
+	v.Set("metadatadir", "../elsewhere")
+	err = applyUserSettings(cfg)
+	if assert.Error(t, err) {
+		assert.Regexp(t, "invalid metadata directory name", err.Error())
+	}
+}
+
+func TestDownloadInvalidConflictStrategy(t *testing.T) {
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", "/home/username")
+	v.Set("apibaseurl", "http://example.com")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+	flags.Set("on-conflict", "bogus")
+
+	err := runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Regexp(t, "invalid --on-conflict strategy", err.Error())
+	}
+}
+
+func TestDownloadReportsMissingFiles(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	mux.HandleFunc("/file-1.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "this is file 1")
+	})
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		payloadBody := fmt.Sprintf(payloadTemplate, "true", "null", ts.URL+"/")
+		fmt.Fprint(w, payloadBody)
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-missing")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+
+	var errBuf bytes.Buffer
+	Err = &errBuf
+	err = runDownload(cfg, flags, []string{})
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "bogus-track", "bogus-exercise", "file-1.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "this is file 1", string(b))
+	assert.Regexp(t, `Unable to download subdir/file-2.txt\s+API returned 404 Not Found`, errBuf.String())
+	assert.NotContains(t, errBuf.String(), "file-1.txt")
+}
+
+func TestDownloadVerifiesChecksums(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var mu sync.Mutex
+	fetches := map[string]int{}
+	// flaky.txt arrives corrupted the first time, broken.txt every time.
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"solution": {"id": "bogus-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "bogus-exercise", "track": {"id": "bogus-track"}}, "file_download_base_url": "%s/files/", "files": ["flaky.txt", "broken.txt"], "file_checksums": {"flaky.txt": "sha256:%s", "broken.txt": "%s"}}}`, ts.URL, checksum([]byte("flaky")), checksum([]byte("broken")))
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		name := strings.TrimPrefix(r.URL.Path, "/files/")
+		mu.Lock()
+		fetches[name]++
+		n := fetches[name]
+		mu.Unlock()
+		content := strings.TrimSuffix(name, ".txt")
+		if name == "broken.txt" || n == 1 {
+			content = "corrupted"
+		}
+		fmt.Fprint(w, content)
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-checksums")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Regexp(t, "broken.txt - checksum mismatch", err.Error())
+		assert.NotContains(t, err.Error(), "flaky.txt")
+	}
+	assert.Equal(t, 2, fetches["flaky.txt"])
+	assert.Equal(t, downloadAttempts, fetches["broken.txt"])
+	_, err = os.Stat(filepath.Join(tmpDir, "bogus-track", "bogus-exercise"))
+	assert.True(t, os.IsNotExist(err))
+
+	// A staged file that got corrupted in the meantime is fetched again.
+	staged := filepath.Join(tmpDir, workspace.MetadataDirName, stagingDirName, "bogus-id", "files", "flaky.txt")
+	assert.NoError(t, ioutil.WriteFile(staged, []byte("tampered"), os.FileMode(0644)))
+	err = runDownload(cfg, flags, []string{})
+	assert.Error(t, err)
+	assert.Equal(t, 3, fetches["flaky.txt"])
+	b, err := ioutil.ReadFile(staged)
+	assert.NoError(t, err)
+	assert.Equal(t, "flaky", string(b))
+}
+
+func TestDownloadPostDownloadHook(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/file-1.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "this is file 1")
+	})
+	mux.HandleFunc("/subdir/file-2.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "this is file 2")
+	})
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, fmt.Sprintf(payloadTemplate, "true", "null", ts.URL+"/"))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-hook")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	setHook := func(command string) {
+		v.Set("hooks.postdownload", []interface{}{
+			map[string]interface{}{"track": "bogus-track", "command": command},
+		})
+	}
+	download := func(noHooks bool) error {
+		os.Remove(filepath.Join(dir, "hook-ran.txt"))
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		flags.Set("exercise", "bogus-exercise")
+		if noHooks {
+			flags.Set("no-hooks", "true")
+		}
+		return runDownload(cfg, flags, []string{})
+	}
+
+	// The hook runs in the exercise directory.
+	setHook("echo ready > hook-ran.txt")
+	assert.NoError(t, download(false))
+	_, err = os.Stat(filepath.Join(dir, "hook-ran.txt"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, download(true))
+	_, err = os.Stat(filepath.Join(dir, "hook-ran.txt"))
+	assert.True(t, os.IsNotExist(err))
+
+	// The exercise is still downloaded when it fails.
+	setHook("echo something is missing && exit 3")
+	assert.NoError(t, download(false))
+	assert.Regexp(t, "something is missing", errBuf.String())
+	assert.Regexp(t, "The post-download command failed", errBuf.String())
+}
+
+func TestDownloadToOutputDir(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/file-1.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "this is file 1")
+	})
+	mux.HandleFunc("/subdir/file-2.txt", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "this is file 2")
+	})
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, fmt.Sprintf(payloadTemplate, "true", "null", ts.URL+"/"))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-output")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", filepath.Join(tmpDir, "workspace"))
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+	flags.Set("output", filepath.Join(tmpDir, "repo"))
+
+	err = runDownload(cfg, flags, []string{})
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "repo", "bogus-exercise")
+	b, err := ioutil.ReadFile(filepath.Join(dir, "subdir", "file-2.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "this is file 2", string(b))
+	_, err = os.Stat(filepath.Join(tmpDir, "workspace", "bogus-track"))
+	assert.True(t, os.IsNotExist(err))
+
+	// The exercise can be found by its metadata.
+	loc, err := workspace.Discover(filepath.Join(dir, "subdir"))
+	assert.NoError(t, err)
+	assert.Equal(t, dir, loc.Dir)
+	assert.Equal(t, "bogus-track", loc.Solution.Track)
+	assert.Equal(t, "bogus-exercise", loc.Solution.Exercise)
+
+	flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("latest", "true")
+	flags.Set("output", filepath.Join(tmpDir, "repo"))
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Regexp(t, "--output can't be combined", err.Error())
+	}
+}
+
+func TestDownloadRepair(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	fetched := false
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/file-1.txt", func(w http.ResponseWriter, r *http.Request) {
+		fetched = true
+	})
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bogus-track", r.URL.Query().Get("track_id"))
+		assert.Equal(t, "bogus-exercise", r.URL.Query().Get("exercise_id"))
+		fmt.Fprint(w, fmt.Sprintf(payloadTemplate, "true", "null", ts.URL+"/"))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-repair")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	repair := func(args ...string) error {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		flags.Set("repair", "true")
+		for i := 0; i < len(args); i += 2 {
+			flags.Set(args[i], args[i+1])
+		}
+		return runDownload(cfg, flags, []string{})
+	}
+
+	err = repair("exercise", "bogus-exercise", "track", "bogus-track")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "no exercise to repair", err.Error())
+	}
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	err = ioutil.WriteFile(filepath.Join(dir, "file-1.txt"), []byte("my solution"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, ".solution.json"), []byte("{broken"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	assert.NoError(t, repair("exercise", "bogus-exercise", "track", "bogus-track"))
+	solution, err := workspace.NewSolution(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "bogus-track", solution.Track)
+	assert.Equal(t, "bogus-exercise", solution.Exercise)
+	b, err := ioutil.ReadFile(filepath.Join(dir, "file-1.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "my solution", string(b))
+	assert.False(t, fetched)
+
+	// From within the exercise, it goes by where the directory is.
+	assert.NoError(t, os.Remove(filepath.Join(dir, ".solution.json")))
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+	assert.NoError(t, os.Chdir(dir))
+	assert.NoError(t, repair())
+	_, err = workspace.NewSolution(dir)
+	assert.NoError(t, err)
+
+	// From a directory within the exercise, it goes up to the exercise.
+	nested := filepath.Join(dir, "src", "lib")
+	assert.NoError(t, os.MkdirAll(nested, os.FileMode(0755)))
+	assert.NoError(t, os.Chdir(nested))
+	err = ioutil.WriteFile(filepath.Join(dir, ".solution.json"), []byte("{broken"), os.FileMode(0644))
+	assert.NoError(t, err)
+	assert.NoError(t, repair())
+	_, err = workspace.NewSolution(dir)
+	assert.NoError(t, err)
+	assert.NoError(t, os.Remove(filepath.Join(dir, ".solution.json")))
+	assert.NoError(t, repair())
+	_, err = workspace.NewSolution(dir)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(nested, ".solution.json"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Above the exercises, there's nothing to go by.
+	assert.NoError(t, os.Chdir(filepath.Join(tmpDir, "bogus-track")))
+	err = repair()
+	if assert.Error(t, err) {
+		assert.Regexp(t, "from within the exercise's directory", err.Error())
+	}
+
+	err = repair("exercise", "bogus-exercise", "track", "bogus-track", "force", "true")
+	assert.Error(t, err)
+}
+
+func TestParseExerciseURL(t *testing.T) {
+	testCases := []struct {
+		url      string
+		track    string
+		exercise string
+		uuid     string
+		err      string
+	}{
+		{
+			url:      "https://exercism.org/tracks/go/exercises/hamming",
+			track:    "go",
+			exercise: "hamming",
+		},
+		{
+			url:      "https://exercism.io/tracks/go/exercises/hamming/solutions?page=2",
+			track:    "go",
+			exercise: "hamming",
+		},
+		{
+			url:  "https://exercism.org/mentor/solutions/a1b2c3",
+			uuid: "a1b2c3",
+		},
+		{
+			url:  "http://localhost:3000/my/solutions/a1b2c3/",
+			uuid: "a1b2c3",
+		},
+		{
+			url: "https://exercism.org/tracks/go",
+			err: "not the address of an exercise or a solution",
+		},
+		{
+			url: "hamming",
+			err: "not the address of an exercise",
+		},
+	}
+
+	for _, tc := range testCases {
+		track, exercise, uuid, err := parseExerciseURL(tc.url)
+		if tc.err != "" {
+			if assert.Error(t, err, tc.url) {
+				assert.Contains(t, err.Error(), tc.err, tc.url)
+			}
+			continue
+		}
+		assert.NoError(t, err, tc.url)
+		assert.Equal(t, tc.track, track, tc.url)
+		assert.Equal(t, tc.exercise, exercise, tc.url)
+		assert.Equal(t, tc.uuid, uuid, tc.url)
+	}
+}
+
+func TestDownloadByURL(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var requests []string
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/solutions/", func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RequestURI())
+		payloadBody := fmt.Sprintf(payloadTemplate, "true", "null", ts.URL+"/")
+		fmt.Fprint(w, payloadBody)
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-url")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+
+	for _, url := range []string{
+		"https://exercism.org/tracks/bogus-track/exercises/bogus-exercise",
+		"https://exercism.org/mentor/solutions/bogus-id",
+	} {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		err = runDownload(cfg, flags, []string{url})
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, []string{
+		"/solutions/latest?exercise_id=bogus-exercise&track_id=bogus-track",
+		"/solutions/bogus-id",
+	}, requests)
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+	err = runDownload(cfg, flags, []string{"https://exercism.org/tracks/bogus-track/exercises/bogus-exercise"})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "give either the address of an exercise")
+	}
+}
+
+func TestDownloadFilesConcurrently(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	// Each file is only served once all three have been asked for.
+	var arrived sync.WaitGroup
+	arrived.Add(3)
+	var mu sync.Mutex
+	requests := map[string]int{}
+	broken := true
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"solution": {"id": "bogus-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "bogus-exercise", "track": {"id": "bogus-track"}}, "file_download_base_url": "%s/", "files": ["a.txt", "b.txt", "c.txt", "broken-1.txt", "broken-2.txt"]}}`, ts.URL)
+	})
+	for _, name := range []string{"a.txt", "b.txt", "c.txt"} {
+		name := name
+		mux.HandleFunc("/"+name, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests[name]++
+			mu.Unlock()
+			arrived.Done()
+			arrived.Wait()
+			fmt.Fprint(w, "this is "+name)
+		})
+	}
+	for _, name := range []string{"broken-1.txt", "broken-2.txt"} {
+		name := name
+		mux.HandleFunc("/"+name, func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			requests[name]++
+			fail := broken
+			mu.Unlock()
+			if fail {
+				// The connection breaks off part way through the file. Once
+				// the response has started, the client doesn't retry it itself.
+				w.Header().Set("Content-Length", "100")
+				fmt.Fprint(w, "this is")
+				w.(http.Flusher).Flush()
+				conn, _, err := w.(http.Hijacker).Hijack()
+				assert.NoError(t, err)
+				conn.Close()
+				return
+			}
+			fmt.Fprint(w, "this is "+name)
+		})
+	}
+
+	tmpDir, err := ioutil.TempDir("", "download-concurrently")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("exercise", "bogus-exercise")
+	flags.Set("workers", "5")
+
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "unable to download 2 of the files of bogus-exercise")
+		assert.Contains(t, err.Error(), "broken-1.txt - ")
+		assert.Contains(t, err.Error(), "broken-2.txt - ")
+	}
+	// Nothing is put in place until all the files have arrived.
+	_, err = os.Stat(filepath.Join(tmpDir, "bogus-track", "bogus-exercise"))
+	assert.True(t, os.IsNotExist(err))
+
+	// Downloading again only fetches the files that are missing.
+	mu.Lock()
+	broken = false
+	mu.Unlock()
+	err = runDownload(cfg, flags, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]int{"a.txt": 1, "b.txt": 1, "c.txt": 1, "broken-1.txt": 2, "broken-2.txt": 2}, requests)
+	for _, name := range []string{"a.txt", "b.txt", "c.txt", "broken-1.txt", "broken-2.txt"} {
+		b, err := ioutil.ReadFile(filepath.Join(tmpDir, "bogus-track", "bogus-exercise", name))
+		assert.NoError(t, err)
+		assert.Equal(t, "this is "+name, string(b))
+	}
+	_, err = os.Stat(filepath.Join(tmpDir, ".exercism"))
+	assert.True(t, os.IsNotExist(err))
+
+	flags.Set("workers", "0")
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "invalid --workers")
+	}
+}
+
 func fakeDownloadServer(requestor, teamSlug string) *httptest.Server {
 	mux := http.NewServeMux()
 	server := httptest.NewServer(mux)
@@ -190,13 +1294,16 @@ func fakeDownloadServer(requestor, teamSlug string) *httptest.Server {
 	})
 
 	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
-		team := "null"
-		if teamSlug := r.FormValue("team_id"); teamSlug != "" {
-			team = fmt.Sprintf(`{"name": "Bogus Team", "slug": "%s"}`, teamSlug)
-		}
-		payloadBody := fmt.Sprintf(payloadTemplate, requestor, team, server.URL+"/")
+		payloadBody := fmt.Sprintf(payloadTemplate, requestor, "null", server.URL+"/")
 		fmt.Fprint(w, payloadBody)
 	})
+	if teamSlug != "" {
+		mux.HandleFunc("/teams/"+teamSlug+"/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+			team := fmt.Sprintf(`{"name": "Bogus Team", "slug": "%s"}`, teamSlug)
+			payloadBody := fmt.Sprintf(payloadTemplate, requestor, team, server.URL+"/")
+			fmt.Fprint(w, payloadBody)
+		})
+	}
 	mux.HandleFunc("/solutions/bogus-id", func(w http.ResponseWriter, r *http.Request) {
 		payloadBody := fmt.Sprintf(payloadTemplate, requestor, "null", server.URL+"/")
 		fmt.Fprint(w, payloadBody)
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/exercism/cli/api"
+	"github.com/spf13/viper"
+)
+
+// defaultDownloadWorkers is how many requests download makes at the same time,
+// unless --workers says otherwise.
+const defaultDownloadWorkers = 4
+
+// rateLimitWait is how long to hold back requests that the API turned away
+// for going over its rate limit, when it doesn't say how long to wait.
+var rateLimitWait = 10 * time.Second
+
+// rateLimitRetries is how many times a request that the API turned away
+// for going over its rate limit is retried.
+const rateLimitRetries = 5
+
+// downloadTrack downloads all the exercises of a track that are available
+// to the user, several at a time. Progress is reported as each one finishes.
+func downloadTrack(client *api.Client, usrCfg *viper.Viper, track, team string, opts downloadOptions) error {
+	// There are a lot of requests to make, so going over the API's rate
+	// limit shouldn't stop the download part way.
+	var mu sync.Mutex
+	limiter := &api.RateLimiter{
+		Retries:     rateLimitRetries,
+		DefaultWait: rateLimitWait,
+		MaxWait:     5 * time.Minute,
+		OnLimit: func(wait time.Duration) {
+			mu.Lock()
+			defer mu.Unlock()
+			fmt.Fprintf(Err, "The API is limiting the rate of requests. Waiting %s.\n", wait)
+		},
+	}
+	client.RateLimiter = limiter
+
+	var exercises []api.Exercise
+	var err error
+	if team != "" {
+		exercises, err = client.TeamExercises(team, track)
+	} else {
+		exercises, err = client.Exercises(track)
+	}
+	if err != nil {
+		return fmt.Errorf("unable to list the exercises of the %s track - %s", track, err)
+	}
+	var slugs []string
+	for _, exercise := range exercises {
+		if !exercise.Locked {
+			slugs = append(slugs, exercise.ID)
+		}
+	}
+	if len(slugs) == 0 {
+		return fmt.Errorf("there are no exercises to download on the %s track", track)
+	}
+
+	// The exercises are downloaded --workers at a time, so each one fetches
+	// its files one by one to keep to that many requests altogether.
+	exerciseOpts := opts
+	exerciseOpts.workers = 1
+
+	var failed []string
+	done := 0
+	forEachConcurrently(len(slugs), opts.workers, func(i int) error {
+		params := downloadParams{track: track, exercise: slugs[i], team: team}
+		solution, err := downloadExercise(client, usrCfg, params, exerciseOpts)
+
+		mu.Lock()
+		defer mu.Unlock()
+		done++
+		if err != nil {
+			failed = append(failed, slugs[i])
+			fmt.Fprintf(Err, "[%d/%d] Unable to download %s - %s\n", done, len(slugs), slugs[i], err)
+			return nil
+		}
+		fmt.Fprintf(Err, "[%d/%d] Downloaded %s\n", done, len(slugs), slugs[i])
+		fmt.Fprintf(Out, "%s\n", solution.Dir)
+		return nil
+	})
+
+	fmt.Fprintf(Err, "\nDownloaded %d of %d exercises of the %s track.\n", len(slugs)-len(failed), len(slugs), track)
+	if retried, waited := limiter.Retried(); retried > 0 {
+		fmt.Fprintf(Err, "Retried %d requests that went over the API's rate limit, after waiting %s altogether.\n", retried, waited.Round(time.Second))
+	}
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("unable to download %s", strings.Join(failed, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,267 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadAll(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	mux.HandleFunc("/tracks/bogus-track/exercises", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"exercises": [{"id": "one"}, {"id": "two"}, {"id": "three"}, {"id": "locked", "locked": true}, {"id": "broken"}]}`)
+	})
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "bogus-track", r.FormValue("track_id"))
+		exercise := r.FormValue("exercise_id")
+		if exercise == "broken" || exercise == "locked" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"error": {"type": "exercise_not_found", "message": "no such exercise"}}`)
+			return
+		}
+		fmt.Fprintf(w, `{"solution": {"id": "%[1]s-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "%[1]s", "track": {"id": "bogus-track"}}, "file_download_base_url": "%[2]s/files/%[1]s/", "files": ["%[1]s.txt"]}}`, exercise, ts.URL)
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, "this is %s", filepath.Base(r.URL.Path))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-all")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("track", "bogus-track")
+	flags.Set("all", "true")
+
+	var out bytes.Buffer
+	Out = &out
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Equal(t, "unable to download broken", err.Error())
+	}
+
+	dirs := strings.Fields(out.String())
+	sort.Strings(dirs)
+	assert.Equal(t, []string{
+		filepath.Join(tmpDir, "bogus-track", "one"),
+		filepath.Join(tmpDir, "bogus-track", "three"),
+		filepath.Join(tmpDir, "bogus-track", "two"),
+	}, dirs)
+	for _, slug := range []string{"one", "two", "three"} {
+		b, err := ioutil.ReadFile(filepath.Join(tmpDir, "bogus-track", slug, slug+".txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "this is "+slug+".txt", string(b))
+	}
+	_, err = os.Stat(filepath.Join(tmpDir, "bogus-track", "locked"))
+	assert.True(t, os.IsNotExist(err))
+	// The staging areas are cleaned up once all the downloads are done.
+	_, err = os.Stat(filepath.Join(tmpDir, workspace.MetadataDirName))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestDownloadAllKeepsToWorkers(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var mu sync.Mutex
+	inFlight, most := 0, 0
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/tracks/bogus-track/exercises", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"exercises": [{"id": "one"}, {"id": "two"}, {"id": "three"}, {"id": "four"}]}`)
+	})
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"solution": {"id": "%[1]s-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "%[1]s", "track": {"id": "bogus-track"}}, "file_download_base_url": "%[2]s/files/%[1]s/", "files": ["a.txt", "b.txt", "c.txt", "d.txt"]}}`, r.FormValue("exercise_id"), ts.URL)
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		inFlight++
+		if inFlight > most {
+			most = inFlight
+		}
+		mu.Unlock()
+		// Give the other workers time to send theirs.
+		time.Sleep(10 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+		fmt.Fprintf(w, "this is %s", filepath.Base(r.URL.Path))
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-all-workers")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("track", "bogus-track")
+	flags.Set("all", "true")
+	flags.Set("workers", "2")
+
+	assert.NoError(t, runDownload(cfg, flags, []string{}))
+	assert.True(t, most <= 2, "%d files were downloaded at the same time", most)
+	b, err := ioutil.ReadFile(filepath.Join(tmpDir, "bogus-track", "four", "d.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "this is d.txt", string(b))
+}
+
+func TestDownloadAllNeedsTrack(t *testing.T) {
+	v := viper.New()
+	v.Set("workspace", "/tmp")
+	v.Set("apibaseurl", "http://example.com")
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+
+	testCases := []struct {
+		flags map[string]string
+		err   string
+	}{
+		{
+			flags: map[string]string{"all": "true"},
+			err:   "--all needs a --track",
+		},
+		{
+			flags: map[string]string{"all": "true", "track": "go", "exercise": "bob"},
+			err:   "can't be combined",
+		},
+	}
+
+	for _, tc := range testCases {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupDownloadFlags(flags)
+		for name, value := range tc.flags {
+			flags.Set(name, value)
+		}
+		err := runDownload(cfg, flags, []string{})
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), tc.err)
+		}
+	}
+}
+
+func TestDownloadAllWaitsOutRateLimit(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldWait := rateLimitWait
+	Out = ioutil.Discard
+	rateLimitWait = time.Millisecond
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		rateLimitWait = oldWait
+	}()
+
+	// Every other request goes over the limit.
+	var mu sync.Mutex
+	requests := 0
+	limited := func(w http.ResponseWriter) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		if requests%2 == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return true
+		}
+		return false
+	}
+	mux := http.NewServeMux()
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+	mux.HandleFunc("/tracks/bogus-track/exercises", func(w http.ResponseWriter, r *http.Request) {
+		if !limited(w) {
+			fmt.Fprint(w, `{"exercises": [{"id": "one"}, {"id": "two"}]}`)
+		}
+	})
+	mux.HandleFunc("/solutions/latest", func(w http.ResponseWriter, r *http.Request) {
+		if !limited(w) {
+			fmt.Fprintf(w, `{"solution": {"id": "%[1]s-id", "user": {"handle": "alice", "is_requester": true}, "exercise": {"id": "%[1]s", "track": {"id": "bogus-track"}}, "file_download_base_url": "%[2]s/files/", "files": ["%[1]s.txt"]}}`, r.FormValue("exercise_id"), ts.URL)
+		}
+	})
+	mux.HandleFunc("/files/", func(w http.ResponseWriter, r *http.Request) {
+		if !limited(w) {
+			fmt.Fprintf(w, "this is %s", filepath.Base(r.URL.Path))
+		}
+	})
+
+	tmpDir, err := ioutil.TempDir("", "download-all-rate-limit")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("track", "bogus-track")
+	flags.Set("all", "true")
+	flags.Set("workers", "1")
+
+	var errBuf bytes.Buffer
+	Err = &errBuf
+	assert.NoError(t, runDownload(cfg, flags, []string{}))
+	for _, slug := range []string{"one", "two"} {
+		b, err := ioutil.ReadFile(filepath.Join(tmpDir, "bogus-track", slug, slug+".txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "this is "+slug+".txt", string(b))
+	}
+	assert.Contains(t, errBuf.String(), "The API is limiting the rate of requests")
+	assert.Contains(t, errBuf.String(), "Retried 5 requests that went over the API's rate limit")
+}
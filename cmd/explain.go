@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+)
+
+// explanation collects the decisions a command makes, along with the reasons for them.
+// A nil explanation ignores everything, so callers don't need to check whether
+// they're explaining.
+type explanation struct {
+	steps []string
+}
+
+func (e *explanation) add(format string, a ...interface{}) {
+	if e == nil {
+		return
+	}
+	e.steps = append(e.steps, fmt.Sprintf(format, a...))
+}
+
+// Print writes the decisions as a numbered list.
+func (e *explanation) Print(w io.Writer, intro string) {
+	fmt.Fprintf(w, "\n    %s\n\n", intro)
+	for i, step := range e.steps {
+		fmt.Fprintf(w, "    %d. %s\n", i+1, step)
+	}
+	fmt.Fprintln(w)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/spf13/pflag"
+	yaml "gopkg.in/yaml.v2"
+)
+
+// tabular is implemented by command results that can be shown as a table.
+// The same results are marshaled as they are for the json and yaml formats.
+type tabular interface {
+	Columns() []string
+	Rows() [][]string
+}
+
+// formatter renders the structured result of a command.
+type formatter interface {
+	Format(w io.Writer, result tabular) error
+}
+
+var formatters = map[string]formatter{
+	"table": tableFormatter{},
+	"json":  jsonFormatter{},
+	"yaml":  yamlFormatter{},
+}
+
+// newFormatter returns the formatter with the given name.
+func newFormatter(name string) (formatter, error) {
+	f, ok := formatters[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown format '%s'. Valid formats are: table, json, yaml", name)
+	}
+	return f, nil
+}
+
+// setupFormatFlag adds the --format flag shared by commands with structured output.
+func setupFormatFlag(flags *pflag.FlagSet, defaultFormat string) {
+	flags.StringP("format", "", defaultFormat, "output format: table, json, or yaml")
+}
+
+type tableFormatter struct{}
+
+// Format writes the result as aligned columns with a header row.
+func (tableFormatter) Format(w io.Writer, result tabular) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.ToUpper(strings.Join(result.Columns(), "\t")))
+	for _, row := range result.Rows() {
+		fmt.Fprintln(tw, strings.Join(row, "\t"))
+	}
+	return tw.Flush()
+}
+
+type jsonFormatter struct{}
+
+// Format writes the result as indented JSON.
+func (jsonFormatter) Format(w io.Writer, result tabular) error {
+	b, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "%s\n", b)
+	return err
+}
+
+type yamlFormatter struct{}
+
+// Format writes the result as YAML.
+func (yamlFormatter) Format(w io.Writer, result tabular) error {
+	b, err := yaml.Marshal(result)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeResult []struct {
+	Name  string `json:"name" yaml:"name"`
+	Count int    `json:"count" yaml:"count"`
+}
+
+func (r fakeResult) Columns() []string {
+	return []string{"name", "count"}
+}
+
+func (r fakeResult) Rows() [][]string {
+	rows := make([][]string, len(r))
+	for i, item := range r {
+		rows[i] = []string{item.Name, strconv.Itoa(item.Count)}
+	}
+	return rows
+}
+
+func TestFormatters(t *testing.T) {
+	result := fakeResult{
+		{Name: "hello-world", Count: 1},
+		{Name: "bob", Count: 3},
+	}
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: "table",
+			expected: `NAME         COUNT
+hello-world  1
+bob          3
+`,
+		},
+		{
+			format: "json",
+			expected: `[
+  {
+    "name": "hello-world",
+    "count": 1
+  },
+  {
+    "name": "bob",
+    "count": 3
+  }
+]
+`,
+		},
+		{
+			format: "yaml",
+			expected: `- name: hello-world
+  count: 1
+- name: bob
+  count: 3
+`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			f, err := newFormatter(tc.format)
+			assert.NoError(t, err)
+
+			var buf bytes.Buffer
+			err = f.Format(&buf, result)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	_, err := newFormatter("xml")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "unknown format 'xml'", err.Error())
+	}
+}
@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/exercism/cli/config"
+)
+
+// historyFilename is the log of successful submissions in the config dir,
+// with one JSON entry per line.
+const historyFilename = "history.jsonl"
+
+// historyEntry records a successful submission.
+type historyEntry struct {
+	SubmittedAt time.Time `json:"submitted_at" yaml:"submitted_at"`
+	Track       string    `json:"track" yaml:"track"`
+	Exercise    string    `json:"exercise" yaml:"exercise"`
+	SolutionID  string    `json:"solution_id" yaml:"solution_id"`
+	Team        string    `json:"team,omitempty" yaml:"team,omitempty"`
+	Iteration   int       `json:"iteration,omitempty" yaml:"iteration,omitempty"`
+	URL         string    `json:"url" yaml:"url"`
+	Files       []string  `json:"files" yaml:"files"`
+}
+
+type historyEntries []historyEntry
+
+// Columns implements tabular.
+func (h historyEntries) Columns() []string {
+	return []string{"submitted", "track", "exercise", "iteration", "files", "url"}
+}
+
+// Rows implements tabular.
+func (h historyEntries) Rows() [][]string {
+	rows := make([][]string, 0, len(h))
+	for _, entry := range h {
+		iteration := "-"
+		if entry.Iteration > 0 {
+			iteration = strconv.Itoa(entry.Iteration)
+		}
+		rows = append(rows, []string{
+			entry.SubmittedAt.Local().Format("2006-01-02 15:04"),
+			entry.Track,
+			entry.Exercise,
+			iteration,
+			strconv.Itoa(len(entry.Files)),
+			entry.URL,
+		})
+	}
+	return rows
+}
+
+// appendHistory adds an entry to the history in dir.
+// Without a config dir there is nowhere to keep it.
+func appendHistory(dir string, entry historyEntry) error {
+	if dir == "" {
+		return nil
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	b, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, historyFilename), os.O_APPEND|os.O_CREATE|os.O_WRONLY, os.FileMode(0600))
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(append(b, '\n')); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// readHistory returns the entries in the history in dir, oldest first.
+// Lines that can't be read, e.g. because an earlier write was cut short,
+// are skipped.
+func readHistory(dir string) (historyEntries, error) {
+	if dir == "" {
+		return nil, nil
+	}
+	f, err := os.Open(filepath.Join(dir, historyFilename))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var entries historyEntries
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10<<20)
+	for scanner.Scan() {
+		var entry historyEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, scanner.Err()
+}
+
+// runSubmitHistory prints the submissions recorded in the history,
+// limited to a track or exercise if the flags name one.
+func runSubmitHistory(cfg config.Config, f submitFlags, output formatter) error {
+	entries, err := readHistory(cfg.Dir)
+	if err != nil {
+		return err
+	}
+	var matching historyEntries
+	for _, entry := range entries {
+		if f.track != "" && entry.Track != f.track {
+			continue
+		}
+		if f.exercise != "" && entry.Exercise != f.exercise {
+			continue
+		}
+		matching = append(matching, entry)
+	}
+
+	if output == nil {
+		if len(matching) == 0 {
+			const msg = "\n    No submissions have been recorded yet.\n\n"
+			_, err := fmt.Fprint(Err, msg)
+			return err
+		}
+		output = tableFormatter{}
+	}
+	return output.Format(Out, matching)
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadHistory(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "history")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	entries, err := readHistory(tmpDir)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+
+	submittedAt := time.Date(2018, 6, 1, 12, 30, 0, 0, time.UTC)
+	err = appendHistory(tmpDir, historyEntry{SubmittedAt: submittedAt, Track: "go", Exercise: "bob", Files: []string{"bob.go"}})
+	assert.NoError(t, err)
+
+	// A line that was cut short is skipped.
+	f, err := os.OpenFile(filepath.Join(tmpDir, historyFilename), os.O_APPEND|os.O_WRONLY, 0)
+	assert.NoError(t, err)
+	_, err = f.WriteString(`{"track": "go", "exerc` + "\n")
+	assert.NoError(t, err)
+	f.Close()
+
+	err = appendHistory(tmpDir, historyEntry{SubmittedAt: submittedAt, Track: "go", Exercise: "leap", Iteration: 3})
+	assert.NoError(t, err)
+
+	entries, err = readHistory(tmpDir)
+	assert.NoError(t, err)
+	if assert.Len(t, entries, 2) {
+		assert.Equal(t, "bob", entries[0].Exercise)
+		assert.Equal(t, []string{"bob.go"}, entries[0].Files)
+		assert.True(t, submittedAt.Equal(entries[0].SubmittedAt))
+		assert.Equal(t, "leap", entries[1].Exercise)
+		assert.Equal(t, 3, entries[1].Iteration)
+	}
+}
+
+func TestSubmitHistory(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-history")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	var files []string
+	for _, exercise := range []string{"bogus-exercise", "other-exercise"} {
+		dir := filepath.Join(tmpDir, "bogus-track", exercise)
+		os.MkdirAll(dir, os.FileMode(0755))
+		writeFakeSolution(t, dir, "bogus-track", exercise)
+		file := filepath.Join(dir, "bogus.go")
+		err = ioutil.WriteFile(file, []byte("package bogus"), os.FileMode(0644))
+		assert.NoError(t, err)
+		files = append(files, file)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		Dir:             filepath.Join(tmpDir, "config"),
+		UserViperConfig: v,
+	}
+
+	var buf bytes.Buffer
+	Err = &buf
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("history", "true")
+	err = runSubmit(context.Background(), cfg, flags, nil)
+	assert.NoError(t, err)
+	assert.Contains(t, buf.String(), "No submissions have been recorded yet.")
+	Err = ioutil.Discard
+
+	for _, file := range files {
+		flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		err = runSubmit(context.Background(), cfg, flags, []string{file})
+		assert.NoError(t, err)
+	}
+
+	buf.Reset()
+	Out = &buf
+	flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("history", "true")
+	err = runSubmit(context.Background(), cfg, flags, nil)
+	assert.NoError(t, err)
+	assert.Regexp(t, `SUBMITTED\s+TRACK\s+EXERCISE\s+ITERATION\s+FILES\s+URL`, buf.String())
+	assert.Regexp(t, `bogus-track\s+bogus-exercise\s+-\s+1`, buf.String())
+	assert.Regexp(t, `bogus-track\s+other-exercise\s+-\s+1`, buf.String())
+
+	buf.Reset()
+	flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("history", "true")
+	flags.Set("exercise", "other-exercise")
+	flags.Set("format", "json")
+	err = runSubmit(context.Background(), cfg, flags, nil)
+	assert.NoError(t, err)
+
+	var entries []historyEntry
+	assert.NoError(t, json.Unmarshal(buf.Bytes(), &entries))
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, "other-exercise", entries[0].Exercise)
+		assert.Equal(t, []string{"bogus.go"}, entries[0].Files)
+		assert.False(t, entries[0].SubmittedAt.IsZero())
+	}
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"runtime"
+
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/viper"
+)
+
+// runHook runs a hook command in the exercise directory,
+// through the platform's shell. Its output goes to w.
+func runHook(ctx context.Context, command, dir string, w io.Writer) error {
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	cmd.Dir = dir
+	cmd.Stdout = w
+	cmd.Stderr = w
+	return cmd.Run()
+}
+
+// runPostDownloadHook runs the post-download command from the config, if
+// there is one, to get the exercise ready to work on. The download is done
+// by then, so a failing command only gets a warning.
+func runPostDownloadHook(usrCfg *viper.Viper, solution *workspace.Solution) {
+	hook := config.PostDownloadCommand(usrCfg, solution.Track, solution.Exercise)
+	if hook == "" {
+		return
+	}
+	ctx, stop := interruptContext()
+	defer stop()
+
+	fmt.Fprintf(Err, "\n    Running the post-download command: %s\n\n", hook)
+	if err := runHook(ctx, hook, solution.Dir, Err); err != nil {
+		msg := `
+
+    WARNING: The post-download command failed: %s
+
+        %s
+
+    The exercise was downloaded, but may not be ready to run yet.
+
+`
+		fmt.Fprintf(Err, msg, err, hook)
+	}
+}
@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"strings"
+)
+
+// instructionsFiles tell what an exercise is about. Newer exercises split
+// them into an introduction and the instructions, older ones have a README.
+var instructionsFiles = [][]string{
+	{filepath.Join(".docs", "introduction.md"), filepath.Join(".docs", "instructions.md")},
+	{"README.md"},
+}
+
+// rgxHTMLComment matches the comments that are left in the Markdown for
+// the people who maintain the exercises.
+var rgxHTMLComment = regexp.MustCompile(`(?s)<!--.*?-->\n?`)
+
+// rgxBlankLines matches runs of blank lines.
+var rgxBlankLines = regexp.MustCompile(`\n{3,}`)
+
+// readInstructions reads the introduction and instructions of the exercise
+// in dir. It's empty if there are none.
+func readInstructions(dir string) (string, error) {
+	for _, names := range instructionsFiles {
+		var parts []string
+		for _, name := range names {
+			b, err := ioutil.ReadFile(filepath.Join(dir, name))
+			if os.IsNotExist(err) {
+				continue
+			}
+			if err != nil {
+				return "", err
+			}
+			if part := renderInstructions(string(b)); part != "" {
+				parts = append(parts, part)
+			}
+		}
+		if len(parts) > 0 {
+			return strings.Join(parts, "\n\n") + "\n", nil
+		}
+	}
+	return "", nil
+}
+
+// renderInstructions tidies up Markdown for reading in a terminal.
+func renderInstructions(markdown string) string {
+	text := strings.Replace(markdown, "\r\n", "\n", -1)
+	text = rgxHTMLComment.ReplaceAllString(text, "")
+	text = rgxBlankLines.ReplaceAllString(text, "\n\n")
+	return strings.TrimSpace(text)
+}
+
+// showInstructions prints the instructions of the exercise in dir.
+// In a terminal, they go through the $PAGER, if there is one.
+func showInstructions(dir string) error {
+	text, err := readInstructions(dir)
+	if err != nil || text == "" {
+		return err
+	}
+
+	pager := os.Getenv("PAGER")
+	if pager == "" || !isTerminal(Err) {
+		fmt.Fprintf(Err, "\n%s", text)
+		return nil
+	}
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.Command("cmd", "/C", pager)
+	} else {
+		cmd = exec.Command("sh", "-c", pager)
+	}
+	cmd.Stdin = strings.NewReader(text)
+	cmd.Stdout = Err
+	cmd.Stderr = Err
+	return cmd.Run()
+}
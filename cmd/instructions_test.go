@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadInstructions(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "instructions")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	text, err := readInstructions(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "", text)
+
+	readme := "# Bogus\r\n\r\n<!-- not for students -->\r\nDo the thing.\r\n\r\n\r\n\r\nThen stop.\r\n"
+	err = ioutil.WriteFile(filepath.Join(tmpDir, "README.md"), []byte(readme), os.FileMode(0644))
+	assert.NoError(t, err)
+	text, err = readInstructions(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Bogus\n\nDo the thing.\n\nThen stop.\n", text)
+
+	// The introduction and instructions take precedence over the README.
+	docs := filepath.Join(tmpDir, ".docs")
+	assert.NoError(t, os.MkdirAll(docs, os.FileMode(0755)))
+	err = ioutil.WriteFile(filepath.Join(docs, "introduction.md"), []byte("# Introduction\n\nOnce upon a time.\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(docs, "instructions.md"), []byte("# Instructions\n\nDo the thing.\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+	text, err = readInstructions(tmpDir)
+	assert.NoError(t, err)
+	assert.Equal(t, "# Introduction\n\nOnce upon a time.\n\n# Instructions\n\nDo the thing.\n", text)
+}
+
+func TestShowInstructions(t *testing.T) {
+	oldErr := Err
+	defer func() {
+		Err = oldErr
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+
+	tmpDir, err := ioutil.TempDir("", "show-instructions")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(tmpDir, "README.md"), []byte("Do the thing.\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	// Buffers aren't terminals, so the pager isn't used.
+	os.Setenv("PAGER", "false")
+	defer os.Unsetenv("PAGER")
+	assert.NoError(t, showInstructions(tmpDir))
+	assert.Equal(t, "\nDo the thing.\n", errBuf.String())
+}
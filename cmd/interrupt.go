@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// exitCodeInterrupted is the conventional exit code for a process stopped by SIGINT.
+const exitCodeInterrupted = 130
+
+// errInterrupted signals that the person cancelled the command.
+var errInterrupted = errors.New("interrupted")
+
+// silenceInterrupted keeps cobra from printing errInterrupted, since the
+// command has already said that it was cancelled. The error is still
+// returned, so that the process exits with exitCodeInterrupted.
+func silenceInterrupted(cmd *cobra.Command, err error) error {
+	if err == errInterrupted {
+		cmd.SilenceErrors = true
+	}
+	return err
+}
+
+// interruptContext returns a context that is cancelled when the process
+// receives SIGINT or SIGTERM.
+// Call stop when the command is done to release the signal handler.
+func interruptContext() (ctx context.Context, stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case <-signals:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
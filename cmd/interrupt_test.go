@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSilenceInterrupted(t *testing.T) {
+	testCases := []struct {
+		err     error
+		printed bool
+	}{
+		{errInterrupted, false},
+		{errors.New("boom"), true},
+	}
+	for _, tc := range testCases {
+		root := &cobra.Command{Use: "root", SilenceUsage: true}
+		root.AddCommand(&cobra.Command{
+			Use: "run",
+			RunE: func(cmd *cobra.Command, args []string) error {
+				return silenceInterrupted(cmd, tc.err)
+			},
+		})
+		var buf bytes.Buffer
+		root.SetOutput(&buf)
+		root.SetArgs([]string{"run"})
+
+		err := root.Execute()
+		assert.Equal(t, tc.err, err)
+		assert.Equal(t, tc.printed, bytes.Contains(buf.Bytes(), []byte("Error:")), tc.err.Error())
+	}
+}
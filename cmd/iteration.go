@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/debug"
+)
+
+// testsPollInterval is how often the API is asked whether the automated
+// tests of a new iteration have finished.
+var testsPollInterval = time.Second
+
+// awaitTests polls the API until the automated tests of the iteration have
+// finished, the timeout runs out, or the context is cancelled. It returns
+// the most recent state of the iteration it knows about.
+func awaitTests(ctx context.Context, client *api.Client, solutionID string, iteration api.Iteration, timeout time.Duration) api.Iteration {
+	deadline := time.Now().Add(timeout)
+	for iteration.TestsPending() && time.Now().Add(testsPollInterval).Before(deadline) {
+		select {
+		case <-ctx.Done():
+			return iteration
+		case <-time.After(testsPollInterval):
+		}
+
+		latest, err := client.LatestIteration(solutionID)
+		if err != nil {
+			// The submission already went through, so this is not worth failing over.
+			debug.Printf("Unable to check the automated tests: %s\n", err)
+			return iteration
+		}
+		if latest.Number != iteration.Number {
+			// Someone submitted again in the meantime.
+			return iteration
+		}
+		iteration = *latest
+	}
+	return iteration
+}
+
+// describeIteration summarizes an iteration and its automated tests.
+func describeIteration(iteration api.Iteration) string {
+	s := fmt.Sprintf("This is iteration %d.", iteration.Number)
+	switch iteration.TestsStatus {
+	case "":
+		return s
+	case "queued":
+		return s + " The automated tests are queued."
+	case "running":
+		return s + " The automated tests are still running."
+	case "passed":
+		return s + " The automated tests passed."
+	case "failed":
+		return s + " The automated tests failed."
+	default:
+		return fmt.Sprintf("%s The status of the automated tests is '%s'.", s, iteration.TestsStatus)
+	}
+}
+
+// recentIterations is how many iterations are listed after submitting.
+const recentIterations = 5
+
+// printIterations lists the most recent iterations, pointing out the one
+// with the given number.
+func printIterations(w io.Writer, iterations []api.Iteration, current int) error {
+	if len(iterations) > recentIterations {
+		iterations = iterations[len(iterations)-recentIterations:]
+	}
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "    ITERATION\tSUBMITTED\tTESTS\t\n")
+	for _, iteration := range iterations {
+		submitted := "-"
+		if iteration.SubmittedAt != nil {
+			submitted = iteration.SubmittedAt.Local().Format("2006-01-02 15:04")
+		}
+		status := iteration.TestsStatus
+		if status == "" {
+			status = "-"
+		}
+		note := ""
+		if iteration.Number == current {
+			note = "(this one)"
+		}
+		fmt.Fprintf(tw, "    %d\t%s\t%s\t%s\n", iteration.Number, submitted, status, note)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(w)
+	return nil
+}
@@ -0,0 +1,52 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/exercism/cli/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDescribeIteration(t *testing.T) {
+	testCases := []struct {
+		status   string
+		expected string
+	}{
+		{"", "This is iteration 3."},
+		{"queued", "This is iteration 3. The automated tests are queued."},
+		{"passed", "This is iteration 3. The automated tests passed."},
+		{"failed", "This is iteration 3. The automated tests failed."},
+		{"errored", "This is iteration 3. The status of the automated tests is 'errored'."},
+	}
+
+	for _, tc := range testCases {
+		got := describeIteration(api.Iteration{Number: 3, TestsStatus: tc.status})
+		assert.Equal(t, tc.expected, got, tc.status)
+	}
+}
+
+func TestPrintIterations(t *testing.T) {
+	submittedAt := time.Date(2018, 8, 20, 10, 11, 12, 0, time.Local)
+	var iterations []api.Iteration
+	for i := 1; i <= 7; i++ {
+		iterations = append(iterations, api.Iteration{Number: i, SubmittedAt: &submittedAt, TestsStatus: "failed"})
+	}
+	iterations[6].TestsStatus = "queued"
+	iterations[5].SubmittedAt = nil
+
+	var buf bytes.Buffer
+	err := printIterations(&buf, iterations, 7)
+	assert.NoError(t, err)
+
+	lines := strings.Split(strings.Trim(buf.String(), "\n"), "\n")
+	// Only the most recent ones are listed, after a header.
+	if assert.Equal(t, 1+recentIterations, len(lines)) {
+		assert.Regexp(t, `^\s+ITERATION\s+SUBMITTED\s+TESTS`, lines[0])
+		assert.Regexp(t, `^\s+3\s+2018-08-20 10:11\s+failed\s*$`, lines[1])
+		assert.Regexp(t, `^\s+6\s+-\s+failed\s*$`, lines[4])
+		assert.Regexp(t, `^\s+7\s+2018-08-20 10:11\s+queued\s+\(this one\)$`, lines[5])
+	}
+}
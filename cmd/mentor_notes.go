@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/workspace"
+)
+
+// mentorNotesFilename is where the discussion about a solution is written,
+// within the exercise directory.
+const mentorNotesFilename = "MENTOR_NOTES.md"
+
+// downloadMentorNotes writes the discussion about the solution in dir to
+// its mentor notes, replacing any earlier copy.
+func downloadMentorNotes(client *api.Client, loc workspace.Location) error {
+	if loc.Solution.ID == "" {
+		msg := `
+
+    The metadata of the exercise doesn't say which solution it is.
+    Download the exercise again, then call the command again.
+
+`
+		return errors.New(msg)
+	}
+
+	comments, err := client.MentorComments(loc.Solution.ID)
+	if err != nil {
+		return fmt.Errorf("unable to fetch the mentor discussion - %s", err)
+	}
+
+	var buf bytes.Buffer
+	writeMentorNotes(&buf, loc.Solution, comments)
+	path := filepath.Join(loc.Dir, mentorNotesFilename)
+	if err := ioutil.WriteFile(path, buf.Bytes(), os.FileMode(0644)); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(Err, "\nWrote %d comment(s) to\n", len(comments))
+	fmt.Fprintf(Out, "%s\n", path)
+	return nil
+}
+
+// writeMentorNotes renders the discussion as Markdown.
+func writeMentorNotes(w io.Writer, solution *workspace.Solution, comments []api.Comment) {
+	fmt.Fprintf(w, "# Mentor notes for %s (%s)\n\n", solution.Exercise, solution.Track)
+	if solution.URL != "" {
+		fmt.Fprintf(w, "The discussion of %s, as downloaded by the CLI.\n", solution.URL)
+	}
+	if len(comments) == 0 {
+		fmt.Fprintf(w, "\nNo one has commented on the solution yet.\n")
+		return
+	}
+
+	for _, comment := range comments {
+		role := ""
+		if comment.Mentor {
+			role = " (mentor)"
+		}
+		fmt.Fprintf(w, "\n## %s%s, %s\n\n", comment.Author.Handle, role, comment.CreatedAt.Local().Format("2006-01-02 15:04"))
+		if content := strings.TrimSpace(comment.Content); content != "" {
+			fmt.Fprintf(w, "%s\n", content)
+		}
+		for _, snippet := range comment.Snippets {
+			fmt.Fprintf(w, "\n```%s\n%s\n```\n", snippet.Language, strings.TrimRight(snippet.Code, "\n"))
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDownloadMentorNotes(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	createdAt := time.Date(2018, 6, 1, 12, 30, 0, 0, time.UTC)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/solutions/bogus-solution-uuid/discussions", r.URL.Path)
+		fmt.Fprintf(w, `{"comments": [
+			{"author": {"handle": "alice"}, "is_mentor": true, "created_at": "%[1]s", "content": "Try a switch here.\n", "snippets": [{"language": "go", "code": "switch {\n}\n"}]},
+			{"author": {"handle": "bob"}, "created_at": "%[1]s", "content": "Thanks!"}
+		]}`, createdAt.Format(time.RFC3339))
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "mentor-notes")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+	tmpDir, err = filepath.EvalSymlinks(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	assert.NoError(t, os.MkdirAll(dir, os.FileMode(0755)))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("token", "abc123")
+
+	cfg := config.Config{
+		UserViperConfig: v,
+	}
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+	assert.NoError(t, os.Chdir(dir))
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupDownloadFlags(flags)
+	flags.Set("mentor-notes", "true")
+	err = runDownload(cfg, flags, []string{})
+	assert.NoError(t, err)
+
+	when := createdAt.Local().Format("2006-01-02 15:04")
+	expected := "# Mentor notes for bogus-exercise (bogus-track)\n\n" +
+		"The discussion of http://example.com/bogus-url, as downloaded by the CLI.\n\n" +
+		"## alice (mentor), " + when + "\n\n" +
+		"Try a switch here.\n\n" +
+		"```go\nswitch {\n}\n```\n\n" +
+		"## bob, " + when + "\n\n" +
+		"Thanks!\n"
+	b, err := ioutil.ReadFile(filepath.Join(dir, "MENTOR_NOTES.md"))
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(b))
+
+	flags.Set("exercise", "bogus-exercise")
+	err = runDownload(cfg, flags, []string{})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "can't be combined")
+	}
+}
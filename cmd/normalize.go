@@ -0,0 +1,85 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+)
+
+// utf8BOM is the byte order mark that some Windows editors put at the
+// start of UTF-8 files.
+var utf8BOM = []byte("\xef\xbb\xbf")
+
+// lineEndingWriter converts CRLF line endings to LF, and drops a leading
+// byte order mark, as it writes. Flush must be called after the last write.
+type lineEndingWriter struct {
+	w io.Writer
+	// head holds the start of the file until we know whether it's a BOM.
+	head       []byte
+	checkedBOM bool
+	// pendingCR is set when a write ends in a carriage return, since the
+	// next write decides whether it's part of a CRLF.
+	pendingCR bool
+}
+
+func newLineEndingWriter(w io.Writer) *lineEndingWriter {
+	return &lineEndingWriter{w: w}
+}
+
+func (lw *lineEndingWriter) Write(p []byte) (int, error) {
+	n := len(p)
+	if !lw.checkedBOM {
+		lw.head = append(lw.head, p...)
+		if len(lw.head) < len(utf8BOM) {
+			return n, nil
+		}
+		p = bytes.TrimPrefix(lw.head, utf8BOM)
+		lw.head = nil
+		lw.checkedBOM = true
+	}
+	return n, lw.write(p)
+}
+
+func (lw *lineEndingWriter) write(p []byte) error {
+	if len(p) == 0 {
+		return nil
+	}
+	buf := make([]byte, 0, len(p)+1)
+	if lw.pendingCR {
+		if p[0] != '\n' {
+			buf = append(buf, '\r')
+		}
+		lw.pendingCR = false
+	}
+	for i, c := range p {
+		if c == '\r' {
+			if i == len(p)-1 {
+				lw.pendingCR = true
+				continue
+			}
+			if p[i+1] == '\n' {
+				continue
+			}
+		}
+		buf = append(buf, c)
+	}
+	_, err := lw.w.Write(buf)
+	return err
+}
+
+// Flush writes what's held back, for files shorter than a BOM or ending
+// in a carriage return.
+func (lw *lineEndingWriter) Flush() error {
+	if !lw.checkedBOM {
+		lw.checkedBOM = true
+		if err := lw.write(bytes.TrimPrefix(lw.head, utf8BOM)); err != nil {
+			return err
+		}
+		lw.head = nil
+	}
+	if lw.pendingCR {
+		lw.pendingCR = false
+		_, err := lw.w.Write([]byte{'\r'})
+		return err
+	}
+	return nil
+}
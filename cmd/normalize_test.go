@@ -0,0 +1,39 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLineEndingWriter(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		chunks   []string
+		expected string
+	}{
+		{"unchanged", []string{"one\ntwo\n"}, "one\ntwo\n"},
+		{"CRLF", []string{"one\r\ntwo\r\n"}, "one\ntwo\n"},
+		{"lone CR", []string{"one\rtwo\r"}, "one\rtwo\r"},
+		{"CRLF across writes", []string{"one\r", "\ntwo\r", "three"}, "one\ntwo\rthree"},
+		{"BOM", []string{"\xef\xbb\xbfone\r\n"}, "one\n"},
+		{"BOM across writes", []string{"\xef", "\xbb", "\xbfone"}, "one"},
+		{"BOM only", []string{"\xef\xbb\xbf"}, ""},
+		{"BOM later on", []string{"one\xef\xbb\xbf"}, "one\xef\xbb\xbf"},
+		{"shorter than a BOM", []string{"a\r"}, "a\r"},
+		{"empty", []string{}, ""},
+	}
+
+	for _, tc := range testCases {
+		var buf bytes.Buffer
+		w := newLineEndingWriter(&buf)
+		for _, chunk := range tc.chunks {
+			n, err := w.Write([]byte(chunk))
+			assert.NoError(t, err, tc.desc)
+			assert.Equal(t, len(chunk), n, tc.desc)
+		}
+		assert.NoError(t, w.Flush(), tc.desc)
+		assert.Equal(t, tc.expected, buf.String(), tc.desc)
+	}
+}
@@ -21,11 +21,15 @@ Pass the path to the directory that contains the solution you want to see on the
 		if err != nil {
 			return err
 		}
-		browser.Open(solution.URL)
+		openBrowser(solution.URL)
 		return nil
 	},
 }
 
+// openBrowser opens a URL in the browser.
+// It's a variable so that tests can stand in for the browser.
+var openBrowser = browser.Open
+
 func init() {
 	RootCmd.AddCommand(openCmd)
 }
@@ -0,0 +1,44 @@
+package cmd
+
+import "sync"
+
+// maxFileWorkers bounds how many files are read at the same time.
+const maxFileWorkers = 8
+
+// forEachConcurrently calls fn with each index from 0 to n-1, making up to
+// workers calls at the same time. Once all the calls have returned, it returns
+// the error of the lowest index that failed, so the outcome doesn't depend on
+// the order in which the calls happened to run.
+func forEachConcurrently(n, workers int, fn func(i int) error) error {
+	if workers < 1 {
+		workers = 1
+	}
+	if workers > n {
+		workers = n
+	}
+
+	indexes := make(chan int)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		indexes <- i
+	}
+	close(indexes)
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachConcurrently(t *testing.T) {
+	var mu sync.Mutex
+	var running, maxRunning int
+	done := make([]bool, 20)
+
+	err := forEachConcurrently(len(done), 3, func(i int) error {
+		mu.Lock()
+		running++
+		if running > maxRunning {
+			maxRunning = running
+		}
+		mu.Unlock()
+
+		time.Sleep(time.Millisecond)
+
+		mu.Lock()
+		running--
+		done[i] = true
+		mu.Unlock()
+		return nil
+	})
+	assert.NoError(t, err)
+	assert.True(t, maxRunning <= 3, fmt.Sprintf("%d calls ran at the same time", maxRunning))
+	for i, ok := range done {
+		assert.True(t, ok, fmt.Sprintf("%d was not called", i))
+	}
+}
+
+func TestForEachConcurrentlyReturnsFirstError(t *testing.T) {
+	err := forEachConcurrently(10, 4, func(i int) error {
+		if i == 3 || i == 7 {
+			return fmt.Errorf("failed %d", i)
+		}
+		return nil
+	})
+	assert.EqualError(t, err, "failed 3")
+
+	assert.NoError(t, forEachConcurrently(0, 4, func(i int) error {
+		t.Fatal("called without any work")
+		return nil
+	}))
+}
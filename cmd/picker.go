@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/exercism/cli/workspace"
+)
+
+// pickSubmitFiles lets people choose which of the files in a directory to
+// submit, starting from the given defaults. It stops waiting when ctx is done.
+func pickSubmitFiles(ctx context.Context, dir string, defaults []string, dereference bool) ([]string, error) {
+	if !canPrompt() {
+		return nil, errors.New("--pick asks which files to submit, but the input is not interactive. Name the files instead")
+	}
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	all, err := workspace.CollectFiles(dir, dereference)
+	if err != nil {
+		return nil, err
+	}
+	candidates := make([]string, 0, len(all))
+	for _, file := range all {
+		if !workspace.IsEditorFile(file) {
+			candidates = append(candidates, file)
+		}
+	}
+	sort.Strings(candidates)
+
+	// The defaults may have been found through a symlink, e.g. to the exercise
+	// directory, so they are compared with the candidates once resolved.
+	resolve := func(file string) string {
+		if resolved, err := filepath.EvalSymlinks(file); err == nil {
+			return resolved
+		}
+		return file
+	}
+	wanted := make(map[string]bool, len(defaults))
+	for _, file := range defaults {
+		wanted[resolve(file)] = true
+	}
+	selected := make(map[string]bool, len(defaults))
+	for _, file := range candidates {
+		if wanted[resolve(file)] {
+			selected[file] = true
+		}
+	}
+	return newPrompter(In, Err).withContext(ctx).pickFiles(dir, candidates, selected)
+}
+
+// pickFiles lists files with their sizes, and lets people toggle them by
+// number until they accept the selection with an empty answer.
+func (p *prompter) pickFiles(dir string, files []string, selected map[string]bool) ([]string, error) {
+	if len(files) == 0 {
+		return nil, fmt.Errorf("there are no files to choose from in %s", dir)
+	}
+	for {
+		fmt.Fprintf(p.out, "\n    Choose the files to submit:\n\n")
+		tw := tabwriter.NewWriter(p.out, 0, 0, 2, ' ', 0)
+		for i, file := range files {
+			mark := " "
+			if selected[file] {
+				mark = "x"
+			}
+			rel, err := filepath.Rel(dir, file)
+			if err != nil {
+				return nil, err
+			}
+			size := "?"
+			if info, err := os.Stat(file); err == nil {
+				size = formatByteSize(info.Size())
+			}
+			fmt.Fprintf(tw, "      [%s] %d\t%s\t%s\n", mark, i+1, filepath.ToSlash(rel), size)
+		}
+		if err := tw.Flush(); err != nil {
+			return nil, err
+		}
+		fmt.Fprintln(p.out)
+
+		answer, err := p.ask("Toggle files by number (e.g. 1 3), a for all, n for none, or press Enter to submit the selected files", "")
+		if err != nil {
+			return nil, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			var picked []string
+			for _, file := range files {
+				if selected[file] {
+					picked = append(picked, file)
+				}
+			}
+			if len(picked) > 0 {
+				return picked, nil
+			}
+			fmt.Fprintln(p.out, "Please select at least one file.")
+			continue
+		case "a", "all":
+			for _, file := range files {
+				selected[file] = true
+			}
+			continue
+		case "n", "none":
+			selected = map[string]bool{}
+			continue
+		}
+
+		numbers := strings.FieldsFunc(answer, func(r rune) bool { return r == ' ' || r == ',' })
+		var toggle []int
+		for _, s := range numbers {
+			n, err := strconv.Atoi(s)
+			if err != nil || n < 1 || n > len(files) {
+				toggle = nil
+				break
+			}
+			toggle = append(toggle, n-1)
+		}
+		if len(toggle) == 0 {
+			fmt.Fprintf(p.out, "Please enter file numbers between 1 and %d.\n", len(files))
+			continue
+		}
+		for _, i := range toggle {
+			selected[files[i]] = !selected[files[i]]
+		}
+	}
+}
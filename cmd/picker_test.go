@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickFiles(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "pick-files")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	var files []string
+	for _, name := range []string{"a.go", "a_test.go", "notes.md"} {
+		file := filepath.Join(tmpDir, name)
+		err = ioutil.WriteFile(file, []byte("content"), os.FileMode(0644))
+		assert.NoError(t, err)
+		files = append(files, file)
+	}
+
+	testCases := []struct {
+		desc     string
+		input    string
+		expected []string
+	}{
+		{
+			desc:     "accepts the defaults",
+			input:    "\n",
+			expected: []string{files[0]},
+		},
+		{
+			desc:     "toggles files by number",
+			input:    "1 3\n\n",
+			expected: []string{files[2]},
+		},
+		{
+			desc:     "selects all files",
+			input:    "a\n\n",
+			expected: files,
+		},
+		{
+			desc:     "asks again for an empty selection or bad numbers",
+			input:    "n\n\n4\nx\n2,3\n\n",
+			expected: []string{files[1], files[2]},
+		},
+	}
+
+	for _, tc := range testCases {
+		out := &bytes.Buffer{}
+		p := newPrompter(strings.NewReader(tc.input), out)
+		picked, err := p.pickFiles(tmpDir, files, map[string]bool{files[0]: true})
+		assert.NoError(t, err, tc.desc)
+		assert.Equal(t, tc.expected, picked, tc.desc)
+	}
+
+	out := &bytes.Buffer{}
+	p := newPrompter(strings.NewReader("n\n\n4\n"), out)
+	_, err = p.pickFiles(tmpDir, files, map[string]bool{files[0]: true})
+	assert.Error(t, err)
+	assert.Contains(t, out.String(), "[x] 1  a.go")
+	assert.Contains(t, out.String(), "Please select at least one file.")
+	assert.Contains(t, out.String(), "Please enter file numbers between 1 and 3.")
+}
+
+func TestPickSubmitFilesThroughSymlink(t *testing.T) {
+	oldIn := In
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() {
+		In = oldIn
+		Err = oldErr
+	}()
+
+	tmpDir, err := ioutil.TempDir("", "pick-symlink")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+	tmpDir, err = filepath.EvalSymlinks(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	for _, name := range []string{"a.go", "a_test.go"} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte("content"), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+	link := filepath.Join(tmpDir, "link")
+	if err := os.Symlink(dir, link); err != nil {
+		t.Skipf("unable to create a symlink: %s", err)
+	}
+
+	// The defaults are named through the symlink, as when the exercise
+	// directory is reached through it.
+	In = strings.NewReader("\n")
+	picked, err := pickSubmitFiles(context.Background(), link, []string{filepath.Join(link, "a.go")}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(dir, "a.go")}, picked)
+}
+
+func TestPrompterStopsWhenCancelled(t *testing.T) {
+	files := []string{filepath.Join("dir", "a.go")}
+	prompts := map[string]func(p *prompter) error{
+		"confirm": func(p *prompter) error {
+			_, err := p.confirm("Submit them anyway?", false)
+			return err
+		},
+		"pick files": func(p *prompter) error {
+			_, err := p.pickFiles("dir", files, map[string]bool{})
+			return err
+		},
+	}
+	for desc, prompt := range prompts {
+		// Nothing is ever typed.
+		r, w := io.Pipe()
+		ctx, cancel := context.WithCancel(context.Background())
+		p := newPrompter(r, ioutil.Discard).withContext(ctx)
+
+		done := make(chan error, 1)
+		go func() {
+			done <- prompt(p)
+		}()
+		time.Sleep(10 * time.Millisecond)
+		cancel()
+
+		select {
+		case err := <-done:
+			assert.Equal(t, errInterrupted, err, desc)
+		case <-time.After(time.Second):
+			t.Errorf("%s: still waiting for an answer after being cancelled", desc)
+		}
+		w.Close()
+	}
+}
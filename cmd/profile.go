@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// profileCmd manages the configuration profiles.
+var profileCmd = &cobra.Command{
+	Use:   "profile",
+	Short: "List the configuration profiles, or switch the default one.",
+	Long: `List the configuration profiles, or switch the default one.
+
+Each profile has its own token, workspace, and API base URL, so that
+the CLI can be used with more than one site. Set one up with
+
+    configure --profile NAME
+
+and use it with --profile NAME on any command. The default profile
+is used when no profile is given.
+`,
+}
+
+var profileListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List the configuration profiles.",
+	Args:  cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		format, err := cmd.Flags().GetString("format")
+		if err != nil {
+			return err
+		}
+		return runProfileList(config.NewConfig().Dir, format)
+	},
+}
+
+var profileUseCmd = &cobra.Command{
+	Use:   "use NAME",
+	Short: "Make a configuration profile the default one.",
+	Args:  cobra.ExactArgs(1),
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runProfileUse(config.NewConfig().Dir, args[0])
+	},
+}
+
+// profileEntry describes a configuration profile.
+type profileEntry struct {
+	Name       string `json:"name" yaml:"name"`
+	Default    bool   `json:"default" yaml:"default"`
+	APIBaseURL string `json:"apibaseurl" yaml:"apibaseurl"`
+	Workspace  string `json:"workspace" yaml:"workspace"`
+}
+
+type profileEntries []profileEntry
+
+// Columns implements tabular.
+func (e profileEntries) Columns() []string {
+	return []string{"", "name", "apibaseurl", "workspace"}
+}
+
+// Rows implements tabular.
+func (e profileEntries) Rows() [][]string {
+	rows := make([][]string, 0, len(e))
+	for _, entry := range e {
+		marker := ""
+		if entry.Default {
+			marker = "*"
+		}
+		rows = append(rows, []string{marker, entry.Name, entry.APIBaseURL, entry.Workspace})
+	}
+	return rows
+}
+
+// runProfileList lists the profiles in the config dir root,
+// marking the default one.
+func runProfileList(root, format string) error {
+	f, err := newFormatter(format)
+	if err != nil {
+		return err
+	}
+	names, err := config.Profiles(root)
+	if err != nil {
+		return err
+	}
+	defaultProfile := config.DefaultProfile(root)
+
+	entries := profileEntries{}
+	for _, name := range names {
+		dir := config.ProfileDir(root, name)
+		v := viper.New()
+		readConfigFile(v, dir, "user")
+		entries = append(entries, profileEntry{
+			Name:       name,
+			Default:    name == defaultProfile,
+			APIBaseURL: v.GetString("apibaseurl"),
+			Workspace:  v.GetString("workspace"),
+		})
+	}
+	return f.Format(Out, entries)
+}
+
+// runProfileUse makes the named profile the default one in the config dir root.
+func runProfileUse(root, name string) error {
+	if err := config.ValidateProfileName(name); err != nil {
+		return err
+	}
+	if !config.ProfileExists(root, name) {
+		msg := `
+
+    There is no profile named '%s'. To set it up, call
+
+        %s configure --profile %s
+
+`
+		return fmt.Errorf(msg, name, BinaryName, name)
+	}
+	if err := config.SetDefaultProfile(root, name); err != nil {
+		return err
+	}
+	fmt.Fprintf(Err, "\nThe '%s' profile is now the default.\n\n", name)
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(profileCmd)
+	profileCmd.AddCommand(profileListCmd)
+	profileCmd.AddCommand(profileUseCmd)
+	setupFormatFlag(profileListCmd.Flags(), "table")
+}
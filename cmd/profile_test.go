@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/exercism/cli/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProfileListAndUse(t *testing.T) {
+	oldOut, oldErr := Out, Err
+	defer func() {
+		Out, Err = oldOut, oldErr
+	}()
+
+	root, err := ioutil.TempDir("", "profile-cmd")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "user.json"), []byte(`{"apibaseurl":"https://api.example.com/v1","workspace":"/home/alice/exercism"}`), os.FileMode(0644)))
+	workDir := config.ProfileDir(root, "work")
+	assert.NoError(t, os.MkdirAll(workDir, os.FileMode(0755)))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(workDir, "user.json"), []byte(`{"apibaseurl":"https://exercism.work.example/api/v1","workspace":"/home/alice/work"}`), os.FileMode(0644)))
+
+	Err = ioutil.Discard
+	var buf bytes.Buffer
+	Out = &buf
+
+	err = runProfileUse(root, "home")
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "configure --profile home")
+	}
+	assert.NoError(t, runProfileUse(root, "work"))
+	assert.Equal(t, "work", config.DefaultProfile(root))
+
+	assert.NoError(t, runProfileList(root, "table"))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if assert.Len(t, lines, 3) {
+		assert.Regexp(t, `^\s+default\s+https://api.example.com/v1\s+/home/alice/exercism$`, lines[1])
+		assert.Regexp(t, `^\*\s+work\s+https://exercism.work.example/api/v1\s+/home/alice/work$`, lines[2])
+	}
+}
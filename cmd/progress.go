@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// progressInterval is how often the progress is redrawn. Uploads that finish
+// within the first interval don't show any progress at all.
+var progressInterval = 200 * time.Millisecond
+
+// progressBarWidth is the number of characters in the bar itself.
+const progressBarWidth = 30
+
+// progressReader draws a progress bar for a body as it is read.
+// The body is read by the HTTP client in its own goroutine,
+// so the drawing is guarded by a mutex.
+type progressReader struct {
+	r     io.Reader
+	w     io.Writer
+	total int64
+
+	mu      sync.Mutex
+	read    int64
+	started time.Time
+	drawn   time.Time
+	stopped bool
+}
+
+// newProgressReader wraps r so that the bytes read out of total are drawn on w.
+func newProgressReader(r io.Reader, w io.Writer, total int64) *progressReader {
+	return &progressReader{r: r, w: w, total: total}
+}
+
+func (p *progressReader) Read(b []byte) (int, error) {
+	n, err := p.r.Read(b)
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.read += int64(n)
+	now := time.Now()
+	if p.started.IsZero() {
+		p.started = now
+	}
+	if !p.stopped && now.Sub(p.started) >= progressInterval && now.Sub(p.drawn) >= progressInterval {
+		fmt.Fprintf(p.w, "\r%s", formatProgress(p.read, p.total))
+		p.drawn = now
+	}
+	return n, err
+}
+
+// Stop erases the progress bar, if it was drawn, and stops drawing it.
+func (p *progressReader) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if !p.drawn.IsZero() && !p.stopped {
+		fmt.Fprintf(p.w, "\r%s\r", strings.Repeat(" ", len(formatProgress(p.total, p.total))))
+	}
+	p.stopped = true
+}
+
+// formatProgress describes how far along an upload is, with a bar and the bytes sent.
+func formatProgress(read, total int64) string {
+	return fmt.Sprintf("    Uploading [%s] %9s of %s", formatBar(read, total), formatByteSize(read), formatByteSize(total))
+}
+
+// formatBar fills a bar in proportion to how much of the total is done.
+func formatBar(done, total int64) string {
+	filled := progressBarWidth
+	if total > 0 && done < total {
+		filled = int(done * progressBarWidth / total)
+	}
+	bar := strings.Repeat("=", filled)
+	if filled < progressBarWidth {
+		bar += ">" + strings.Repeat(" ", progressBarWidth-filled-1)
+	}
+	return bar
+}
+
+// downloadProgress draws how many of the files of a download have arrived,
+// and reports the ones that failed as soon as they do. The files are
+// fetched concurrently, so the drawing is guarded by a mutex.
+// A nil downloadProgress draws nothing.
+type downloadProgress struct {
+	w     io.Writer
+	total int
+
+	mu    sync.Mutex
+	done  int
+	drawn bool
+}
+
+// newDownloadProgress draws the progress of downloading total files on w.
+func newDownloadProgress(w io.Writer, total int) *downloadProgress {
+	p := &downloadProgress{w: w, total: total}
+	p.draw()
+	return p
+}
+
+// add records that a file is done. If it failed, err says why.
+func (p *downloadProgress) add(file string, err error) {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.done++
+	if err != nil {
+		p.erase()
+		fmt.Fprintf(p.w, "    Unable to download %s - %s\n", file, err)
+	}
+	p.draw()
+}
+
+// Stop erases the progress bar.
+func (p *downloadProgress) Stop() {
+	if p == nil {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.erase()
+}
+
+func (p *downloadProgress) draw() {
+	fmt.Fprintf(p.w, "\r%s", formatDownloadProgress(p.done, p.total))
+	p.drawn = true
+}
+
+func (p *downloadProgress) erase() {
+	if p.drawn {
+		fmt.Fprintf(p.w, "\r%s\r", strings.Repeat(" ", len(formatDownloadProgress(p.total, p.total))))
+		p.drawn = false
+	}
+}
+
+// formatDownloadProgress describes how many of the files of a download have arrived.
+func formatDownloadProgress(done, total int) string {
+	return fmt.Sprintf("    Downloading [%s] %3d of %d files", formatBar(int64(done), int64(total)), done, total)
+}
+
+// isTerminal determines whether output goes to a terminal.
+// Writers that aren't files, such as buffers, don't count.
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
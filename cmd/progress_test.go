@@ -0,0 +1,83 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatProgress(t *testing.T) {
+	assert.Equal(t, "    Uploading [>                             ]   0 bytes of 2.0 KB", formatProgress(0, 2048))
+	assert.Equal(t, "    Uploading [===============>              ]    1.0 KB of 2.0 KB", formatProgress(1024, 2048))
+	assert.Equal(t, "    Uploading [==============================]    2.0 KB of 2.0 KB", formatProgress(2048, 2048))
+}
+
+func TestProgressReader(t *testing.T) {
+	oldInterval := progressInterval
+	defer func() { progressInterval = oldInterval }()
+
+	contents := strings.Repeat("x", 2048)
+
+	// Quick uploads don't show anything.
+	var out bytes.Buffer
+	progress := newProgressReader(strings.NewReader(contents), &out, int64(len(contents)))
+	_, err := ioutil.ReadAll(progress)
+	assert.NoError(t, err)
+	progress.Stop()
+	assert.Equal(t, "", out.String())
+
+	progressInterval = 0
+	out.Reset()
+	progress = newProgressReader(strings.NewReader(contents), &out, int64(len(contents)))
+	b, err := ioutil.ReadAll(progress)
+	assert.NoError(t, err)
+	assert.Equal(t, contents, string(b))
+	assert.Contains(t, out.String(), "\r    Uploading [")
+	assert.Contains(t, out.String(), "2.0 KB of 2.0 KB")
+
+	// Stopping erases the bar, and nothing is drawn after that.
+	progress.Stop()
+	assert.True(t, strings.HasSuffix(out.String(), "\r"+strings.Repeat(" ", len(formatProgress(2048, 2048)))+"\r"))
+	drawn := out.Len()
+	progress.Read(make([]byte, 1))
+	assert.Equal(t, drawn, out.Len())
+}
+
+func TestDownloadProgress(t *testing.T) {
+	assert.Equal(t, "    Downloading [===============>              ]   1 of 2 files", formatDownloadProgress(1, 2))
+
+	var out bytes.Buffer
+	progress := newDownloadProgress(&out, 2)
+	assert.Equal(t, "\r"+formatDownloadProgress(0, 2), out.String())
+
+	// Failures are reported on a line of their own, and the bar is drawn again below.
+	out.Reset()
+	progress.add("file-1.txt", errors.New("connection reset"))
+	blank := "\r" + strings.Repeat(" ", len(formatDownloadProgress(2, 2))) + "\r"
+	assert.Equal(t, blank+"    Unable to download file-1.txt - connection reset\n\r"+formatDownloadProgress(1, 2), out.String())
+
+	out.Reset()
+	progress.add("file-2.txt", nil)
+	progress.Stop()
+	assert.Equal(t, "\r"+formatDownloadProgress(2, 2)+blank, out.String())
+
+	// Without progress, there's nothing to draw.
+	var none *downloadProgress
+	none.add("file-1.txt", nil)
+	none.Stop()
+}
+
+func TestIsTerminal(t *testing.T) {
+	assert.False(t, isTerminal(&bytes.Buffer{}))
+
+	f, err := ioutil.TempFile("", "is-terminal")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+	assert.False(t, isTerminal(f))
+}
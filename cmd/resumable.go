@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/debug"
+	"github.com/exercism/cli/workspace"
+)
+
+var (
+	// resumableThreshold is the size from which submissions are sent in
+	// chunks, if the API supports it. Smaller ones are quick to send again.
+	resumableThreshold int64 = 8 << 20
+	// uploadChunkSize is how much of a submission is sent per request.
+	uploadChunkSize int64 = 1 << 20
+)
+
+// uploadsFilename holds the state of unfinished uploads in the config dir.
+const uploadsFilename = "uploads.json"
+
+// uploadState is what's needed to resume an upload, keyed by solution ID.
+// The boundary is kept so that the body can be built exactly as before,
+// and the digest tells whether it still is the same body.
+type uploadState struct {
+	ID       string `json:"id"`
+	Boundary string `json:"boundary"`
+	Digest   string `json:"digest"`
+	Size     int64  `json:"size"`
+}
+
+func readUploadStates(dir string) map[string]uploadState {
+	states := map[string]uploadState{}
+	if dir == "" {
+		return states
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, uploadsFilename))
+	if err != nil {
+		return states
+	}
+	if err := json.Unmarshal(b, &states); err != nil {
+		return map[string]uploadState{}
+	}
+	return states
+}
+
+func writeUploadStates(dir string, states map[string]uploadState) error {
+	if dir == "" {
+		return nil
+	}
+	path := filepath.Join(dir, uploadsFilename)
+	if len(states) == 0 {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	b, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+	return config.WriteFileAtomic(path, b, os.FileMode(0600))
+}
+
+// digest measures the body for a solution, and computes its SHA-256.
+func (b submitBody) digest(solution *workspace.Solution) (int64, string, error) {
+	h := sha256.New()
+	var n countingWriter
+	if err := b.write(io.MultiWriter(h, &n), solution); err != nil {
+		return 0, "", err
+	}
+	return int64(n), hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// resumableUpload sends a submission in chunks. If the upload fails part
+// way, its state is kept in the config dir, so that the next attempt to
+// submit the same files continues where it left off.
+type resumableUpload struct {
+	client *api.Client
+	// url is the submission URL. Uploads are created under it.
+	url      string
+	solution *workspace.Solution
+	body     submitBody
+	stateDir string
+	policy   api.RetryPolicy
+	// wrap, if set, wraps the body as it's read, e.g. to throttle it.
+	wrap func(r io.Reader, offset, size int64) io.Reader
+	// saved tells whether the state of the upload is kept, so that it can
+	// be resumed if it fails.
+	saved bool
+}
+
+// uploadProgress is how far along an upload is, according to the API.
+type uploadProgress struct {
+	Upload struct {
+		ID     string `json:"id"`
+		Offset int64  `json:"offset"`
+	} `json:"upload"`
+}
+
+// send uploads the body, and returns the API's response to the final chunk,
+// which is its response to the submission.
+func (u *resumableUpload) send(ctx context.Context) (*http.Response, error) {
+	states := readUploadStates(u.stateDir)
+	state, offset, ok := u.resume(ctx, states[u.solution.ID])
+	if ok {
+		u.saved = true
+		fmt.Fprintf(Err, "\n    Resuming the upload from %s of %s.\n\n", formatByteSize(offset), formatByteSize(state.Size))
+	} else {
+		var err error
+		if state, err = u.start(ctx); err != nil {
+			return nil, err
+		}
+		states[u.solution.ID] = state
+		if err := writeUploadStates(u.stateDir, states); err != nil {
+			debug.Printf("Unable to keep the upload state: %s\n", err)
+		} else {
+			u.saved = u.stateDir != ""
+		}
+	}
+	body := u.body
+	body.boundary = state.Boundary
+
+	// open reads the body from the offset on. It's opened again when the
+	// API has less of the body than was sent.
+	var stream io.ReadCloser
+	var r io.Reader
+	open := func(offset int64) error {
+		if stream != nil {
+			stream.Close()
+		}
+		stream = body.stream(u.solution)
+		if _, err := io.CopyN(ioutil.Discard, stream, offset); err != nil {
+			return err
+		}
+		r = stream
+		if u.wrap != nil {
+			r = u.wrap(r, offset, state.Size)
+		}
+		return nil
+	}
+	defer func() {
+		if stream != nil {
+			stream.Close()
+		}
+	}()
+	if err := open(offset); err != nil {
+		return nil, err
+	}
+
+	url := fmt.Sprintf("%s/uploads/%s", u.url, state.ID)
+	chunk := make([]byte, uploadChunkSize)
+	for {
+		n, err := io.ReadFull(r, chunk)
+		if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+			return nil, err
+		}
+		contentRange := fmt.Sprintf("bytes %d-%d/%d", offset, offset+int64(n)-1, state.Size)
+		if n == 0 {
+			// Everything was sent before, but the response to it wasn't received.
+			contentRange = fmt.Sprintf("bytes */%d", state.Size)
+		}
+		data := chunk[:n]
+		newRequest := func() (*http.Request, error) {
+			req, err := u.client.NewRequest("PUT", url, bytes.NewReader(data))
+			if err != nil {
+				return nil, err
+			}
+			req.Header.Set("Content-Type", "application/octet-stream")
+			req.Header.Set("Content-Range", contentRange)
+			return req.WithContext(ctx), nil
+		}
+		// A chunk says where it goes, so the API can tell one it already has.
+		// Only the last one, which completes the submission, can't be resent.
+		sent := offset + int64(n)
+		policy := u.policy
+		policy.OnlyUnsent = sent >= state.Size
+		resp, err := u.client.DoWithRetry(newRequest, policy)
+		if err != nil {
+			return nil, err
+		}
+
+		if sent >= state.Size {
+			// The upload is over, whether the submission was accepted or not.
+			delete(states, u.solution.ID)
+			if err := writeUploadStates(u.stateDir, states); err != nil {
+				debug.Printf("Unable to clear the upload state: %s\n", err)
+			}
+			u.saved = false
+			return resp, nil
+		}
+		if resp.StatusCode == http.StatusNotFound || resp.StatusCode == http.StatusGone {
+			resp.Body.Close()
+			delete(states, u.solution.ID)
+			writeUploadStates(u.stateDir, states)
+			u.saved = false
+			return nil, fmt.Errorf("the upload has expired (API returned %s). Submit again to start over", resp.Status)
+		}
+		if resp.StatusCode >= 400 {
+			resp.Body.Close()
+			return nil, fmt.Errorf("API returned %s while uploading", resp.Status)
+		}
+
+		// Carry on from where the API says it got to, which may be short
+		// of what was sent.
+		var progress uploadProgress
+		err = json.NewDecoder(resp.Body).Decode(&progress)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse API response - %s", err)
+		}
+		if progress.Upload.Offset <= offset || progress.Upload.Offset > sent {
+			return nil, fmt.Errorf("the API has %d bytes of the upload after %d were sent", progress.Upload.Offset, sent)
+		}
+		if progress.Upload.Offset < sent {
+			debug.Printf("Sending the upload again from %d bytes, where the API got to\n", progress.Upload.Offset)
+			if err := open(progress.Upload.Offset); err != nil {
+				return nil, err
+			}
+		}
+		offset = progress.Upload.Offset
+	}
+}
+
+// start asks the API for a new upload.
+func (u *resumableUpload) start(ctx context.Context) (uploadState, error) {
+	size, digest, err := u.body.digest(u.solution)
+	if err != nil {
+		return uploadState{}, err
+	}
+	contentType, err := submitContentType(u.body.boundary)
+	if err != nil {
+		return uploadState{}, err
+	}
+	params := map[string]interface{}{
+		"size":         size,
+		"digest":       "sha256:" + digest,
+		"content_type": contentType,
+	}
+	if u.body.gzipped {
+		params["content_encoding"] = "gzip"
+	}
+	b, err := json.Marshal(params)
+	if err != nil {
+		return uploadState{}, err
+	}
+
+	req, err := u.client.NewRequest("POST", u.url+"/uploads", bytes.NewReader(b))
+	if err != nil {
+		return uploadState{}, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := u.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return uploadState{}, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
+		return uploadState{}, fmt.Errorf("unable to start the upload: API returned %s", resp.Status)
+	}
+	var progress uploadProgress
+	if err := json.NewDecoder(resp.Body).Decode(&progress); err != nil {
+		return uploadState{}, fmt.Errorf("unable to parse API response - %s", err)
+	}
+	if progress.Upload.ID == "" {
+		return uploadState{}, errors.New("unable to start the upload: the API didn't identify it")
+	}
+	return uploadState{ID: progress.Upload.ID, Boundary: u.body.boundary, Digest: digest, Size: size}, nil
+}
+
+// resume determines whether an unfinished upload can be continued, and if so,
+// from where. It can't if the files changed since, or the API lost track of it.
+func (u *resumableUpload) resume(ctx context.Context, state uploadState) (uploadState, int64, bool) {
+	if state.ID == "" {
+		return state, 0, false
+	}
+	body := u.body
+	body.boundary = state.Boundary
+	size, digest, err := body.digest(u.solution)
+	if err != nil || size != state.Size || digest != state.Digest {
+		debug.Println("Starting the upload over, because the submission changed since it was interrupted")
+		return state, 0, false
+	}
+
+	req, err := u.client.NewRequest("GET", fmt.Sprintf("%s/uploads/%s", u.url, state.ID), nil)
+	if err != nil {
+		return state, 0, false
+	}
+	resp, err := u.client.Do(req.WithContext(ctx))
+	if err != nil {
+		debug.Printf("Starting the upload over, because its progress is unknown: %s\n", err)
+		return state, 0, false
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		debug.Printf("Starting the upload over, because the API returned %s for it\n", resp.Status)
+		return state, 0, false
+	}
+	var progress uploadProgress
+	if err := json.NewDecoder(resp.Body).Decode(&progress); err != nil {
+		return state, 0, false
+	}
+	if progress.Upload.Offset < 0 || progress.Upload.Offset > state.Size {
+		return state, 0, false
+	}
+	return state, progress.Upload.Offset, true
+}
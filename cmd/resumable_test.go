@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSubmitResumableUpload(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldThreshold := resumableThreshold
+	oldChunkSize := uploadChunkSize
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		resumableThreshold = oldThreshold
+		uploadChunkSize = oldChunkSize
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+	resumableThreshold = 0
+	uploadChunkSize = 1024
+
+	var (
+		starts      int
+		contentType string
+		received    []byte
+		failAt      = 3
+		chunks      int
+		files       map[string]string
+		// shortAt is the chunk of which the API only keeps a part.
+		shortAt     int
+		startStatus = http.StatusCreated
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/capabilities":
+			fmt.Fprint(w, `{"capabilities": {"chunked_upload": true}}`)
+		case r.Method == "POST" && r.URL.Path == "/solutions/bogus-solution-uuid/uploads":
+			starts++
+			if startStatus != http.StatusCreated {
+				w.WriteHeader(startStatus)
+				return
+			}
+			var params map[string]interface{}
+			err := json.NewDecoder(r.Body).Decode(&params)
+			assert.NoError(t, err)
+			contentType = params["content_type"].(string)
+			received = nil
+			fmt.Fprint(w, `{"upload": {"id": "upload-1", "offset": 0}}`)
+		case r.Method == "GET" && r.URL.Path == "/solutions/bogus-solution-uuid/uploads/upload-1":
+			fmt.Fprintf(w, `{"upload": {"id": "upload-1", "offset": %d}}`, len(received))
+		case r.Method == "PUT" && r.URL.Path == "/solutions/bogus-solution-uuid/uploads/upload-1":
+			chunks++
+			if chunks == failAt {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			var start, end, total int
+			_, err := fmt.Sscanf(r.Header.Get("Content-Range"), "bytes %d-%d/%d", &start, &end, &total)
+			assert.NoError(t, err)
+			assert.Equal(t, len(received), start)
+			b, err := ioutil.ReadAll(r.Body)
+			assert.NoError(t, err)
+			assert.Equal(t, end-start+1, len(b))
+			if chunks == shortAt {
+				b = b[:100]
+			}
+			received = append(received, b...)
+			if len(received) < total {
+				fmt.Fprintf(w, `{"upload": {"id": "upload-1", "offset": %d}}`, len(received))
+				return
+			}
+
+			// The upload is complete, so it's a submission like any other.
+			_, params, err := mime.ParseMediaType(contentType)
+			assert.NoError(t, err)
+			reader := multipart.NewReader(bytes.NewReader(received), params["boundary"])
+			files = map[string]string{}
+			for {
+				p, err := reader.NextPart()
+				if err == io.EOF {
+					break
+				}
+				assert.NoError(t, err)
+				b, err := ioutil.ReadAll(p)
+				assert.NoError(t, err)
+				if p.FormName() == "files[]" {
+					files[p.FileName()] = string(b)
+				}
+			}
+			fmt.Fprint(w, `{}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-resumable")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	contents := strings.Repeat("This is a large file.\n", 300)
+	file := filepath.Join(dir, "large.txt")
+	err = ioutil.WriteFile(file, []byte(contents), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Dir:             filepath.Join(tmpDir, "config"),
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func() error {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		flags.Set("retries", "0")
+		return runSubmit(context.Background(), cfg, flags, []string{file})
+	}
+
+	// The upload breaks off part way, and its state is kept.
+	err = submit()
+	if assert.Error(t, err) {
+		assert.Regexp(t, "500", err.Error())
+	}
+	assert.Regexp(t, "resume the upload", errBuf.String())
+	assert.Equal(t, 2048, len(received))
+	_, err = os.Stat(filepath.Join(cfg.Dir, uploadsFilename))
+	assert.NoError(t, err)
+
+	// The next attempt continues where it stopped.
+	errBuf.Reset()
+	err = submit()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, starts)
+	assert.Regexp(t, "Resuming the upload from 2.0 KB", errBuf.String())
+	assert.Equal(t, map[string]string{"large.txt": contents}, files)
+	_, err = os.Stat(filepath.Join(cfg.Dir, uploadsFilename))
+	assert.True(t, os.IsNotExist(err))
+
+	// An upload isn't resumed once the files have changed.
+	chunks, failAt = 0, 2
+	err = ioutil.WriteFile(file, []byte(contents+"More.\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = submit()
+	assert.Error(t, err)
+
+	errBuf.Reset()
+	failAt = 0
+	err = ioutil.WriteFile(file, []byte(contents+"Even more.\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = submit()
+	assert.NoError(t, err)
+	assert.Equal(t, 3, starts)
+	assert.NotRegexp(t, "Resuming", errBuf.String())
+	assert.Equal(t, map[string]string{"large.txt": contents + "Even more.\n"}, files)
+
+	// The rest of a chunk the API only took part of is sent again.
+	chunks, shortAt = 0, 2
+	err = ioutil.WriteFile(file, []byte(contents+"Yet more.\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = submit()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"large.txt": contents + "Yet more.\n"}, files)
+
+	// An upload that couldn't be started can't be resumed either.
+	errBuf.Reset()
+	startStatus = http.StatusInternalServerError
+	err = ioutil.WriteFile(file, []byte(contents+"Last.\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = submit()
+	assert.Error(t, err)
+	assert.NotRegexp(t, "resume the upload", errBuf.String())
+}
+
+func TestUploadStates(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "upload-states")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	states := map[string]uploadState{"abc": {ID: "upload-1", Boundary: "xyz", Digest: "123", Size: 10}}
+	err = writeUploadStates(tmpDir, states)
+	assert.NoError(t, err)
+	assert.Equal(t, states, readUploadStates(tmpDir))
+
+	err = writeUploadStates(tmpDir, map[string]uploadState{})
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(tmpDir, uploadsFilename))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -26,6 +26,13 @@ var (
 	Err io.Writer
 	// In is used to provide mocked test input (i.e. for prompts).
 	In io.Reader
+
+	// configSource is where to read the user config from, if not the config dir.
+	// A dash means standard input.
+	configSource string
+
+	// profileName picks the configuration profile, if not the default one.
+	profileName string
 )
 
 // RootCmd represents the base command when called without any subcommands.
@@ -36,7 +43,7 @@ var RootCmd = &cobra.Command{
 
 Download exercises and submit your solutions.`,
 	SilenceUsage: true,
-	PersistentPreRun: func(cmd *cobra.Command, args []string) {
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
 		if verbose, _ := cmd.Flags().GetBool("verbose"); verbose {
 			debug.Verbose = verbose
 		}
@@ -44,12 +51,34 @@ Download exercises and submit your solutions.`,
 			cli.TimeoutInSeconds = timeout
 			api.TimeoutInSeconds = timeout
 		}
+		if timeout, _ := cmd.Flags().GetInt("connect-timeout"); timeout > 0 {
+			api.ConnectTimeoutInSeconds = timeout
+		}
+		if timeout, _ := cmd.Flags().GetInt("tls-timeout"); timeout > 0 {
+			api.TLSTimeoutInSeconds = timeout
+		}
+
+		// The commands that need the config explain what's wrong with it.
+		cfg, err := loadUserConfig()
+		if err != nil {
+			debug.Printf("Not applying the user config: %s\n", err)
+			return nil
+		}
+		err = applyUserSettings(cfg)
+		// The config command has to work despite a bad setting, so that it can fix it.
+		if err != nil && (cmd == configCmd || cmd.Parent() == configCmd) {
+			return nil
+		}
+		return err
 	},
 }
 
 // Execute adds all child commands to the root command.
 func Execute() {
 	if err := RootCmd.Execute(); err != nil {
+		if err == errInterrupted {
+			os.Exit(exitCodeInterrupted)
+		}
 		os.Exit(-1)
 	}
 }
@@ -61,6 +90,11 @@ func init() {
 	Err = os.Stderr
 	In = os.Stdin
 	api.UserAgent = fmt.Sprintf("github.com/exercism/cli v%s (%s/%s)", Version, runtime.GOOS, runtime.GOARCH)
+	RootCmd.SetGlobalNormalizationFunc(normalizeRenamedFlags)
 	RootCmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
+	RootCmd.PersistentFlags().StringVar(&configSource, "config", "", "read the user config as JSON from this file instead of the config dir, or from standard input with -")
+	RootCmd.PersistentFlags().StringVar(&profileName, "profile", "", "use the settings of this configuration profile instead of the default one")
 	RootCmd.PersistentFlags().IntP("timeout", "", 0, "override the default HTTP timeout (seconds)")
+	RootCmd.PersistentFlags().IntP("connect-timeout", "", 0, "override the default timeout for connecting to the API (seconds)")
+	RootCmd.PersistentFlags().IntP("tls-timeout", "", 0, "override the default timeout for the TLS handshake (seconds)")
 }
@@ -0,0 +1,208 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/workspace"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/spf13/viper"
+)
+
+// selftestCmd checks that submissions are built correctly, without using the network.
+var selftestCmd = &cobra.Command{
+	Use:   "selftest",
+	Short: "Check that this build can prepare submissions.",
+	Long: `Check that this build of the command-line client can prepare submissions.
+
+This creates a fake exercise in a temporary directory, takes its files
+through the same steps as the submit command, up to the upload, and then
+reads the request body back to make sure that every file arrives intact
+and under the right name.
+
+Nothing is sent over the network, and your workspace is not touched.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		return runSelftest(Out)
+	},
+}
+
+// selftestFiles are the files of the fake exercise, keyed by their path
+// relative to the exercise directory.
+var selftestFiles = map[string]string{
+	"bogus.go":            "package bogus\n",
+	"bogus_test.go":       "package bogus\n\nimport \"testing\"\n",
+	"lib/helpers.go":      "package lib\n",
+	"lib/ünïcode.txt":     "ünïcode\n",
+	"notes with space.md": "# Notes\n\nLine endings\r\nare kept.\r\n",
+}
+
+func runSelftest(w io.Writer) error {
+	tmpDir, err := ioutil.TempDir("", "exercism-selftest")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+	// The temporary directory may be behind a symlink, e.g. on macOS.
+	if tmpDir, err = filepath.EvalSymlinks(tmpDir); err != nil {
+		return err
+	}
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	solution := &workspace.Solution{
+		ID:          "bogus-id",
+		Track:       "bogus-track",
+		Exercise:    "bogus-exercise",
+		IsRequester: true,
+	}
+	if err := solution.Write(dir); err != nil {
+		return err
+	}
+
+	// The files are named explicitly, since the tests and documentation
+	// would be left out of the directory.
+	var files []string
+	for name, contents := range selftestFiles {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(file), os.FileMode(0755)); err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(file, []byte(contents), os.FileMode(0644)); err != nil {
+			return err
+		}
+		files = append(files, file)
+	}
+
+	// A token file of its own keeps the user's token out of it.
+	tokenFile := filepath.Join(tmpDir, "token")
+	if err := ioutil.WriteFile(tokenFile, []byte("bogus-token"), os.FileMode(0600)); err != nil {
+		return err
+	}
+
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", "http://example.com")
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+	flags := pflag.NewFlagSet("selftest", pflag.ContinueOnError)
+	setupSubmitFlags(flags)
+	if err := flags.Set("token-file", tokenFile); err != nil {
+		return err
+	}
+	f, err := newSubmitFlags(flags)
+	if err != nil {
+		return err
+	}
+
+	// The submission goes through the same steps as with the submit command,
+	// up to the upload. Without asking the API, it supports nothing optional.
+	s := &submitState{cfg: cfg, timer: newPhaseTimer()}
+	if err := resolveSubmit(context.Background(), f, s, files); err != nil {
+		return err
+	}
+	if err := filterSubmit(f, s); err != nil {
+		return err
+	}
+	if s.dir != dir {
+		return fmt.Errorf("expected exercise %s, got %s", dir, s.dir)
+	}
+	if _, err := inspectSubmit(context.Background(), f, s); err != nil {
+		return err
+	}
+	replace, _, err := leaveOutUnchanged(f, s)
+	if err != nil {
+		return err
+	}
+	if _, err := buildSubmitBody(f, s, replace); err != nil {
+		return err
+	}
+	body, contentType, err := s.body.build(s.solution)
+	if err != nil {
+		return err
+	}
+
+	if err := checkSubmitBody(body, contentType, s.solution); err != nil {
+		fmt.Fprintf(w, "Self-test failed: %s\n", err)
+		return err
+	}
+	fmt.Fprintf(w, "Self-test passed: %d files round-tripped through the submission body.\n", len(selftestFiles))
+	return nil
+}
+
+// checkSubmitBody parses a submission body and compares it to the fake exercise.
+func checkSubmitBody(body []byte, contentType string, solution *workspace.Solution) error {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return fmt.Errorf("invalid content type '%s': %s", contentType, err)
+	}
+
+	fields := map[string]string{}
+	files := map[string]string{}
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return fmt.Errorf("unreadable body: %s", err)
+		}
+		// Part.FileName drops directories, so read the raw header instead.
+		_, disposition, err := mime.ParseMediaType(part.Header.Get("Content-Disposition"))
+		if err != nil {
+			return fmt.Errorf("invalid part header: %s", err)
+		}
+		b, err := ioutil.ReadAll(part)
+		if err != nil {
+			return err
+		}
+		if disposition["name"] == "files[]" {
+			files[disposition["filename"]] = string(b)
+			continue
+		}
+		fields[disposition["name"]] = string(b)
+	}
+
+	expected := map[string]string{
+		"track":    solution.Track,
+		"exercise": solution.Exercise,
+	}
+	for name, value := range expected {
+		if fields[name] != value {
+			return fmt.Errorf("expected field '%s' to be '%s', got '%s'", name, value, fields[name])
+		}
+	}
+
+	if len(files) != len(selftestFiles) {
+		return fmt.Errorf("expected %d files, got %d", len(selftestFiles), len(files))
+	}
+	for name, contents := range selftestFiles {
+		got, ok := files[name]
+		if !ok {
+			return fmt.Errorf("missing file '%s'", name)
+		}
+		if got != contents {
+			return fmt.Errorf("contents of '%s' changed: expected %q, got %q", name, contents, got)
+		}
+	}
+	return nil
+}
+
+func init() {
+	RootCmd.AddCommand(selftestCmd)
+}
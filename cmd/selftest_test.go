@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/exercism/cli/workspace"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSelftest(t *testing.T) {
+	var out bytes.Buffer
+	err := runSelftest(&out)
+	assert.NoError(t, err)
+	assert.Regexp(t, "Self-test passed", out.String())
+}
+
+func TestCheckSubmitBodyDetectsChanges(t *testing.T) {
+	solution := &workspace.Solution{Track: "bogus-track", Exercise: "bogus-exercise"}
+
+	body := strings.Join([]string{
+		"--xyz",
+		`Content-Disposition: form-data; name="track"`,
+		"",
+		"bogus-track",
+		"--xyz",
+		`Content-Disposition: form-data; name="exercise"`,
+		"",
+		"other-exercise",
+		"--xyz--",
+		"",
+	}, "\r\n")
+
+	err := checkSubmitBody([]byte(body), "multipart/form-data; boundary=xyz", solution)
+	assert.Error(t, err)
+	assert.Regexp(t, "exercise", err.Error())
+}
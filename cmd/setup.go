@@ -0,0 +1,282 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/exercism/cli/api"
+	"github.com/exercism/cli/config"
+	"github.com/spf13/cobra"
+)
+
+// setupCmd walks new users through configuring the CLI.
+var setupCmd = &cobra.Command{
+	Use:   "setup",
+	Short: "Set up the command-line client interactively.",
+	Long: `Set up the command-line client by answering a few questions.
+
+This asks which API to talk to, for your API token, and where to put
+your exercises, and then saves the answers in the same place as the
+configure command does. The token is checked with the API before it's
+saved. Calling the configure command without any flags does the same.
+
+To configure the client non-interactively, e.g. from a script,
+use the configure command instead.
+`,
+	Args: cobra.NoArgs,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		if err := checkConfigWritable("setup"); err != nil {
+			return err
+		}
+		cfg, err := loadUserConfig()
+		if err != nil {
+			return err
+		}
+		return runSetup(cfg, In)
+	},
+}
+
+func runSetup(cfg config.Config, in io.Reader) error {
+	if !isInteractive(in) {
+		msg := `
+
+    The setup command needs to ask you some questions,
+    but it isn't connected to a terminal.
+
+    To configure the client non-interactively, call
+
+        %s configure --token=YOUR_TOKEN --workspace=PATH
+
+`
+		return fmt.Errorf(msg, BinaryName)
+	}
+
+	usrCfg := cfg.UserViperConfig
+	p := newPrompter(in, Err)
+
+	defaultBaseURL := usrCfg.GetString("apibaseurl")
+	if defaultBaseURL == "" {
+		defaultBaseURL = cfg.DefaultBaseURL
+	}
+	var baseURL string
+	for baseURL == "" {
+		answer, err := p.ask("API base URL", defaultBaseURL)
+		if err != nil {
+			return err
+		}
+		client, err := api.NewClient("", strings.TrimRight(answer, "/"))
+		if err != nil {
+			return err
+		}
+		if err := client.IsPingable(); err != nil {
+			fmt.Fprintf(Err, "The API at '%s' cannot be reached - %s\n", answer, err)
+			continue
+		}
+		baseURL = client.APIBaseURL
+	}
+
+	fmt.Fprintf(Err, "\nYou can find your API token at %s\n\n", config.SettingsURL(baseURL))
+	defaultToken := usrCfg.GetString("token")
+	var token string
+	for token == "" {
+		answer, err := p.ask("Token", defaultToken)
+		if err != nil {
+			return err
+		}
+		if token = cleanPastedToken(answer); token == "" {
+			fmt.Fprintln(Err, "The token is required.")
+			continue
+		}
+		if token != answer {
+			fmt.Fprintf(Err, "Using %s, without the extra characters that came along with it.\n", token)
+		}
+
+		client, err := api.NewClient(token, baseURL)
+		if err != nil {
+			return err
+		}
+		info, err := client.ValidateToken()
+		if err != nil {
+			return err
+		}
+		if !info.Valid {
+			fmt.Fprintf(Err, "The token '%s' is invalid. Please check it against the one on the website.\n", token)
+			token, defaultToken = "", ""
+			continue
+		}
+		reportTokenOwner(info)
+	}
+
+	defaultWorkspace := usrCfg.GetString("workspace")
+	if defaultWorkspace == "" {
+		defaultWorkspace = config.DefaultWorkspaceDir(cfg)
+	}
+	var workspace string
+	for workspace == "" {
+		answer, err := p.ask("Workspace directory", defaultWorkspace)
+		if err != nil {
+			return err
+		}
+		workspace = config.Resolve(answer, cfg.Home)
+
+		info, err := os.Lstat(workspace)
+		if os.IsNotExist(err) {
+			create, err := p.confirm(fmt.Sprintf("%s does not exist. Create it?", workspace), true)
+			if err != nil {
+				return err
+			}
+			if !create {
+				workspace = ""
+				continue
+			}
+			if err := os.MkdirAll(workspace, os.FileMode(0755)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if !info.IsDir() {
+			fmt.Fprintf(Err, "%s is not a directory. Please choose a different location.\n", workspace)
+			workspace = ""
+		}
+	}
+
+	usrCfg.Set("token", token)
+	usrCfg.Set("workspace", workspace)
+	usrCfg.Set("apibaseurl", baseURL)
+	if err := cfg.Save("user"); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(Err, "\nYou have configured the Exercism command-line client:")
+	printCurrentConfig(cfg)
+	return nil
+}
+
+// rgxPastedToken finds a token, which is a UUID, in what was pasted.
+var rgxPastedToken = regexp.MustCompile(`(?i)[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}`)
+
+// cleanPastedToken picks the token out of what was pasted, which may have
+// come with quotes, a label, or line breaks from copying it off the page.
+func cleanPastedToken(pasted string) string {
+	token := strings.Join(strings.Fields(pasted), "")
+	if match := rgxPastedToken.FindString(token); match != "" {
+		return match
+	}
+	return strings.Trim(strings.TrimSpace(pasted), `"'`)
+}
+
+// isInteractive determines whether input comes from a terminal.
+// Readers that aren't files, such as scripted input, count as interactive.
+func isInteractive(in io.Reader) bool {
+	f, ok := in.(*os.File)
+	if !ok {
+		return true
+	}
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return info.Mode()&os.ModeCharDevice != 0
+}
+
+// prompter asks questions and reads the answers line by line.
+type prompter struct {
+	in  *bufio.Reader
+	out io.Writer
+	// ctx, if set, stops the wait for an answer when it's cancelled,
+	// e.g. by Ctrl-C while a signal handler is installed.
+	ctx context.Context
+}
+
+func newPrompter(in io.Reader, out io.Writer) *prompter {
+	return &prompter{in: bufio.NewReader(in), out: out}
+}
+
+// withContext makes the prompter give up on the answer when ctx is done.
+func (p *prompter) withContext(ctx context.Context) *prompter {
+	p.ctx = ctx
+	return p
+}
+
+// readLine reads the next answer. Once the context is done, it returns
+// errInterrupted without waiting for the read to finish.
+func (p *prompter) readLine() (string, error) {
+	if p.ctx == nil {
+		return p.in.ReadString('\n')
+	}
+	type result struct {
+		line string
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		line, err := p.in.ReadString('\n')
+		done <- result{line, err}
+	}()
+	select {
+	case r := <-done:
+		return r.line, r.err
+	case <-p.ctx.Done():
+		fmt.Fprintln(p.out)
+		return "", errInterrupted
+	}
+}
+
+// ask prompts for a value, falling back to the default if the answer is blank.
+func (p *prompter) ask(question, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(p.out, "%s [%s]: ", question, defaultValue)
+	} else {
+		fmt.Fprintf(p.out, "%s: ", question)
+	}
+
+	line, err := p.readLine()
+	if err != nil && (err != io.EOF || line == "") {
+		if err == io.EOF {
+			return "", errors.New("aborted before all the questions were answered")
+		}
+		return "", err
+	}
+
+	answer := strings.TrimSpace(line)
+	if answer == "" {
+		return defaultValue, nil
+	}
+	return answer, nil
+}
+
+// confirm asks a yes/no question.
+func (p *prompter) confirm(question string, defaultYes bool) (bool, error) {
+	options := "y/N"
+	if defaultYes {
+		options = "Y/n"
+	}
+	for {
+		answer, err := p.ask(fmt.Sprintf("%s (%s)", question, options), "")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "":
+			return defaultYes, nil
+		case "y", "yes":
+			return true, nil
+		case "n", "no":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "Please answer yes or no.")
+	}
+}
+
+func init() {
+	RootCmd.AddCommand(setupCmd)
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/exercism/cli/config"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetup(t *testing.T) {
+	oldErr := Err
+	defer func() {
+		Err = oldErr
+	}()
+
+	pastedToken := "c3a9d4f2-1b5e-4c8a-9f6d-2e7b8a1c0d34"
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/ping" {
+			return
+		}
+		assert.Equal(t, "/validate_token", r.URL.Path)
+		switch r.Header.Get("Authorization") {
+		case "Bearer good-token", "Bearer " + pastedToken:
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "setup")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	existing := filepath.Join(tmpDir, "existing")
+	err = os.Mkdir(existing, os.FileMode(0755))
+	assert.NoError(t, err)
+
+	notADir := filepath.Join(tmpDir, "file")
+	err = ioutil.WriteFile(notADir, []byte("x"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc      string
+		input     []string
+		token     string
+		workspace string
+		output    string
+		err       string
+	}{
+		{
+			desc:      "existing workspace",
+			input:     []string{"", "good-token", existing},
+			workspace: existing,
+		},
+		{
+			desc:      "creates the workspace",
+			input:     []string{"", "good-token", "~/new", ""},
+			workspace: filepath.Join(tmpDir, "new"),
+		},
+		{
+			desc:      "re-prompts for missing token and bad workspace",
+			input:     []string{"", "", "good-token", notADir, "~/declined", "n", existing},
+			workspace: existing,
+		},
+		{
+			desc:      "re-prompts for an API that can't be reached",
+			input:     []string{"http://127.0.0.1:1", ts.URL + "/", "good-token", existing},
+			workspace: existing,
+			output:    "cannot be reached",
+		},
+		{
+			desc:      "re-prompts for an invalid token",
+			input:     []string{"", "bad-token", "good-token", existing},
+			workspace: existing,
+			output:    "The token 'bad-token' is invalid",
+		},
+		{
+			desc:      "cleans up a pasted token",
+			input:     []string{"", `"Token: ` + pastedToken + `"`, existing},
+			token:     pastedToken,
+			workspace: existing,
+			output:    "without the extra characters",
+		},
+		{
+			desc:  "input ends early",
+			input: []string{"", "good-token"},
+			err:   "aborted",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			Err = &buf
+
+			configDir, err := ioutil.TempDir("", "setup-config")
+			defer os.RemoveAll(configDir)
+			assert.NoError(t, err)
+
+			v := viper.New()
+			v.Set("apibaseurl", ts.URL)
+			cfg := config.Config{
+				Persister:       config.FilePersister{Dir: configDir},
+				UserViperConfig: v,
+				Home:            tmpDir,
+				DefaultDirName:  "exercism",
+			}
+
+			in := strings.NewReader(strings.Join(tc.input, "\n") + "\n")
+			err = runSetup(cfg, in)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Regexp(t, tc.err, err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Contains(t, buf.String(), tc.output)
+
+			token := tc.token
+			if token == "" {
+				token = "good-token"
+			}
+			saved := readUserConfig(t, configDir)
+			assert.Equal(t, token, saved.GetString("token"))
+			assert.Equal(t, tc.workspace, saved.GetString("workspace"))
+			assert.Equal(t, ts.URL, saved.GetString("apibaseurl"))
+
+			info, err := os.Stat(tc.workspace)
+			assert.NoError(t, err)
+			assert.True(t, info.IsDir())
+		})
+	}
+}
+
+func TestSetupNonInteractive(t *testing.T) {
+	f, err := ioutil.TempFile("", "setup-input")
+	assert.NoError(t, err)
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: viper.New(),
+	}
+
+	err = runSetup(cfg, f)
+	if assert.Error(t, err) {
+		assert.Regexp(t, "configure --token", err.Error())
+	}
+}
+
+func TestCleanPastedToken(t *testing.T) {
+	token := "c3a9d4f2-1b5e-4c8a-9f6d-2e7b8a1c0d34"
+	for _, pasted := range []string{
+		token,
+		"  " + token + "\t",
+		`"` + token + `"`,
+		"Token: " + token,
+		"c3a9d4f2-1b5e-4c8a-\n9f6d-2e7b8a1c0d34",
+	} {
+		assert.Equal(t, token, cleanPastedToken(pasted), pasted)
+	}
+	assert.Equal(t, "not-a-uuid", cleanPastedToken(" 'not-a-uuid' "))
+	assert.Equal(t, "", cleanPastedToken("   "))
+}
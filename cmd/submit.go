@@ -1,16 +1,32 @@
 package cmd
 
 import (
+	"bufio"
 	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"mime/multipart"
+	"net"
+	"net/http"
+	"net/textproto"
 	"os"
 	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+	"unicode/utf8"
 
 	"github.com/exercism/cli/api"
 	"github.com/exercism/cli/config"
+	"github.com/exercism/cli/debug"
 	"github.com/exercism/cli/workspace"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
@@ -25,117 +41,393 @@ var submitCmd = &cobra.Command{
 	Long: `Submit your solution to an Exercism exercise.
 
 	Call the command with the list of files you want to submit.
-`,
-	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg := config.NewConfig()
 
-		usrCfg := viper.New()
-		usrCfg.AddConfigPath(cfg.Dir)
-		usrCfg.SetConfigName("user")
-		usrCfg.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = usrCfg.ReadInConfig()
-		cfg.UserViperConfig = usrCfg
+	You can also name a directory, such as the exercise directory,
+	to submit the solution files in it. Tests, documentation, hidden
+	files, dependencies, and build output are left out.
 
-		v := viper.New()
-		v.AddConfigPath(cfg.Dir)
-		v.SetConfigName("cli")
-		v.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = v.ReadInConfig()
+	Call the command without any files from within an exercise directory
+	to submit that exercise. The solution files listed in the exercise's
+	.exercism/config.json are submitted, if there is one. You're warned when
+	you submit a file that it lists as a test, or doesn't list as a solution.
+	Editor swap files and the exercise's metadata are never submitted.
 
-		return runSubmit(cfg, cmd.Flags(), args)
-	},
-}
+	The files don't have to be in your workspace. An exercise that you keep
+	somewhere else, e.g. in a repository of its own, can be submitted as long
+	as it still has its solution metadata file, .solution.json.
 
-func runSubmit(cfg config.Config, flags *pflag.FlagSet, args []string) error {
-	usrCfg := cfg.UserViperConfig
+	When exercises are nested in one another, the files are submitted to the
+	innermost one. Pass --track and --exercise to pick another one.
 
-	if usrCfg.GetString("token") == "" {
-		return fmt.Errorf(msgWelcomePleaseConfigure, config.SettingsURL(usrCfg.GetString("apibaseurl")), BinaryName)
-	}
+	Files that match the patterns in the exercise's .exercismignore file,
+	which uses the same syntax as .gitignore, are never submitted.
 
-	if usrCfg.GetString("workspace") == "" {
-		return fmt.Errorf(msgRerunConfigure, BinaryName)
-	}
+	With --normalize, or the normalize setting in the config, text files are
+	sent with LF line endings and without a byte order mark, as the tests on
+	the website expect. Your files are left as they are.
 
-	for i, arg := range args {
-		var err error
-		arg, err = filepath.Abs(arg)
+	Large submissions are sent in chunks, if the API supports it. If such an
+	upload is interrupted, submitting the same files again resumes it.
+
+	A command to run before submitting, such as a formatter or the tests,
+	can be set for a track or a single exercise in the presubmit list in
+	the hooks section of the config, e.g.
+
+	    "hooks": {"presubmit": [{"track": "go", "command": "go vet && go test"}]}
+
+	Nothing is submitted if it fails. Pass --no-verify to skip it.
+
+	Submitting a directory, or submitting without naming any files, lists
+	the files of the exercise with their sizes, and lets you choose which
+	ones to upload. The solution files are selected to start. The list is
+	only shown when the command runs in a terminal; pass --no-pick to skip
+	it there too, e.g. in scripts, or --pick to show it anyway.
+
+	Each successful submission is recorded in the config directory. To list
+	them, call the command with --history, optionally with --track or
+	--exercise to narrow it down. Add --format json to see the files sent.
+
+	The upload gives up if it takes longer than the HTTP timeout, which is
+	60 seconds unless --timeout or the timeout setting in the config says
+	otherwise, e.g.
+
+	    exercism config set timeout 300
+
+	With --watch, the command keeps running after submitting, and submits
+	again whenever the files change, once they've been left alone for a
+	moment. Press Ctrl+C to stop watching.
+`,
+	RunE: func(cmd *cobra.Command, args []string) error {
+		cfg, err := loadUserConfig()
 		if err != nil {
 			return err
 		}
 
-		info, err := os.Lstat(arg)
-		if err != nil {
-			if os.IsNotExist(err) {
-				msg := `
-
-    The file you are trying to submit cannot be found.
+		v := viper.New()
+		if cfg.Dir != "" {
+			v.AddConfigPath(cfg.Dir)
+			v.SetConfigName("cli")
+			v.SetConfigType("json")
+			// A missing file is fine.
+			readConfigFile(v, cfg.Dir, "cli")
+		}
 
-        %s
+		ctx, stop := interruptContext()
+		defer stop()
 
-		`
-				return fmt.Errorf(msg, arg)
-			}
+		watch, err := cmd.Flags().GetBool("watch")
+		if err != nil {
 			return err
 		}
-		if info.IsDir() {
-			msg := `
+		if watch {
+			err = watchAndSubmit(ctx, args, func() error {
+				return runSubmit(ctx, cfg, cmd.Flags(), args)
+			})
+			return silenceInterrupted(cmd, err)
+		}
+		return silenceInterrupted(cmd, runSubmit(ctx, cfg, cmd.Flags(), args))
+	},
+}
 
-    You are submitting a directory, which is not currently supported.
+// submitFlags holds the flags of the submit command, read once so that
+// each step of a submission can use the ones it needs.
+type submitFlags struct {
+	trace               bool
+	format              string
+	json                bool
+	history             bool
+	explain             bool
+	tokenFile           string
+	resubmitLast        bool
+	dereference         bool
+	track               string
+	exercise            string
+	pick                bool
+	noPick              bool
+	minInterval         int
+	force               bool
+	dryRun              bool
+	noVerify            bool
+	alsoSubmitTo        []string
+	team                string
+	allowBinary         bool
+	strict              bool
+	maxFiles            int
+	maxFileSize         string
+	printDiff           bool
+	printDiffOnly       bool
+	timeoutChanged      bool
+	refreshCapabilities bool
+	ifNewer             bool
+	replace             bool
+	onlyChanged         bool
+	gzip                string
+	gzipChanged         bool
+	gzipThreshold       string
+	normalize           bool
+	normalizeChanged    bool
+	message             string
+	multipartBoundary   string
+	rateLimit           string
+	continueOnError     bool
+	retries             int
+	retryBackoff        time.Duration
+	quiet               bool
+	archiveDir          string
+	open                bool
+	waitForTests        time.Duration
+	listIterations      bool
+}
 
-        %s
+func newSubmitFlags(flags *pflag.FlagSet) (submitFlags, error) {
+	var f submitFlags
+	var err error
+	bools := []struct {
+		name  string
+		value *bool
+	}{
+		{"trace", &f.trace},
+		{"json", &f.json},
+		{"history", &f.history},
+		{"explain", &f.explain},
+		{"resubmit-last", &f.resubmitLast},
+		{"dereference", &f.dereference},
+		{"pick", &f.pick},
+		{"no-pick", &f.noPick},
+		{"force", &f.force},
+		{"dry-run", &f.dryRun},
+		{"no-verify", &f.noVerify},
+		{"allow-binary", &f.allowBinary},
+		{"strict", &f.strict},
+		{"print-diff", &f.printDiff},
+		{"print-diff-only", &f.printDiffOnly},
+		{"refresh-capabilities", &f.refreshCapabilities},
+		{"if-newer", &f.ifNewer},
+		{"replace", &f.replace},
+		{"only-changed", &f.onlyChanged},
+		{"normalize", &f.normalize},
+		{"continue-on-error", &f.continueOnError},
+		{"quiet", &f.quiet},
+		{"open", &f.open},
+		{"all-iterations-list", &f.listIterations},
+	}
+	for _, b := range bools {
+		if *b.value, err = flags.GetBool(b.name); err != nil {
+			return f, err
+		}
+	}
+	strs := []struct {
+		name  string
+		value *string
+	}{
+		{"format", &f.format},
+		{"token-file", &f.tokenFile},
+		{"track", &f.track},
+		{"exercise", &f.exercise},
+		{"team", &f.team},
+		{"max-file-size", &f.maxFileSize},
+		{"gzip", &f.gzip},
+		{"gzip-threshold", &f.gzipThreshold},
+		{"message", &f.message},
+		{"multipart-boundary", &f.multipartBoundary},
+		{"rate-limit", &f.rateLimit},
+		{"archive-dir", &f.archiveDir},
+	}
+	for _, s := range strs {
+		if *s.value, err = flags.GetString(s.name); err != nil {
+			return f, err
+		}
+	}
+	if f.alsoSubmitTo, err = flags.GetStringArray("also-submit-to"); err != nil {
+		return f, err
+	}
+	if f.minInterval, err = flags.GetInt("min-interval"); err != nil {
+		return f, err
+	}
+	if f.maxFiles, err = flags.GetInt("max-files"); err != nil {
+		return f, err
+	}
+	if f.retries, err = flags.GetInt("retries"); err != nil {
+		return f, err
+	}
+	if f.retryBackoff, err = flags.GetDuration("retry-backoff"); err != nil {
+		return f, err
+	}
+	if f.waitForTests, err = flags.GetDuration("wait-for-tests"); err != nil {
+		return f, err
+	}
+	// The --timeout flag belongs to the root command, which has already
+	// applied it. Without it, the timeout from the config is used.
+	if t := flags.Lookup("timeout"); t != nil {
+		f.timeoutChanged = t.Changed
+	}
+	f.gzipChanged = flags.Changed("gzip")
+	f.normalizeChanged = flags.Changed("normalize")
+	return f, nil
+}
 
-    Please change into the directory and provide the path to the file(s) you wish to submit
+// submitState is what the steps of a submission work out, for the steps after them.
+type submitState struct {
+	cfg    config.Config
+	timer  *phaseTimer
+	output formatter
+	// Warnings are printed as they come up, and summarized in the JSON report.
+	warned  warnings
+	explain *explanation
 
-        %s submit FILENAME
+	token    string
+	ws       workspace.Workspace
+	dir      string
+	exercise workspace.Exercise
+	solution *workspace.Solution
+	// solutions are the ones the files are submitted to, starting with solution.
+	solutions []*workspace.Solution
+	team      string
+	files     []string
+	texts     *textFiles
+	checksums map[string]string
+	// tmpDir holds the files restored for --resubmit-last.
+	tmpDir string
+
+	client       *api.Client
+	capabilities api.Capabilities
+	rate         int64
+	body         submitBody
+	contentType  string
+}
 
-			`
-			return fmt.Errorf(msg, arg, BinaryName)
+func runSubmit(ctx context.Context, cfg config.Config, flags *pflag.FlagSet, args []string) error {
+	f, err := newSubmitFlags(flags)
+	if err != nil {
+		return err
+	}
+
+	s := &submitState{cfg: cfg, timer: newPhaseTimer()}
+	if f.trace {
+		defer s.timer.Print(Err)
+	}
+	defer func() {
+		if s.tmpDir != "" {
+			os.RemoveAll(s.tmpDir)
 		}
+	}()
 
-		src, err := filepath.EvalSymlinks(arg)
-		if err != nil {
+	// Without an explicit format, results are described in prose.
+	if f.format != "" {
+		if s.output, err = newFormatter(f.format); err != nil {
 			return err
 		}
-		args[i] = src
+	}
+	if f.json {
+		if f.format != "" {
+			return errors.New("--json and --format can't be used together")
+		}
+		f.format = "json"
+		s.output = jsonFormatter{}
 	}
 
-	ws, err := workspace.New(usrCfg.GetString("workspace"))
-	if err != nil {
+	if f.pick && f.noPick {
+		return errors.New("--pick and --no-pick can't be used together")
+	}
+	// When there's someone to choose, the files are picked from a list.
+	f.pick = f.pick || (!f.noPick && canPrompt())
+
+	if f.history {
+		return runSubmitHistory(cfg, f, s.output)
+	}
+	if f.explain {
+		s.explain = &explanation{}
+	}
+
+	if err := resolveSubmit(ctx, f, s, args); err != nil {
+		return err
+	}
+	if err := filterSubmit(f, s); err != nil {
 		return err
 	}
+	s.timer.Mark("resolve arguments")
 
-	var exerciseDir string
-	for _, arg := range args {
-		dir, err := ws.SolutionDir(arg)
+	if err := runPreSubmitHook(ctx, f, s); err != nil {
+		return err
+	}
+	if done, err := inspectSubmit(ctx, f, s); done || err != nil {
+		return err
+	}
+	if done, err := prepareUpload(f, s); done || err != nil {
+		return err
+	}
+
+	var results submitResults
+	var failures []submitFailure
+	for _, solution := range s.solutions {
+		// The body is streamed, so building it is part of the upload.
+		payload, err := uploadSubmission(ctx, f, s, solution)
+		s.timer.Mark("upload")
 		if err != nil {
-			if workspace.IsMissingMetadata(err) {
-				return errors.New(msgMissingMetadata)
+			// The JSON report covers failures too, so it has to be printed first.
+			if err == errInterrupted || (!f.continueOnError && !f.json) {
+				return err
 			}
+			failures = append(failures, submitFailure{solution: solution, err: err})
+			if !f.continueOnError {
+				break
+			}
+			continue
+		}
+		result, err := reportSubmission(ctx, f, s, solution, payload)
+		s.timer.Mark("report")
+		if err != nil {
 			return err
 		}
-		if exerciseDir != "" && dir != exerciseDir {
-			msg := `
+		results = append(results, result)
+	}
 
-    You are submitting files belonging to different solutions.
-    Please submit the files for one solution at a time.
+	return reportSubmissions(f, s, results, failures)
+}
 
-		`
-			return errors.New(msg)
-		}
-		exerciseDir = dir
+// resolveSubmit works out the files to submit, the solution they belong to,
+// and any other solutions to submit them to.
+func resolveSubmit(ctx context.Context, f submitFlags, s *submitState, args []string) error {
+	usrCfg := s.cfg.UserViperConfig
+
+	token, err := config.ResolveToken(f.tokenFile, s.cfg)
+	if err != nil {
+		return err
 	}
 
-	exercise := workspace.NewExerciseFromDir(exerciseDir)
+	if token == "" {
+		return fmt.Errorf(msgWelcomePleaseConfigure, config.SettingsURL(usrCfg.GetString("apibaseurl")), BinaryName)
+	}
+	s.token = token
+
+	root := config.WorkspaceFor(usrCfg)
+	if root == "" {
+		return fmt.Errorf(msgRerunConfigure, BinaryName)
+	}
+
+	warnIfNetworkPath(root, s.cfg.Dir)
 
-	solution, err := workspace.NewSolution(exerciseDir)
+	files, err := resolveSubmitFiles(ctx, f, s, root, args)
 	if err != nil {
 		return err
 	}
+	s.files = files
 
-	if !solution.IsRequester {
+	ws, err := workspace.New(root)
+	if err != nil {
+		return err
+	}
+	s.ws = ws
+
+	loc, err := locateSubmission(f, s, files)
+	if err != nil {
+		return err
+	}
+	s.dir = loc.Dir
+	s.exercise = loc.Exercise
+	s.solution = loc.Solution
+
+	if !s.solution.IsRequester {
 		// TODO: add test
 		msg := `
 
@@ -144,106 +436,2170 @@ func runSubmit(cfg config.Config, flags *pflag.FlagSet, args []string) error {
 
         %s download --exercise=%s --track=%s
 
+    Any local changes you've made are kept. To control how conflicting
+    files are handled, pass --on-conflict=keep, overwrite, merge, or backup.
+
 		`
-		return fmt.Errorf(msg, BinaryName, solution.Exercise, solution.Track)
+		return fmt.Errorf(msg, BinaryName, s.solution.Exercise, s.solution.Track)
 	}
+	s.explain.add("The files belong to %s, found in %s, which is solution %s.", s.solution, s.dir, s.solution.ID)
 
-	exercise.Documents = make([]workspace.Document, 0, len(args))
-	for _, file := range args {
-		// Don't submit empty files
-		info, err := os.Stat(file)
+	if f.minInterval > 0 && s.solution.SubmittedAt != nil {
+		since := time.Since(*s.solution.SubmittedAt)
+		if since < time.Duration(f.minInterval)*time.Second && !f.force {
+			msg := `
+
+    You last submitted this solution %s ago, which is less than
+    the minimum interval of %d seconds.
+
+    If you really mean to submit again, call the command again with --force
+
+`
+			return fmt.Errorf(msg, since.Round(time.Second), f.minInterval)
+		}
+	}
+
+	// Resolve every target up front, so that nothing is uploaded
+	// if any of them is wrong.
+	s.solutions = []*workspace.Solution{s.solution}
+	for _, target := range f.alsoSubmitTo {
+		solution, err := resolveSubmitTarget(s.ws, target)
 		if err != nil {
 			return err
 		}
-		if info.Size() == 0 {
+		s.solutions = append(s.solutions, solution)
+		s.explain.add("Because of --also-submit-to, they will also be submitted to %s, which is solution %s.", solution, solution.ID)
+	}
+
+	s.team = f.team
+	teamSource := "--team"
+	if s.team == "" {
+		s.team = usrCfg.GetString("team")
+		teamSource = "the config"
+	}
+	if s.team != "" {
+		if err := config.ValidateTeamSlug(s.team); err != nil {
+			return err
+		}
+		s.explain.add("Submissions go through the team '%s', as set by %s.", s.team, teamSource)
+	}
+	return nil
+}
 
+// resolveSubmitFiles turns the arguments into the absolute paths of the files to submit.
+// Directories are replaced by the solution files in them.
+func resolveSubmitFiles(ctx context.Context, f submitFlags, s *submitState, root string, args []string) ([]string, error) {
+	// Without arguments, submit the exercise that we're in.
+	if len(args) == 0 {
+		ws, err := workspace.New(root)
+		if err != nil {
+			return nil, err
+		}
+		loc, err := ws.FindExerciseFromCwd()
+		pickExercise := f.track != "" || f.exercise != ""
+		if workspace.IsNotInWorkspace(err) || pickExercise {
+			// Exercises may be kept elsewhere, e.g. in a repository of their own.
+			var cwd string
+			if cwd, err = os.Getwd(); err == nil {
+				loc, err = workspace.DiscoverExercise(cwd, f.track, f.exercise)
+				if pickExercise && workspace.IsMissingMetadata(err) {
+					return nil, errNoMatchingExercise(cwd, f.track, f.exercise)
+				}
+			}
+		}
+		if workspace.IsNotInExercise(err) || workspace.IsMissingMetadata(err) {
 			msg := `
 
-    WARNING: Skipping empty file
-             %s
+    No files found to submit.
 
-		`
-			fmt.Fprintf(Err, msg, file)
-			continue
+    Call the command from within an exercise directory to submit its
+    solution files, or name the files to submit:
+
+        %s submit FILE1 [FILE2 ...]
+
+`
+			return nil, fmt.Errorf(msg, BinaryName)
 		}
-		doc, err := workspace.NewDocument(exercise.Filepath(), file)
 		if err != nil {
-			return err
+			return nil, err
+		}
+
+		args = []string{loc.Dir}
+		if !f.resubmitLast {
+			args, err = defaultSolutionFiles(loc.Dir, f.dereference)
+			if err != nil && !f.pick {
+				return nil, err
+			}
+			if f.pick {
+				if args, err = pickSubmitFiles(ctx, loc.Dir, args, f.dereference); err != nil {
+					return nil, err
+				}
+			}
 		}
-		exercise.Documents = append(exercise.Documents, doc)
+		s.explain.add("Submit the exercise in %s, since no files were named.", loc.Dir)
 	}
 
-	if len(exercise.Documents) == 0 {
-		msg := `
+	args, err := expandGlobs(args)
+	if err != nil {
+		return nil, err
+	}
 
-    No files found to submit.
+	files := make([]string, 0, len(args))
+	for _, arg := range args {
+		var err error
+		arg, err = filepath.Abs(arg)
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Lstat(arg)
+		if err != nil {
+			if os.IsNotExist(err) {
+				msg := `
+
+    The file you are trying to submit cannot be found.
+
+        %s
 
 		`
-		return errors.New(msg)
-	}
+				return nil, fmt.Errorf(msg, arg)
+			}
+			return nil, err
+		}
+		if info.IsDir() && !f.resubmitLast {
+			found, err := solutionFilesInDir(arg, f.dereference)
+			if err != nil {
+				return nil, err
+			}
+			if f.pick {
+				if found, err = pickSubmitFiles(ctx, arg, found, f.dereference); err != nil {
+					return nil, err
+				}
+			}
+			if len(found) == 0 {
+				msg := `
 
-	body := &bytes.Buffer{}
-	writer := multipart.NewWriter(body)
+    No solution files found in the directory.
+
+        %s
+
+    Tests, documentation, and hidden files are left out.
+    To submit any of them, name them explicitly:
+
+        %s submit FILENAME
+
+`
+				return nil, fmt.Errorf(msg, arg, BinaryName)
+			}
+			for _, file := range found {
+				s.explain.add("Include %s, found in the directory %s.", file, arg)
+			}
+			files = append(files, found...)
+			continue
+		}
 
-	for _, doc := range exercise.Documents {
-		file, err := os.Open(doc.Filepath())
+		src, err := filepath.EvalSymlinks(arg)
 		if err != nil {
-			return err
+			return nil, err
 		}
-		defer file.Close()
+		files = append(files, src)
+	}
+	// A file may have been named along with its directory.
+	return uniqueStrings(files), nil
+}
+
+// locateSubmission finds the solution that all the files belong to.
+// Exercises may be nested in one another, in which case the innermost
+// one is picked, unless the flags say otherwise.
+func locateSubmission(f submitFlags, s *submitState, files []string) (workspace.Location, error) {
+	pickExercise := f.track != "" || f.exercise != ""
 
-		part, err := writer.CreateFormFile("files[]", doc.Path())
+	var loc workspace.Location
+	for _, file := range files {
+		var l workspace.Location
+		var err error
+		if pickExercise {
+			l, err = workspace.DiscoverExercise(file, f.track, f.exercise)
+			if workspace.IsMissingMetadata(err) {
+				return loc, errNoMatchingExercise(file, f.track, f.exercise)
+			}
+			if err == nil {
+				s.explain.add("%s belongs to %s, as picked by --track and --exercise.", file, l.Dir)
+			}
+		} else {
+			l, err = s.ws.Locate(file)
+		}
+		if workspace.IsNotInWorkspace(err) {
+			// Exercises may be kept elsewhere, e.g. in a repository of their own.
+			l, err = workspace.Discover(file)
+			if err == nil {
+				s.explain.add("%s is outside the workspace, %s, so use the solution metadata in %s.", file, s.ws.Dir, l.Dir)
+			}
+		}
 		if err != nil {
-			return err
+			if workspace.IsMissingMetadata(err) {
+				return loc, errors.New(msgMissingMetadata)
+			}
+			if e, ok := err.(workspace.ErrInvalidMetadata); ok {
+				exercise := workspace.NewExerciseFromDir(filepath.Dir(e.Path))
+				msg := `
+
+    The metadata for the exercise you are submitting is damaged.
+
+        %s
+
+    Please re-download the exercise to restore it:
+
+        %s download --exercise=%s --track=%s
+
+    Any local changes you've made are kept.
+
+		`
+				return loc, fmt.Errorf(msg, e, BinaryName, exercise.Slug, exercise.Track)
+			}
+			return loc, err
+		}
+		if loc.Dir != "" && l.Dir != loc.Dir {
+			msg := `
+
+    You are submitting files belonging to different solutions.
+    Please submit the files for one solution at a time.
+
+		`
+			return loc, errors.New(msg)
 		}
-		_, err = io.Copy(part, file)
+		loc = l
+	}
+	return loc, nil
+}
+
+// runPreSubmitHook runs the pre-submit command from the config, if there is one.
+// It runs before the files are read, so that it may fix them up.
+func runPreSubmitHook(ctx context.Context, f submitFlags, s *submitState) error {
+	hook := config.PreSubmitCommand(s.cfg.UserViperConfig, s.solution.Track, s.solution.Exercise)
+	switch {
+	case hook == "":
+	case f.noVerify:
+		s.explain.add("Don't run the pre-submit command %q, because of --no-verify.", hook)
+	case s.explain != nil || f.dryRun:
+		debug.Printf("Not running the pre-submit command %q, because nothing is submitted\n", hook)
+		s.explain.add("Run the pre-submit command %q in %s, and stop if it fails.", hook, s.dir)
+	default:
+		fmt.Fprintf(Err, "\n    Running the pre-submit command: %s\n\n", hook)
+		err := runHook(ctx, hook, s.dir, Err)
+		s.timer.Mark("run pre-submit command")
 		if err != nil {
-			return err
+			if ctx.Err() != nil {
+				return errInterrupted
+			}
+			msg := `
+
+    The pre-submit command failed: %s
+
+        %s
+
+    Fix the problems it found, or call the command again with --no-verify
+    to submit anyway.
+
+`
+			return fmt.Errorf(msg, err, hook)
 		}
 	}
+	return nil
+}
 
-	err = writer.Close()
-	if err != nil {
-		return err
+// filterSubmit leaves out the files that the exercise's ignore file matches,
+// refuses the ones that aren't part of a solution, and warns about the ones
+// that the exercise config doesn't list as solution files.
+func filterSubmit(f submitFlags, s *submitState) error {
+	// When resubmitting, the files come from the snapshots, not the arguments.
+	if f.resubmitLast {
+		s.files = nil
+		return nil
 	}
 
-	client, err := api.NewClient(usrCfg.GetString("token"), usrCfg.GetString("apibaseurl"))
+	ignore, err := workspace.NewIgnore(s.exercise.Filepath())
 	if err != nil {
 		return err
 	}
-	url := fmt.Sprintf("%s/solutions/%s", usrCfg.GetString("apibaseurl"), solution.ID)
-	req, err := client.NewRequest("PATCH", url, body)
-	if err != nil {
+	exerciseConfig, err := workspace.NewExerciseConfig(s.exercise.Filepath())
+	if err != nil && !os.IsNotExist(err) {
 		return err
 	}
-	req.Header.Set("Content-Type", writer.FormDataContentType())
 
-	resp, err := client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+	kept := make([]string, 0, len(s.files))
+	for _, file := range s.files {
+		rel, err := filepath.Rel(s.exercise.Filepath(), file)
+		if err != nil {
+			return err
+		}
+		if ignore.Match(rel, false) {
+			debug.Printf("Leaving out %s, because it matches %s\n", file, workspace.IgnoreFilename)
+			s.explain.add("Leave out %s, because it matches %s.", file, workspace.IgnoreFilename)
+			continue
+		}
+		if workspace.IsMetadata(rel) || workspace.IsEditorFile(rel) {
+			what := "an editor's swap or backup file"
+			if workspace.IsMetadata(rel) {
+				what = "part of the exercise's metadata"
+			}
+			msg := `
 
-	bb := &bytes.Buffer{}
-	_, err = bb.ReadFrom(resp.Body)
-	if err != nil {
-		return err
-	}
+    The file you are submitting is %s, not part of your solution.
 
-	msg := `
+        %s
+
+    Please leave it out of the files you submit.
 
-    Your solution has been submitted successfully.
-    %s
 `
-	suffix := "View it at:\n\n    "
-	if solution.AutoApprove {
-		suffix = "You can complete the exercise and unlock the next core exercise at:\n"
-	}
-	fmt.Fprintf(Err, msg, suffix)
+			return fmt.Errorf(msg, what, file)
+		}
+		if exerciseConfig != nil {
+			if exerciseConfig.IsTest(rel) {
+				msg := `
+
+    WARNING: Submitting one of the exercise's test files
+             %s
+
+`
+				s.warned.print(Err, msg, file)
+				s.explain.add("Submit %s even though the exercise config lists it as a test file.", file)
+			} else if len(exerciseConfig.Files.Solution) > 0 && !exerciseConfig.IsSolution(rel) {
+				msg := `
+
+    WARNING: Submitting a file that is not one of the exercise's solution files
+             %s
+
+`
+				s.warned.print(Err, msg, file)
+				s.explain.add("Submit %s even though the exercise config doesn't list it as a solution file.", file)
+			}
+		}
+		kept = append(kept, file)
+	}
+	s.files = kept
+	return nil
+}
+
+// inspectSubmit reads the files, turns them into the documents to submit,
+// and checks that there is something sensible to submit. It's done if
+// nothing is to be submitted, e.g. because of --print-diff-only.
+func inspectSubmit(ctx context.Context, f submitFlags, s *submitState) (bool, error) {
+	files := s.files
+
+	// Reading the files one after the other is slow on network filesystems,
+	// so check them all concurrently before going through them in order.
+	inspections := make([]fileInspection, len(files))
+	s.texts = newTextFiles()
+	err := forEachConcurrently(len(files), maxFileWorkers, func(i int) error {
+		var err error
+		inspections[i], err = inspectFile(files[i], f.allowBinary, s.texts)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	s.timer.Mark("read files")
+
+	s.exercise.Documents = make([]workspace.Document, 0, len(files))
+	for i, file := range files {
+		inspection := inspections[i]
+
+		// Don't submit empty files
+		if inspection.size == 0 {
+
+			msg := `
+
+    WARNING: Skipping empty file
+             %s
+
+		`
+			s.warned.print(Err, msg, file)
+			s.explain.add("Skip %s, because it is empty.", file)
+			continue
+		}
+		if f.allowBinary {
+			s.explain.add("Don't check whether %s is text, because of --allow-binary.", file)
+		} else {
+			if kind := inspection.binaryKind; kind != "" {
+				msg := `
+
+    The file you are submitting looks like %s.
+
+        %s
+
+    Binary files, such as executables, images, and archives, are not
+    submitted by default, since they are rarely part of a solution.
+
+    If you really mean to submit it, call the command again with --allow-binary
+
+`
+				return false, fmt.Errorf(msg, kind, file)
+			}
+			ok := inspection.text
+			if !ok && f.strict {
+				msg := `
+
+    The file you are submitting is not UTF-8 text.
+
+        %s
+
+    If you really mean to submit it, call the command again with --allow-binary
+
+`
+				return false, fmt.Errorf(msg, file)
+			}
+			if !ok {
+				msg := `
+
+    WARNING: Submitting a file that is not UTF-8 text
+             %s
+
+    Pass --allow-binary to silence this warning, or --strict to refuse such files.
+
+`
+				s.warned.print(Err, msg, file)
+				s.explain.add("Submit %s even though it is not UTF-8 text. Pass --strict to refuse such files.", file)
+			}
+		}
+		doc, err := workspace.NewDocument(s.exercise.Filepath(), file)
+		if err != nil {
+			return false, err
+		}
+		s.exercise.Documents = append(s.exercise.Documents, doc)
+		s.explain.add("Include %s, uploaded as %s relative to the exercise.", file, doc.Path())
+	}
+
+	if collisions := caseCollisions(s.exercise.Documents); len(collisions) > 0 {
+		msg := `
+
+    Some of the files you are submitting have names that differ only in case.
+
+%s
+    They would overwrite each other on case-insensitive file systems, such
+    as the default ones on macOS and Windows. Rename or leave out all but
+    one of each group, and call the command again.
+
+`
+		return false, fmt.Errorf(msg, describeCollisions(collisions))
+	}
+
+	if f.resubmitLast {
+		if err := restoreLastSubmission(s); err != nil {
+			return false, err
+		}
+	}
+
+	if len(s.exercise.Documents) == 0 {
+		msg := `
+
+    No files found to submit.
+
+		`
+		return false, errors.New(msg)
+	}
+
+	if f.maxFiles > 0 && len(s.exercise.Documents) > f.maxFiles {
+		msg := `
+
+    You are about to submit %d files, which is more than the limit of %d.
+
+    If that's not what you meant, name the files to submit explicitly:
+
+        %s submit FILE1 [FILE2 ...]
+
+    If you really mean to submit all of them, raise the limit with --max-files,
+    or disable it with --max-files=0
+
+`
+		return false, fmt.Errorf(msg, len(s.exercise.Documents), f.maxFiles, BinaryName)
+	}
+
+	if ok, err := confirmLargeFiles(ctx, f, s); !ok || err != nil {
+		return true, err
+	}
+
+	s.timer.Mark("check files")
+
+	if f.printDiff || f.printDiffOnly {
+		for _, doc := range s.exercise.Documents {
+			if err := printSubmissionDiff(doc); err != nil {
+				return false, err
+			}
+		}
+		if f.printDiffOnly {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// restoreLastSubmission replaces the documents with the files as they were
+// last submitted, for --resubmit-last. They are restored to a temporary
+// directory, which is removed once the submission is over.
+func restoreLastSubmission(s *submitState) error {
+	tmpDir, err := ioutil.TempDir("", "exercism-resubmit")
+	if err != nil {
+		return err
+	}
+	s.tmpDir = tmpDir
+
+	docs, err := workspace.RestoreSnapshots(s.solution, tmpDir)
+	if os.IsNotExist(err) {
+		msg := `
+
+    There is no record of a previous submission to resend.
+
+        %s
+
+    Only submissions made with this version of the client can be resent.
+    To submit your current files, call the command without --resubmit-last
+
+`
+		return fmt.Errorf(msg, s.solution.Dir)
+	}
+	if err != nil {
+		return err
+	}
+	for _, doc := range docs {
+		s.explain.add("Include %s as it was last submitted, because of --resubmit-last.", doc.Path())
+	}
+	s.exercise.Documents = docs
+	return nil
+}
+
+// confirmLargeFiles asks before submitting files larger than --max-file-size.
+// It's false if they aren't to be submitted.
+func confirmLargeFiles(ctx context.Context, f submitFlags, s *submitState) (bool, error) {
+	sizeLimit, err := parseByteSize(f.maxFileSize)
+	if err != nil {
+		return false, fmt.Errorf("invalid --max-file-size: %s", err)
+	}
+	if sizeLimit <= 0 || f.force {
+		return true, nil
+	}
+	var large []workspace.Document
+	for _, doc := range s.exercise.Documents {
+		info, err := os.Stat(doc.Filepath())
+		if err != nil {
+			return false, err
+		}
+		if info.Size() > sizeLimit {
+			large = append(large, doc)
+			s.explain.add("Ask before submitting %s, because it is larger than %s.", doc.Path(), f.maxFileSize)
+		}
+	}
+	// Only ask when something is actually going to be submitted.
+	if len(large) == 0 || s.explain != nil || f.dryRun {
+		return true, nil
+	}
+	msg := `
+
+    WARNING: These files are larger than %s:
+
+%s
+    Large files are usually build output or dependencies, such as
+    compiled binaries or node_modules, rather than part of a solution.
+
+`
+	s.warned.print(Err, msg, formatByteSize(sizeLimit), describeLargestDocuments(large, len(large)))
+	if !canPrompt() {
+		msg := `
+    If you really mean to submit them, call the command again with --force,
+    or raise the limit with --max-file-size
+
+`
+		return false, errors.New(msg)
+	}
+	ok, err := newPrompter(In, Err).withContext(ctx).confirm("Submit them anyway?", false)
+	if err != nil {
+		return false, err
+	}
+	if !ok {
+		fmt.Fprintf(Err, "\n    Nothing was submitted.\n\n")
+	}
+	return ok, nil
+}
+
+// prepareUpload finds out what the API supports, and builds the body of the
+// request from the documents that need to be sent. It's done if nothing is
+// to be uploaded, e.g. because of --explain or --dry-run.
+func prepareUpload(f submitFlags, s *submitState) (bool, error) {
+	usrCfg := s.cfg.UserViperConfig
+
+	client, err := api.NewClient(s.token, usrCfg.GetString("apibaseurl"))
+	if err != nil {
+		return false, err
+	}
+	if !f.timeoutChanged && usrCfg.IsSet("timeout") {
+		client.Timeout = time.Duration(usrCfg.GetInt("timeout")) * time.Second
+	}
+	s.client = client
+
+	if f.dryRun {
+		s.capabilities = client.CachedCapabilities(s.cfg.Dir)
+	} else {
+		s.capabilities = client.Capabilities(s.cfg.Dir, f.refreshCapabilities)
+	}
+	debug.Printf("API capabilities: %+v\n", s.capabilities)
+	s.timer.Mark("check API capabilities")
+
+	if f.ifNewer && f.dryRun {
+		debug.Println("Not checking the last submission, because of --dry-run")
+	}
+	if f.ifNewer && !f.dryRun {
+		if err := checkNewerThanLastSubmission(s); err != nil {
+			return false, err
+		}
+		s.timer.Mark("check last submission")
+	}
+
+	replace, done, err := leaveOutUnchanged(f, s)
+	if done || err != nil {
+		return done, err
+	}
+	return buildSubmitBody(f, s, replace)
+}
+
+// checkNewerThanLastSubmission refuses to submit files that are all older
+// than the last submission, for --if-newer.
+func checkNewerThanLastSubmission(s *submitState) error {
+	submittedAt, err := s.client.LastSubmittedAt(s.solution.ID)
+	if err != nil {
+		return err
+	}
+	if submittedAt == nil {
+		return nil
+	}
+	var newest time.Time
+	for _, doc := range s.exercise.Documents {
+		info, err := os.Stat(doc.Filepath())
+		if err != nil {
+			return err
+		}
+		if info.ModTime().After(newest) {
+			newest = info.ModTime()
+		}
+	}
+	if !newest.After(*submittedAt) {
+		msg := `
+
+    None of the files have been modified since your last submission.
+
+        last submitted:  %s
+        last modified:   %s
+
+    You may be about to overwrite a newer iteration with stale files.
+    If you really mean to submit them, call the command again without --if-newer
+
+`
+		return fmt.Errorf(msg, submittedAt.Local().Format(time.RFC1123), newest.Local().Format(time.RFC1123))
+	}
+	s.explain.add("Go ahead despite --if-newer, because the files were modified after the last submission.")
+	return nil
+}
+
+// leaveOutUnchanged compares the documents with the last submission.
+// With --replace or --only-changed, it leaves out the ones that haven't
+// changed, and tells whether the API is to keep them. Otherwise it refuses
+// to submit the same files again. It's done if there is nothing to submit.
+func leaveOutUnchanged(f submitFlags, s *submitState) (replace, done bool, err error) {
+	docs := s.exercise.Documents
+	sums := make([]string, len(docs))
+	err = forEachConcurrently(len(docs), maxFileWorkers, func(i int) error {
+		var err error
+		sums[i], err = docs[i].Checksum()
+		return err
+	})
+	if err != nil {
+		return false, false, err
+	}
+	s.timer.Mark("hash files")
+	s.checksums = make(map[string]string, len(docs))
+	for i, doc := range docs {
+		s.checksums[doc.Path()] = sums[i]
+	}
+
+	// Both --replace and --only-changed leave out the unchanged files,
+	// so the API has to keep them from the last submission.
+	replace = f.replace
+	reason := "--replace"
+	if !replace && f.onlyChanged {
+		reason = "--only-changed"
+		replace = true
+	}
+	if replace && f.resubmitLast {
+		return false, false, fmt.Errorf("%s cannot be combined with --resubmit-last", reason)
+	}
+	if replace && len(s.solutions) > 1 {
+		return false, false, fmt.Errorf("%s cannot be combined with --also-submit-to", reason)
+	}
+	if replace && !s.capabilities.PartialUpdate {
+		const msg = `
+
+    WARNING: The API does not support replacing individual files.
+             Submitting all the files instead of using %s.
+
+`
+		s.warned.print(Err, msg, reason)
+		s.explain.add("Submit all the files despite %s, because the API can't replace individual files.", reason)
+		replace = false
+	}
+
+	// Files that were never submitted count as changed,
+	// so the first submission includes everything.
+	if replace {
+		changed := make([]workspace.Document, 0, len(docs))
+		for _, doc := range docs {
+			if s.solution.Checksums[doc.Path()] != s.checksums[doc.Path()] {
+				changed = append(changed, doc)
+				continue
+			}
+			s.explain.add("Leave out %s, because of %s and it hasn't changed since the last submission.", doc.Path(), reason)
+		}
+		if len(changed) == 0 {
+			msg := `
+
+    None of the files have changed since your last submission.
+    There is nothing to submit.
+
+`
+			fmt.Fprint(Err, msg)
+			return replace, true, nil
+		}
+		s.exercise.Documents = changed
+	}
+
+	// Resending the last submission is what --resubmit-last is for,
+	// so only guard against doing it by accident.
+	if !f.resubmitLast && !replace && !f.force && sameChecksums(s.solution.Checksums, s.checksums) {
+		msg := `
+
+    The files are identical to your last iteration%s.
+    Submitting them again would only add a duplicate iteration.
+
+    If you really mean to submit them again, call the command again with --force
+
+`
+		var when string
+		if s.solution.SubmittedAt != nil {
+			when = fmt.Sprintf(", submitted %s ago", time.Since(*s.solution.SubmittedAt).Round(time.Second))
+		}
+		return replace, false, fmt.Errorf(msg, when)
+	}
+	return replace, false, nil
+}
+
+// buildSubmitBody works out how each document is sent, and describes the
+// request body for --explain and --dry-run, which are then done.
+func buildSubmitBody(f submitFlags, s *submitState, replace bool) (bool, error) {
+	usrCfg := s.cfg.UserViperConfig
+	docs := s.exercise.Documents
+
+	threshold, err := parseByteSize(f.gzipThreshold)
+	if err != nil {
+		return false, fmt.Errorf("invalid --gzip-threshold: %s", err)
+	}
+	gzipMode := f.gzip
+	if !f.gzipChanged && usrCfg.GetString("gzip") != "" {
+		gzipMode = usrCfg.GetString("gzip")
+	}
+	var gzipBody bool
+	switch gzipMode {
+	case "auto":
+		gzipBody = s.capabilities.Gzip
+	case "always":
+		gzipBody = true
+	case "never":
+	default:
+		return false, fmt.Errorf("invalid --gzip '%s', expected auto, always, or never", gzipMode)
+	}
+	if gzipBody {
+		s.explain.add("Compress the request body.")
+	}
+
+	compressed := make(map[string]bool)
+	if threshold > 0 && gzipBody {
+		debug.Println("Not compressing files individually, because the whole body is compressed")
+		threshold = 0
+	}
+	if threshold > 0 && !s.capabilities.GzipParts {
+		debug.Println("Not compressing files, because the API doesn't support gzipped parts")
+		s.explain.add("Don't compress any files, because the API doesn't support it.")
+		threshold = 0
+	}
+	for _, doc := range docs {
+		ok, err := shouldGzipPart(doc, threshold, s.texts)
+		if err != nil {
+			return false, err
+		}
+		if ok {
+			compressed[doc.Path()] = true
+			s.explain.add("Compress %s, because it is text and at least %s.", doc.Path(), f.gzipThreshold)
+		}
+	}
+
+	normalize := f.normalize
+	if !f.normalizeChanged {
+		normalize = usrCfg.GetBool("normalize")
+	}
+	normalized := make(map[string]bool)
+	if normalize {
+		for _, doc := range docs {
+			ok, err := s.texts.isText(doc.Filepath())
+			if err != nil {
+				return false, err
+			}
+			if ok {
+				normalized[doc.Path()] = true
+				s.explain.add("Convert CRLF line endings to LF and drop any byte order mark in %s.", doc.Path())
+			}
+		}
+	}
+
+	message := strings.TrimSpace(f.message)
+	if message != "" {
+		s.explain.add("Attach the message %q to the iteration.", message)
+	}
+
+	if s.explain != nil {
+		for _, solution := range s.solutions {
+			s.explain.add("Send a PATCH request to %s with %d file(s).", submitURL(usrCfg.GetString("apibaseurl"), s.team, solution.ID), len(docs))
+		}
+		s.explain.Print(Err, "Here is what submitting would do:")
+		fmt.Fprintf(Err, "    Nothing was sent, because of --explain.\n\n")
+		return true, nil
+	}
+
+	boundary, err := multipartBoundary(f.multipartBoundary, docs)
+	if err != nil {
+		return false, err
+	}
+
+	s.contentType, err = submitContentType(boundary)
+	if err != nil {
+		return false, err
+	}
+	s.body = submitBody{
+		boundary:   boundary,
+		docs:       docs,
+		replace:    replace,
+		compressed: compressed,
+		gzipped:    gzipBody,
+		normalized: normalized,
+		message:    message,
+	}
+
+	if f.dryRun {
+		sizes := make(map[*workspace.Solution]int64, len(s.solutions))
+		for _, solution := range s.solutions {
+			size, err := s.body.size(solution)
+			if err != nil {
+				return false, err
+			}
+			sizes[solution] = size
+		}
+		w := Out
+		if f.json {
+			// The JSON report is all that goes to stdout.
+			w = Err
+		}
+		return true, printDryRun(w, s.body, s.solutions, sizes)
+	}
+
+	s.rate, err = parseByteRate(f.rateLimit)
+	if err != nil {
+		return false, err
+	}
+	return false, nil
+}
+
+// uploadSubmission sends the request body to a solution, retrying as the
+// flags allow, and returns what the API said about it.
+func uploadSubmission(ctx context.Context, f submitFlags, s *submitState, solution *workspace.Solution) (submitPayload, error) {
+	var payload submitPayload
+	client := s.client
+	url := submitURL(s.cfg.UserViperConfig.GetString("apibaseurl"), s.team, solution.ID)
+	// Keep the output clean when it's not for a person to watch.
+	showProgress := !f.quiet && f.format == "" && isTerminal(Out)
+
+	var size int64
+	if showProgress || s.capabilities.ChunkedUpload {
+		// Measuring the body means writing it twice, but only people watching,
+		// or who might be sending it in chunks, pay for it.
+		var err error
+		size, err = s.body.size(solution)
+		if err != nil {
+			return payload, err
+		}
+	}
+	chunked := s.capabilities.ChunkedUpload && size >= resumableThreshold
+
+	// Each attempt needs a body of its own, since a streamed body can only be read once.
+	var body io.ReadCloser
+	var progress *progressReader
+	defer func() {
+		if body != nil {
+			body.Close()
+		}
+	}()
+	newRequest := func() (*http.Request, error) {
+		if body != nil {
+			body.Close()
+		}
+		// It is streamed, so that large submissions don't have to fit in memory.
+		body = s.body.stream(solution)
+		reader := newThrottledReader(body, s.rate)
+		if showProgress {
+			progress = newProgressReader(reader, Out, size)
+			reader = progress
+		}
+		req, err := client.NewRequest("PATCH", url, reader)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", s.contentType)
+		if s.body.gzipped {
+			req.Header.Set("Content-Encoding", "gzip")
+		}
+		return req.WithContext(ctx), nil
+	}
+	// The API may have accepted a submission whose response was lost,
+	// and sending it again would make another iteration. So it's only
+	// sent again when it didn't get through, e.g. when the connection
+	// was refused, or a gateway answered 502 without passing it on.
+	policy := api.RetryPolicy{
+		Retries:    f.retries,
+		Backoff:    f.retryBackoff,
+		OnlyUnsent: true,
+		OnRetry: func(retry int, wait time.Duration, reason error) {
+			if progress != nil {
+				progress.Stop()
+			}
+			msg := `
+    Submitting failed: %s
+    Retrying in %s (retry %d of %d).
+`
+			fmt.Fprintf(Err, msg, reason, wait, retry, f.retries)
+		},
+	}
+
+	var resp *http.Response
+	var resumable bool
+	var err error
+	if chunked {
+		u := &resumableUpload{
+			client:   client,
+			url:      url,
+			solution: solution,
+			body:     s.body,
+			stateDir: s.cfg.Dir,
+			policy:   policy,
+			wrap: func(r io.Reader, offset, size int64) io.Reader {
+				r = newThrottledReader(r, s.rate)
+				if showProgress {
+					progress = newProgressReader(r, Out, size)
+					progress.read = offset
+					r = progress
+				}
+				return r
+			},
+		}
+		resp, err = u.send(ctx)
+		resumable = u.saved
+	} else {
+		resp, err = client.DoWithRetry(newRequest, policy)
+	}
+	if progress != nil {
+		progress.Stop()
+	}
+	if err != nil {
+		if ctx.Err() != nil {
+			fmt.Fprintf(Err, "\n    Submission cancelled.\n\n")
+		}
+		if resumable {
+			fmt.Fprintf(Err, "\n    Submit the same files again to resume the upload where it stopped.\n\n")
+		}
+		if ctx.Err() != nil {
+			return payload, errInterrupted
+		}
+		if isTimeout(err) {
+			msg := `
+
+    The submission timed out after %s.
+
+    The network may be slow, or the files large. Call the command again
+    with a longer --timeout (in seconds), or set a default with
+
+        %s config set timeout SECONDS
+
+`
+			return payload, fmt.Errorf(msg, client.Timeout, BinaryName)
+		}
+		return payload, err
+	}
+
+	bb := &bytes.Buffer{}
+	_, err = bb.ReadFrom(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return payload, err
+	}
+	// The payload is informational, so a response without one isn't an error.
+	_ = json.Unmarshal(bb.Bytes(), &payload)
+
+	if s.team != "" && resp.StatusCode == http.StatusNotFound {
+		msg := `
+
+    Unable to submit to the team '%s'.
+    Either the team doesn't exist, or you are not a member of it.
+
+    Check the team slug, or submit without --team to use your own account.
+
+`
+		return payload, fmt.Errorf(msg, s.team)
+	}
+	if resp.StatusCode == http.StatusRequestEntityTooLarge {
+		msg := `
+
+    The submission is too large for the API to accept.
+    These are the largest files in it:
+
+%s
+    If any of them are build artifacts or dependencies, such as a
+    node_modules directory, leave them out and submit again.
+
+`
+		return payload, fmt.Errorf(msg, describeLargestDocuments(s.exercise.Documents, 5))
+	}
+	if resp.StatusCode >= 400 {
+		return payload, submitAPIError(resp, payload, solution, s.cfg.UserViperConfig.GetString("apibaseurl"))
+	}
+	return payload, nil
+}
+
+// recordSubmission remembers what was submitted to a solution and when, so
+// that --replace and --print-diff can tell what changed, and --min-interval
+// how long ago it was.
+func recordSubmission(f submitFlags, s *submitState, solution *workspace.Solution) {
+	// Unless only changed files were sent, they are the complete set.
+	if solution.Checksums == nil || !s.body.replace {
+		solution.Checksums = map[string]string{}
+	}
+	for _, doc := range s.exercise.Documents {
+		solution.Checksums[doc.Path()] = s.checksums[doc.Path()]
+	}
+	now := time.Now()
+	solution.SubmittedAt = &now
+
+	archiveDir := f.archiveDir
+	if archiveDir == "" {
+		archiveDir = s.cfg.UserViperConfig.GetString("archivedir")
+	}
+	archiveDir = config.Resolve(archiveDir, s.cfg.Home)
+	if archiveDir != "" {
+		path, err := archiveSubmission(archiveDir, solution, s.exercise.Documents, now)
+		if err != nil {
+			s.warned.printAfterSubmit(Err, "archive the submission in "+path, err)
+		} else {
+			debug.Printf("Archived the submission in %s\n", path)
+		}
+	}
+	if err := solution.Write(solution.Dir); err != nil {
+		s.warned.printAfterSubmit(Err, "record the submission in "+solution.Dir, err)
+	}
+	// The snapshots are kept next to the files, which belong to the first solution.
+	if solution != s.solution {
+		return
+	}
+	for _, doc := range s.exercise.Documents {
+		if err := doc.WriteSnapshot(); err != nil {
+			s.warned.printAfterSubmit(Err, "keep a snapshot of "+doc.Filepath(), err)
+		}
+	}
+}
+
+// reportSubmission records a successful submission to a solution, and
+// describes it, unless it's to be reported in a format of its own.
+func reportSubmission(ctx context.Context, f submitFlags, s *submitState, solution *workspace.Solution, payload submitPayload) (submitResult, error) {
+	recordSubmission(f, s, solution)
+
+	// Older versions of the API don't describe the new iteration.
+	var iteration *api.Iteration
+	if payload.Iteration != nil && payload.Iteration.Number > 0 {
+		it := awaitTests(ctx, s.client, solution.ID, *payload.Iteration, f.waitForTests)
+		iteration = &it
+	}
+	var history []api.Iteration
+	if f.listIterations {
+		var err error
+		if history, err = s.client.Iterations(solution.ID); err != nil {
+			s.warned.printAfterSubmit(Err, fmt.Sprintf("list the iterations of %s", solution), err)
+		}
+	}
+
+	entry := historyEntry{
+		SubmittedAt: time.Now().UTC(),
+		Track:       solution.Track,
+		Exercise:    solution.Exercise,
+		SolutionID:  solution.ID,
+		Team:        s.team,
+		URL:         solution.URL,
+	}
+	if iteration != nil {
+		entry.Iteration = iteration.Number
+	}
+	for _, doc := range s.exercise.Documents {
+		entry.Files = append(entry.Files, doc.Path())
+	}
+	if err := appendHistory(s.cfg.Dir, entry); err != nil {
+		s.warned.printAfterSubmit(Err, "record the submission in the history", err)
+	}
+
+	if f.open {
+		if err := openBrowser(solution.URL); err != nil {
+			s.warned.printAfterSubmit(Err, fmt.Sprintf("open %s in the browser", solution.URL), err)
+		}
+	}
+
+	result := submitResult{
+		Track:      solution.Track,
+		Exercise:   solution.Exercise,
+		SolutionID: solution.ID,
+		Team:       s.team,
+		URL:        solution.URL,
+	}
+	if iteration != nil {
+		result.Iteration = iteration.Number
+		result.TestsStatus = iteration.TestsStatus
+	}
+	result.Iterations = history
+	for _, doc := range s.exercise.Documents {
+		result.Files = append(result.Files, doc.Path())
+	}
+	if s.output != nil {
+		return result, nil
+	}
+
+	msg := `
+
+    Your solution has been submitted successfully%s.
+    %s
+`
+	var recipient string
+	if s.team != "" {
+		recipient = fmt.Sprintf(" to the team '%s'", s.team)
+	}
+	suffix := "View it at:\n\n    "
+	if solution.AutoApprove {
+		suffix = "You can complete the exercise and unlock the next core exercise at:\n"
+	}
+	fmt.Fprintf(Err, msg, recipient, suffix)
 	fmt.Fprintf(Out, "    %s\n\n", solution.URL)
+
+	if iteration != nil {
+		fmt.Fprintf(Err, "    %s\n\n", describeIteration(*iteration))
+	}
+	if len(history) > 0 {
+		current := history[len(history)-1].Number
+		if iteration != nil {
+			current = iteration.Number
+		}
+		if err := printIterations(Err, history, current); err != nil {
+			return result, err
+		}
+	}
+
+	if next := payload.NextExercise; next != nil && next.ID != "" && next.Track.ID != "" {
+		msg := `    Once it's complete, download the next exercise with:
+
+`
+		fmt.Fprint(Err, msg)
+		fmt.Fprintf(Out, "        %s download --exercise=%s --track=%s\n\n", BinaryName, next.ID, next.Track.ID)
+	}
+	return result, nil
+}
+
+// reportSubmissions prints the results in the requested format, if any,
+// and fails if any of the submissions did.
+func reportSubmissions(f submitFlags, s *submitState, results submitResults, failures []submitFailure) error {
+	if f.json {
+		report := submitReport{
+			Warnings: append([]string{}, s.warned...),
+		}
+		if len(results) > 0 {
+			report.submitResult = &results[0]
+			report.AlsoSubmittedTo = results[1:]
+		}
+		for _, failure := range failures {
+			report.Failures = append(report.Failures, submitFailureReport{
+				Track:    failure.solution.Track,
+				Exercise: failure.solution.Exercise,
+				Error:    strings.TrimSpace(failure.err.Error()),
+			})
+		}
+		b, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(Out, "%s\n", b)
+	} else if s.output != nil {
+		if err := s.output.Format(Out, results); err != nil {
+			return err
+		}
+	}
+
+	if len(failures) > 0 && !f.continueOnError {
+		return failures[0].err
+	}
+	if len(failures) > 0 {
+		printSubmitSummary(len(s.solutions), failures)
+		return fmt.Errorf("%d of %d submissions failed", len(failures), len(s.solutions))
+	}
+	return nil
+}
+
+// errNoMatchingExercise explains that none of the exercises that a path
+// belongs to is the one named with --track and --exercise.
+func errNoMatchingExercise(path, trackID, exerciseSlug string) error {
+	var wanted []string
+	if exerciseSlug != "" {
+		wanted = append(wanted, fmt.Sprintf("the exercise '%s'", exerciseSlug))
+	}
+	if trackID != "" {
+		wanted = append(wanted, fmt.Sprintf("in the track '%s'", trackID))
+	}
+	if exerciseSlug == "" {
+		wanted[0] = "an exercise " + wanted[0]
+	}
+	msg := `
+
+    None of the exercises that this belongs to is %s.
+
+        %s
+
+    Please check the --track and --exercise flags.
+
+`
+	return fmt.Errorf(msg, strings.Join(wanted, " "), path)
+}
+
+// sameChecksums determines whether two sets of files, as checksums keyed
+// by their paths, are the same. Nothing is the same as an empty set.
+func sameChecksums(a, b map[string]string) bool {
+	if len(a) == 0 || len(a) != len(b) {
+		return false
+	}
+	for path, checksum := range a {
+		if b[path] != checksum {
+			return false
+		}
+	}
+	return true
+}
+
+// submitFailure records why a submission in a batch failed.
+type submitFailure struct {
+	solution *workspace.Solution
+	err      error
+}
+
+// printSubmitSummary lists the failures in a batch submission.
+func printSubmitSummary(total int, failures []submitFailure) {
+	fmt.Fprintf(Err, "\n    Submitted to %d of %d exercises.\n\n    Failed:\n\n", total-len(failures), total)
+	for _, f := range failures {
+		fmt.Fprintf(Err, "        %s/%s: %s\n", f.solution.Track, f.solution.Exercise, strings.TrimSpace(f.err.Error()))
+	}
+	fmt.Fprintln(Err)
+}
+
+// submitURL is the endpoint that a solution is submitted to.
+func submitURL(apiBaseURL, team, solutionID string) string {
+	if team != "" {
+		return config.TeamSolutionURL(apiBaseURL, team, solutionID)
+	}
+	return fmt.Sprintf("%s/solutions/%s", apiBaseURL, solutionID)
+}
+
+// expandGlobs expands arguments that are glob patterns, such as src/*.py,
+// since not every shell does that, e.g. cmd.exe on Windows.
+// An argument that names an existing file is used as is, even if it
+// looks like a pattern.
+func expandGlobs(args []string) ([]string, error) {
+	expanded := make([]string, 0, len(args))
+	for _, arg := range args {
+		if !strings.ContainsAny(arg, "*?[") {
+			expanded = append(expanded, arg)
+			continue
+		}
+		if _, err := os.Lstat(arg); err == nil {
+			expanded = append(expanded, arg)
+			continue
+		}
+
+		matches, err := filepath.Glob(arg)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern '%s': %s", arg, err)
+		}
+		if len(matches) == 0 {
+			msg := `
+
+    No files match the pattern.
+
+        %s
+
+`
+			return nil, fmt.Errorf(msg, arg)
+		}
+		expanded = append(expanded, matches...)
+	}
+	return expanded, nil
+}
+
+// uniqueStrings drops repeated strings, keeping the first occurrence of each.
+func uniqueStrings(strs []string) []string {
+	seen := make(map[string]bool, len(strs))
+	unique := strs[:0]
+	for _, s := range strs {
+		if !seen[s] {
+			seen[s] = true
+			unique = append(unique, s)
+		}
+	}
+	return unique
+}
+
+// defaultSolutionFiles are the files to submit for an exercise when none are named.
+// If the exercise config lists the solution files, those are used.
+// Otherwise it's the files in the exercise that are likely part of the solution.
+func defaultSolutionFiles(dir string, dereference bool) ([]string, error) {
+	exerciseConfig, err := workspace.NewExerciseConfig(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if exerciseConfig == nil || len(exerciseConfig.Files.Solution) == 0 {
+		files, err := solutionFilesInDir(dir, dereference)
+		if err != nil {
+			return nil, err
+		}
+		if len(files) == 0 {
+			return nil, errors.New("\n\n    No solution files found in the exercise.\n\n")
+		}
+		return files, nil
+	}
+
+	files := make([]string, 0, len(exerciseConfig.Files.Solution))
+	for _, file := range exerciseConfig.Files.Solution {
+		files = append(files, filepath.Join(dir, filepath.FromSlash(file)))
+	}
+	return files, nil
+}
+
+// solutionFilesInDir finds the files in a directory that are likely part of the solution.
+// The directory's real path is walked, so that the files can be located in the workspace.
+func solutionFilesInDir(dir string, dereference bool) ([]string, error) {
+	dir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return nil, err
+	}
+	all, err := workspace.CollectFiles(dir, dereference)
+	if err != nil {
+		return nil, err
+	}
+	files := make([]string, 0, len(all))
+	for _, file := range all {
+		rel, err := filepath.Rel(dir, file)
+		if err != nil {
+			return nil, err
+		}
+		if workspace.IsLikelySolutionFile(rel) && !workspace.IsEditorFile(rel) {
+			files = append(files, file)
+		}
+	}
+	return files, nil
+}
+
+// isTimeout reports whether err is the result of a network timeout.
+func isTimeout(err error) bool {
+	netErr, ok := err.(net.Error)
+	return ok && netErr.Timeout()
+}
+
+// caseCollisions groups the paths of documents that differ only in case.
+// Groups are in the order their first document was given.
+func caseCollisions(docs []workspace.Document) [][]string {
+	var keys []string
+	groups := map[string][]string{}
+	for _, doc := range docs {
+		key := strings.ToLower(doc.Path())
+		paths, ok := groups[key]
+		if !ok {
+			keys = append(keys, key)
+		}
+		seen := false
+		for _, path := range paths {
+			if path == doc.Path() {
+				seen = true
+			}
+		}
+		if !seen {
+			groups[key] = append(paths, doc.Path())
+		}
+	}
+
+	var collisions [][]string
+	for _, key := range keys {
+		if len(groups[key]) > 1 {
+			collisions = append(collisions, groups[key])
+		}
+	}
+	return collisions
+}
+
+// describeCollisions lists each group of colliding paths, one path per line,
+// with a blank line after each group.
+func describeCollisions(collisions [][]string) string {
+	var buf bytes.Buffer
+	for _, paths := range collisions {
+		for _, path := range paths {
+			fmt.Fprintf(&buf, "        %s\n", path)
+		}
+		buf.WriteString("\n")
+	}
+	return buf.String()
+}
+
+// describeLargestDocuments lists the n largest documents with their sizes,
+// one per line, largest first.
+func describeLargestDocuments(docs []workspace.Document, n int) string {
+	type sizedDoc struct {
+		path string
+		size int64
+	}
+	sized := make([]sizedDoc, 0, len(docs))
+	for _, doc := range docs {
+		info, err := os.Stat(doc.Filepath())
+		if err != nil {
+			continue
+		}
+		sized = append(sized, sizedDoc{path: doc.Path(), size: info.Size()})
+	}
+	sort.SliceStable(sized, func(i, j int) bool {
+		return sized[i].size > sized[j].size
+	})
+	if len(sized) > n {
+		sized = sized[:n]
+	}
+
+	var buf bytes.Buffer
+	for _, d := range sized {
+		fmt.Fprintf(&buf, "        %10s  %s\n", formatByteSize(d.size), d.path)
+	}
+	return buf.String()
+}
+
+// printDryRun describes the submission that would be sent, without sending it.
+func printDryRun(w io.Writer, submission submitBody, solutions []*workspace.Solution, sizes map[*workspace.Solution]int64) error {
+	for _, solution := range solutions {
+		fmt.Fprintf(w, "\n    Would submit %d file(s) to %s\n\n", len(submission.docs), solution.URL)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		for _, doc := range submission.docs {
+			info, err := os.Stat(doc.Filepath())
+			if err != nil {
+				return err
+			}
+			note := ""
+			if submission.compressed[doc.Path()] {
+				note = "(compressed)"
+			}
+			fmt.Fprintf(tw, "        %s\t%s\t%s\t%s\n", doc.Path(), doc.Filepath(), formatByteSize(info.Size()), note)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+		if submission.message != "" {
+			fmt.Fprintf(w, "\n    Message: %s\n", submission.message)
+		}
+		note := ""
+		if submission.gzipped {
+			note = " (compressed)"
+		}
+		fmt.Fprintf(w, "\n    Total payload: %s%s\n", formatByteSize(sizes[solution]), note)
+	}
+	fmt.Fprintf(w, "\n    Nothing was sent, because of --dry-run.\n\n")
+	return nil
+}
+
+// formatByteSize describes a number of bytes in a readable unit.
+func formatByteSize(n int64) string {
+	switch {
+	case n >= 1024*1024:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1024*1024))
+	case n >= 1024:
+		return fmt.Sprintf("%.1f KB", float64(n)/1024)
+	}
+	return fmt.Sprintf("%d bytes", n)
+}
+
+// submitPayload is the part of the API's response to a submission that we use.
+type submitPayload struct {
+	// NextExercise is the exercise that completing this one unlocks, if any.
+	NextExercise *struct {
+		ID    string `json:"id"`
+		Track struct {
+			ID string `json:"id"`
+		} `json:"track"`
+	} `json:"next_exercise"`
+	// Iteration is the iteration that the submission created.
+	Iteration *api.Iteration `json:"iteration"`
+	// Error explains why the submission was refused.
+	Error struct {
+		Type    string `json:"type"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// submitAPIError explains why the API refused a submission, and what to do about it.
+func submitAPIError(resp *http.Response, payload submitPayload, solution *workspace.Solution, apiBaseURL string) error {
+	switch {
+	case payload.Error.Type == "solution_not_found" || (payload.Error.Type == "" && resp.StatusCode == http.StatusNotFound):
+		msg := `
+
+    The website doesn't know about the solution you are submitting.
+    It may have been deleted, or belong to another account.
+
+    Please download the exercise again to get a solution of your own:
+
+        %s download --exercise=%s --track=%s
+
+`
+		return fmt.Errorf(msg, BinaryName, solution.Exercise, solution.Track)
+	case payload.Error.Type == "too_many_iterations" || (payload.Error.Type == "" && resp.StatusCode == http.StatusTooManyRequests):
+		var detail string
+		if payload.Error.Message != "" {
+			detail = "\n    " + payload.Error.Message
+		}
+		msg := `
+
+    You have submitted too many iterations of this solution.%s
+
+    Please wait a while before submitting again.
+
+`
+		return fmt.Errorf(msg, detail)
+	case payload.Error.Type == "invalid_token" || payload.Error.Type == "token_revoked" || resp.StatusCode == http.StatusUnauthorized:
+		msg := `
+
+    Your API token is no longer valid. It may have been reset.
+    Find your current token at
+
+        %s
+
+    Then run the configure command:
+
+        %s configure --token=YOUR_TOKEN
+
+`
+		return fmt.Errorf(msg, config.SettingsURL(apiBaseURL), BinaryName)
+	case payload.Error.Message != "":
+		return fmt.Errorf("API returned %s: %s", resp.Status, payload.Error.Message)
+	default:
+		return fmt.Errorf("API returned %s", resp.Status)
+	}
+}
+
+// submitResult describes a successful submission.
+type submitResult struct {
+	Track       string   `json:"track" yaml:"track"`
+	Exercise    string   `json:"exercise" yaml:"exercise"`
+	SolutionID  string   `json:"solution_id" yaml:"solution_id"`
+	Team        string   `json:"team,omitempty" yaml:"team,omitempty"`
+	URL         string   `json:"url" yaml:"url"`
+	Files       []string `json:"files" yaml:"files"`
+	Iteration   int      `json:"iteration,omitempty" yaml:"iteration,omitempty"`
+	TestsStatus string   `json:"tests_status,omitempty" yaml:"tests_status,omitempty"`
+	// Iterations are all the iterations of the solution, with --all-iterations-list.
+	Iterations []api.Iteration `json:"iterations,omitempty" yaml:"iterations,omitempty"`
+}
+
+type submitResults []submitResult
+
+// submitReport is what --json prints: the result of the submission,
+// along with the failures and warnings that came up.
+// The result is missing when nothing was submitted.
+type submitReport struct {
+	*submitResult
+	AlsoSubmittedTo []submitResult        `json:"also_submitted_to,omitempty"`
+	Failures        []submitFailureReport `json:"failures,omitempty"`
+	Warnings        []string              `json:"warnings"`
+}
+
+// submitFailureReport describes a failed submission in the JSON report.
+type submitFailureReport struct {
+	Track    string `json:"track"`
+	Exercise string `json:"exercise"`
+	Error    string `json:"error"`
+}
+
+// Columns implements tabular.
+func (r submitResults) Columns() []string {
+	return []string{"track", "exercise", "files", "url"}
+}
+
+// Rows implements tabular.
+func (r submitResults) Rows() [][]string {
+	rows := make([][]string, 0, len(r))
+	for _, result := range r {
+		rows = append(rows, []string{result.Track, result.Exercise, strconv.Itoa(len(result.Files)), result.URL})
+	}
+	return rows
+}
+
+// printSubmissionDiff shows how a document differs from the snapshot
+// taken when it was last submitted.
+func printSubmissionDiff(doc workspace.Document) error {
+	previous, err := doc.Snapshot()
+	if os.IsNotExist(err) {
+		fmt.Fprintf(Err, "\n%s: not submitted before\n", doc.Path())
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	current, err := ioutil.ReadFile(doc.Filepath())
+	if err != nil {
+		return err
+	}
+
+	diff := unifiedDiff("a/"+doc.Path(), "b/"+doc.Path(), previous, current)
+	if diff == "" {
+		fmt.Fprintf(Err, "\n%s: unchanged since the last submission\n", doc.Path())
+		return nil
+	}
+	fmt.Fprintf(Err, "\n%s", diff)
+	return nil
+}
+
+// submitBody describes the multipart request body of a submission.
+// Each target solution gets a body of its own, since it names the track and exercise.
+type submitBody struct {
+	boundary string
+	docs     []workspace.Document
+	// replace asks the API to replace just these files in the last submission.
+	replace bool
+	// compressed holds the paths of the documents that are gzipped individually.
+	compressed map[string]bool
+	// gzipped is whether the whole body is gzipped.
+	gzipped bool
+	// normalized holds the paths of the text documents whose line endings
+	// are converted to LF, and whose byte order mark is dropped.
+	normalized map[string]bool
+	// message is a note to attach to the iteration, if any.
+	message string
+}
+
+// write writes the body for submitting the documents to a solution,
+// compressing it if it is to be gzipped.
+func (b submitBody) write(w io.Writer, solution *workspace.Solution) error {
+	if !b.gzipped {
+		return b.writeMultipart(w, solution)
+	}
+	zw := gzip.NewWriter(w)
+	if err := b.writeMultipart(zw, solution); err != nil {
+		return err
+	}
+	return zw.Close()
+}
+
+// writeMultipart writes the multipart form with the documents.
+// Along with the files, it names the solution's track and exercise so that
+// the API can check them against the solution. APIs that don't know about
+// these fields ignore them.
+func (b submitBody) writeMultipart(w io.Writer, solution *workspace.Solution) error {
+	writer := multipart.NewWriter(w)
+	if err := writer.SetBoundary(b.boundary); err != nil {
+		return fmt.Errorf("invalid multipart boundary '%s': %s", b.boundary, err)
+	}
+
+	if err := writer.WriteField("track", solution.Track); err != nil {
+		return err
+	}
+	if err := writer.WriteField("exercise", solution.Exercise); err != nil {
+		return err
+	}
+	if b.replace {
+		if err := writer.WriteField("replace", "true"); err != nil {
+			return err
+		}
+	}
+	if b.message != "" {
+		if err := writer.WriteField("message", b.message); err != nil {
+			return err
+		}
+	}
+
+	for _, doc := range b.docs {
+		if err := writeFormFile(writer, doc, b.compressed[doc.Path()], b.normalized[doc.Path()]); err != nil {
+			return err
+		}
+	}
+	return writer.Close()
+}
+
+// build returns the whole body for a solution, and its content type.
+func (b submitBody) build(solution *workspace.Solution) ([]byte, string, error) {
+	contentType, err := submitContentType(b.boundary)
+	if err != nil {
+		return nil, "", err
+	}
+	body := &bytes.Buffer{}
+	if err := b.write(body, solution); err != nil {
+		return nil, "", err
+	}
+	return body.Bytes(), contentType, nil
+}
+
+// stream returns the body for a solution as a reader.
+// The body is written as it is read, so only a small part of it is ever in memory.
+// Closing the reader stops the writing.
+func (b submitBody) stream(solution *workspace.Solution) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(b.write(pw, solution))
+	}()
+	return pr
+}
+
+// size is the size of the body for a solution.
+func (b submitBody) size(solution *workspace.Solution) (int64, error) {
+	var n countingWriter
+	if err := b.write(&n, solution); err != nil {
+		return 0, err
+	}
+	return int64(n), nil
+}
+
+// submitContentType is the content type of a submission body with the given boundary.
+func submitContentType(boundary string) (string, error) {
+	writer := multipart.NewWriter(ioutil.Discard)
+	if err := writer.SetBoundary(boundary); err != nil {
+		return "", fmt.Errorf("invalid multipart boundary '%s': %s", boundary, err)
+	}
+	return writer.FormDataContentType(), nil
+}
+
+// countingWriter discards what is written to it, counting the bytes.
+type countingWriter int64
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	*c += countingWriter(len(p))
+	return len(p), nil
+}
+
+func writeFormFile(writer *multipart.Writer, doc workspace.Document, gzipped, normalized bool) error {
+	file, err := os.Open(doc.Filepath())
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	h := formFileHeader("files[]", doc.Path())
+	if gzipped {
+		h.Set("Content-Encoding", "gzip")
+	}
+	part, err := writer.CreatePart(h)
+	if err != nil {
+		return err
+	}
+
+	var w io.Writer = part
+	var zw *gzip.Writer
+	if gzipped {
+		zw = gzip.NewWriter(part)
+		w = zw
+	}
+	if normalized {
+		lw := newLineEndingWriter(w)
+		if _, err := io.Copy(lw, file); err != nil {
+			return err
+		}
+		if err := lw.Flush(); err != nil {
+			return err
+		}
+	} else if _, err := io.Copy(w, file); err != nil {
+		return err
+	}
+	if zw != nil {
+		return zw.Close()
+	}
 	return nil
 }
 
+// shouldGzipPart decides whether to compress a document on its own.
+// Only text is worth it; most binary formats are compressed already.
+func shouldGzipPart(doc workspace.Document, threshold int64, texts *textFiles) (bool, error) {
+	if threshold <= 0 {
+		return false, nil
+	}
+	info, err := os.Stat(doc.Filepath())
+	if err != nil {
+		return false, err
+	}
+	if info.Size() < threshold {
+		return false, nil
+	}
+	return texts.isText(doc.Filepath())
+}
+
+// randomBoundary generates a random multipart boundary.
+var randomBoundary = func() string {
+	return multipart.NewWriter(nil).Boundary()
+}
+
+// multipartBoundary picks a boundary that doesn't occur in any of the documents,
+// since a file containing the boundary would corrupt the request body.
+// A custom boundary is used as is, unless it collides.
+func multipartBoundary(custom string, docs []workspace.Document) (string, error) {
+	collides := func(boundary string) (bool, error) {
+		for _, doc := range docs {
+			ok, err := fileContains(doc.Filepath(), []byte(boundary))
+			if ok || err != nil {
+				return ok, err
+			}
+		}
+		return false, nil
+	}
+
+	if custom != "" {
+		ok, err := collides(custom)
+		if err != nil {
+			return "", err
+		}
+		if ok {
+			return "", fmt.Errorf("the multipart boundary '%s' occurs in the files being submitted. Choose a different one", custom)
+		}
+		return custom, nil
+	}
+
+	const attempts = 10
+	for i := 0; i < attempts; i++ {
+		boundary := randomBoundary()
+		ok, err := collides(boundary)
+		if err != nil {
+			return "", err
+		}
+		if !ok {
+			return boundary, nil
+		}
+		debug.Printf("Multipart boundary %s occurs in the submission, generating another one\n", boundary)
+	}
+	return "", errors.New("unable to generate a multipart boundary that doesn't occur in the files being submitted")
+}
+
+// fileContains reports whether a file contains b, reading it a chunk at a time
+// rather than all at once.
+func fileContains(path string, b []byte) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	// Carry the end of each chunk over to the next, in case b straddles them.
+	overlap := len(b) - 1
+	buf := make([]byte, overlap+32*1024)
+	n := 0
+	for {
+		m, err := f.Read(buf[n:])
+		n += m
+		if bytes.Contains(buf[:n], b) {
+			return true, nil
+		}
+		if err == io.EOF {
+			return false, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if n > overlap {
+			copy(buf, buf[n-overlap:n])
+			n = overlap
+		}
+	}
+}
+
+// fileInspection is what submit needs to know about a file before submitting it.
+type fileInspection struct {
+	size int64
+	// binaryKind describes what a binary file looks like. It's empty for text.
+	binaryKind string
+	// text is whether the file is UTF-8 text.
+	text bool
+}
+
+// inspectFile reads a file to find out whether it can be submitted.
+// Unless binary files are allowed, it checks what kind of file it is.
+func inspectFile(path string, allowBinary bool, texts *textFiles) (fileInspection, error) {
+	var inspection fileInspection
+	info, err := os.Stat(path)
+	if err != nil {
+		return inspection, err
+	}
+	inspection.size = info.Size()
+	if inspection.size == 0 || allowBinary {
+		return inspection, nil
+	}
+	if inspection.binaryKind, err = binaryKind(path); err != nil {
+		return inspection, err
+	}
+	if inspection.binaryKind != "" {
+		return inspection, nil
+	}
+	inspection.text, err = texts.isText(path)
+	return inspection, err
+}
+
+// executableMagic are the signatures that executables start with.
+var executableMagic = [][]byte{
+	[]byte("\x7fELF"),          // Linux and most Unixes
+	[]byte("MZ"),               // Windows
+	[]byte("\xfe\xed\xfa\xce"), // macOS, 32-bit
+	[]byte("\xfe\xed\xfa\xcf"), // macOS, 64-bit
+	[]byte("\xce\xfa\xed\xfe"), // macOS, 32-bit, little-endian
+	[]byte("\xcf\xfa\xed\xfe"), // macOS, 64-bit, little-endian
+}
+
+// archiveTypes are the media types detected by http.DetectContentType
+// that are archives.
+var archiveTypes = map[string]bool{
+	"application/zip":              true,
+	"application/x-gzip":           true,
+	"application/x-rar-compressed": true,
+}
+
+// binaryKind sniffs the start of a file to determine whether it's binary.
+// If it is, it describes what the file looks like, e.g. "an executable".
+// Text, including text that isn't valid UTF-8, gives an empty description.
+func binaryKind(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	// That's as much as http.DetectContentType considers.
+	head := make([]byte, 512)
+	n, err := io.ReadFull(f, head)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return "", err
+	}
+	head = head[:n]
+
+	mediaType := strings.SplitN(http.DetectContentType(head), ";", 2)[0]
+	switch {
+	case strings.HasPrefix(mediaType, "text/"):
+		return "", nil
+	case strings.HasPrefix(mediaType, "image/"):
+		return fmt.Sprintf("an image (%s)", mediaType), nil
+	case strings.HasPrefix(mediaType, "audio/"), strings.HasPrefix(mediaType, "video/"):
+		return fmt.Sprintf("a media file (%s)", mediaType), nil
+	case archiveTypes[mediaType]:
+		return fmt.Sprintf("an archive (%s)", mediaType), nil
+	case mediaType == "application/pdf":
+		return "a PDF document", nil
+	}
+	for _, magic := range executableMagic {
+		if bytes.HasPrefix(head, magic) {
+			return "an executable", nil
+		}
+	}
+	return "binary data", nil
+}
+
+// textFiles remembers which files are text, so that each file is only read
+// once however many steps of a submission need to know.
+// It's safe for concurrent use.
+type textFiles struct {
+	mu    sync.Mutex
+	known map[string]bool
+}
+
+func newTextFiles() *textFiles {
+	return &textFiles{known: make(map[string]bool)}
+}
+
+// isText is like the isText function, but only reads each file once.
+func (t *textFiles) isText(path string) (bool, error) {
+	path = filepath.Clean(path)
+	t.mu.Lock()
+	ok, seen := t.known[path]
+	t.mu.Unlock()
+	if seen {
+		return ok, nil
+	}
+	ok, err := isText(path)
+	if err != nil {
+		return false, err
+	}
+	t.mu.Lock()
+	t.known[path] = ok
+	t.mu.Unlock()
+	return ok, nil
+}
+
+// isText determines whether a file contains valid UTF-8 text.
+// It stops reading at the first thing that isn't.
+func isText(path string) (bool, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	r := bufio.NewReaderSize(f, 32<<10)
+	for {
+		c, size, err := r.ReadRune()
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+		if c == 0 || (c == utf8.RuneError && size == 1) {
+			return false, nil
+		}
+	}
+}
+
+// formFileHeader is like the header of multipart.Writer.CreateFormFile, but it
+// also provides an RFC 5987 encoded filename for paths that contain non-ASCII
+// characters, so that they arrive at the server intact.
+func formFileHeader(fieldname, filename string) textproto.MIMEHeader {
+	disposition := fmt.Sprintf(`form-data; name="%s"; filename="%s"`, escapeQuotes(fieldname), escapeQuotes(filename))
+	if !isASCII(filename) {
+		disposition = fmt.Sprintf("%s; filename*=UTF-8''%s", disposition, encodeRFC5987(filename))
+	}
+
+	h := make(textproto.MIMEHeader)
+	h.Set("Content-Disposition", disposition)
+	h.Set("Content-Type", "application/octet-stream")
+	return h
+}
+
+var quoteEscaper = strings.NewReplacer("\\", "\\\\", `"`, "\\\"")
+
+func escapeQuotes(s string) string {
+	return quoteEscaper.Replace(s)
+}
+
+func isASCII(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] >= utf8.RuneSelf {
+			return false
+		}
+	}
+	return true
+}
+
+// encodeRFC5987 percent-encodes every byte that isn't an RFC 5987 attr-char.
+func encodeRFC5987(s string) string {
+	const attrChars = "!#$&+-.^_`|~"
+	var buf bytes.Buffer
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' || strings.IndexByte(attrChars, c) >= 0 {
+			buf.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&buf, "%%%02X", c)
+	}
+	return buf.String()
+}
+
+// resolveSubmitTarget finds the solution for a track/exercise pair in the workspace.
+func resolveSubmitTarget(ws workspace.Workspace, target string) (*workspace.Solution, error) {
+	parts := strings.Split(target, "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid target '%s'. Use the format TRACK/EXERCISE", target)
+	}
+
+	exercise := workspace.Exercise{Root: ws.Dir, Track: parts[0], Slug: parts[1]}
+	ok, err := exercise.HasMetadata()
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		msg := `
+
+    Cannot find the exercise %s in your workspace.
+    Please download it first:
+
+        %s download --exercise=%s --track=%s
+
+		`
+		return nil, fmt.Errorf(msg, target, BinaryName, exercise.Slug, exercise.Track)
+	}
+
+	solution, err := workspace.NewSolution(exercise.MetadataDir())
+	if err != nil {
+		return nil, err
+	}
+	if !solution.IsRequester {
+		return nil, fmt.Errorf("the solution for %s is not connected to your account", target)
+	}
+	return solution, nil
+}
+
+func setupSubmitFlags(flags *pflag.FlagSet) {
+	flags.StringP("token-file", "", "", "read the API token from this file (also settable with "+config.TokenFileEnvVar+")")
+	flags.BoolP("dry-run", "", false, "list the files that would be submitted and the payload size, without contacting the API")
+	flags.BoolP("explain", "", false, "describe what submitting would do and why, without sending anything")
+	flags.IntP("max-files", "", 100, "refuse to submit more than this many files; 0 means no limit")
+	flags.BoolP("resubmit-last", "", false, "resend the files exactly as they were last submitted, ignoring local changes")
+	flags.IntP("min-interval", "", 0, "refuse to submit if the last submission was less than this many seconds ago; 0 means no limit")
+	flags.BoolP("force", "", false, "submit even if the files are identical to the last iteration, the last submission was within --min-interval, or files are larger than --max-file-size")
+	flags.StringP("max-file-size", "", "1m", "ask before submitting files larger than this (e.g. 500k); 0 means no limit")
+	flags.StringP("archive-dir", "", "", "keep a copy of each successful submission in a timestamped folder in this directory")
+	flags.BoolP("only-changed", "", false, "only submit the files that changed since the last submission, keeping the others from it")
+	flags.BoolP("dereference", "", false, "when submitting a directory, follow symlinks to other directories")
+	flags.BoolP("refresh-capabilities", "", false, "fetch the API's supported features again rather than using the cached ones")
+	flags.BoolP("trace", "", false, "print how long each phase of the submission takes; the request body is built as it's sent, so that's part of the upload")
+	flags.BoolP("allow-binary", "", false, "submit binary files, such as images, and files that are not UTF-8 text, without warning")
+	flags.BoolP("strict", "", false, "refuse to submit files that are not UTF-8 text")
+	flags.BoolP("replace", "", false, "only send the files that changed since the last submission, replacing them in that submission")
+	flags.BoolP("print-diff", "", false, "show what changed in each file since the last submission before submitting")
+	flags.BoolP("print-diff-only", "", false, "show what changed in each file since the last submission without submitting")
+	flags.BoolP("if-newer", "", false, "refuse to submit unless the files were modified after the last submission")
+	flags.StringP("rate-limit", "", "0", "limit the upload speed, in bytes per second (e.g. 500k); 0 means unlimited")
+	flags.StringP("gzip", "", "auto", "compress the request body: auto (if the API supports it), always, or never (also settable with the gzip config setting)")
+	flags.StringP("gzip-threshold", "", "64k", "compress text files at least this big, if the API supports it; 0 means never")
+	flags.StringP("track", "t", "", "the track of the exercise to submit to, when exercises are nested in one another")
+	flags.StringP("exercise", "e", "", "the exercise to submit to, when exercises are nested in one another")
+	flags.StringP("team", "", "", "submit on behalf of the team with this slug (defaults to the team in the config, if any)")
+	flags.StringP("multipart-boundary", "", "", "use this boundary in the request body instead of a random one")
+	flags.StringP("format", "", "", "print the result as table, json, or yaml instead of a message")
+	flags.BoolP("json", "", false, "print the result and any warnings as a JSON object, and everything else to stderr")
+	flags.StringArrayP("also-submit-to", "", []string{}, "also submit the same files to another exercise, as TRACK/EXERCISE (repeatable)")
+	flags.IntP("retries", "", 2, "retry this many times when the API can't be reached, or a gateway in front of it turns the submission away")
+	flags.DurationP("retry-backoff", "", time.Second, "wait this long before the first retry, doubling the wait for each retry after that")
+	flags.StringP("message", "m", "", "attach a note to the iteration, e.g. to tell mentors what changed")
+	flags.BoolP("open", "", false, "open the submitted solution in the browser")
+	flags.BoolP("all-iterations-list", "", false, "after submitting, list the recent iterations of the solution and their test results")
+	flags.DurationP("wait-for-tests", "", 5*time.Second, "wait up to this long for the automated tests to finish after submitting; 0 means don't wait")
+	flags.BoolP("quiet", "q", false, "don't show the upload progress")
+	flags.BoolP("normalize", "", false, "convert CRLF line endings to LF and drop byte order marks in text files (also settable with the normalize config setting)")
+	flags.BoolP("history", "", false, "list the submissions made from this computer, instead of submitting")
+	flags.BoolP("pick", "", false, "when submitting a directory, or without naming any files, choose the files to submit from a list, even outside a terminal")
+	flags.BoolP("no-pick", "", false, "when submitting a directory, or without naming any files, submit the solution files without choosing them from a list")
+	flags.BoolP("no-verify", "", false, "don't run the pre-submit command from the config")
+	flags.BoolP("watch", "w", false, "keep running, and submit again whenever the files change")
+	flags.BoolP("continue-on-error", "", false, "when submitting to several exercises, keep going after a failure and summarize the results")
+}
+
 func init() {
 	RootCmd.AddCommand(submitCmd)
+	setupSubmitFlags(submitCmd.Flags())
+	// This is meant for reproducible testing, not everyday use.
+	submitCmd.Flags().MarkHidden("multipart-boundary")
 }
@@ -3,6 +3,7 @@
 package cmd
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -60,7 +61,10 @@ func TestSubmitFilesInSymlinkedPath(t *testing.T) {
 	err = ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte("This is a file."), os.FileMode(0755))
 	assert.NoError(t, err)
 
-	err = runSubmit(cfg, pflag.NewFlagSet("symlinks", pflag.PanicOnError), []string{file})
+	flags := pflag.NewFlagSet("symlinks", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(submittedFiles))
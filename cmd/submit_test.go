@@ -1,12 +1,24 @@
 package cmd
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"mime"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/exercism/cli/config"
 	"github.com/exercism/cli/workspace"
@@ -21,7 +33,10 @@ func TestSubmitWithoutToken(t *testing.T) {
 		UserViperConfig: viper.New(),
 	}
 
-	err := runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), []string{})
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err := runSubmit(context.Background(), cfg, flags, []string{})
 	assert.Regexp(t, "Welcome to Exercism", err.Error())
 	assert.Regexp(t, "exercism.io/my/settings", err.Error())
 }
@@ -36,7 +51,10 @@ func TestSubmitWithoutWorkspace(t *testing.T) {
 		DefaultBaseURL:  "http://example.com",
 	}
 
-	err := runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), []string{})
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err := runSubmit(context.Background(), cfg, flags, []string{})
 	assert.Regexp(t, "re-run the configure", err.Error())
 }
 
@@ -65,7 +83,10 @@ func TestSubmitNonExistentFile(t *testing.T) {
 		"no-such-file.txt",
 		filepath.Join(tmpDir, "file-2.txt"),
 	}
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), files)
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, files)
 	assert.Regexp(t, "cannot be found", err.Error())
 }
 
@@ -91,39 +112,111 @@ func TestSubmitExerciseWithoutSolutionMetadataFile(t *testing.T) {
 		UserViperConfig: v,
 	}
 
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), []string{file})
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
 	assert.Error(t, err)
 	assert.Regexp(t, "doesn't have the necessary metadata", err.Error())
 }
 
 func TestSubmitFilesAndDir(t *testing.T) {
-	tmpDir, err := ioutil.TempDir("", "submit-no-such-file")
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-files-and-dir")
 	defer os.RemoveAll(tmpDir)
 	assert.NoError(t, err)
 
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "lib"), os.FileMode(0755))
+	os.MkdirAll(filepath.Join(dir, "docs"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	for _, name := range []string{"bogus.go", "bogus_test.go", "README.md", "lib/helper.go", "docs/notes.txt"} {
+		err = ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte("This is "+name), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
 	v := viper.New()
 	v.Set("token", "abc123")
 	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
 
 	cfg := config.Config{
 		Persister:       config.InMemoryPersister{},
 		UserViperConfig: v,
-		DefaultBaseURL:  "http://example.com",
 	}
 
-	err = ioutil.WriteFile(filepath.Join(tmpDir, "file-1.txt"), []byte("This is file 1"), os.FileMode(0755))
+	// Tests and documentation in the directory are left out,
+	// unless they are named explicitly.
+	files := []string{
+		filepath.Join(dir, "README.md"),
+		filepath.Join(dir, "lib"),
+		dir,
+	}
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("no-pick", "true")
+	flags.Set("format", "json")
+
+	var out bytes.Buffer
+	Out = &out
+	err = runSubmit(context.Background(), cfg, flags, files)
 	assert.NoError(t, err)
 
-	err = ioutil.WriteFile(filepath.Join(tmpDir, "file-2.txt"), []byte("This is file 2"), os.FileMode(0755))
+	// Files that were named twice are only submitted once.
+	var results []submitResult
+	assert.NoError(t, json.Unmarshal(out.Bytes(), &results))
+	if assert.Len(t, results, 1) {
+		assert.Len(t, results[0].Files, 4)
+	}
+	assert.Equal(t, map[string]string{
+		"README.md":      "This is README.md",
+		"bogus.go":       "This is bogus.go",
+		"docs/notes.txt": "This is docs/notes.txt",
+		"lib/helper.go":  "This is lib/helper.go",
+	}, submittedFiles)
+}
+
+func TestSubmitDirWithoutSolutionFiles(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "submit-empty-dir")
+	defer os.RemoveAll(tmpDir)
 	assert.NoError(t, err)
-	files := []string{
-		filepath.Join(tmpDir, "file-1.txt"),
-		tmpDir,
-		filepath.Join(tmpDir, "file-2.txt"),
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+	err = ioutil.WriteFile(filepath.Join(dir, "bogus_test.go"), []byte("a test"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+		DefaultBaseURL:  "http://example.com",
 	}
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), files)
-	assert.Regexp(t, "submitting a directory", err.Error())
-	assert.Regexp(t, "Please change into the directory and provide the path to the file\\(s\\) you wish to submit", err.Error())
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("no-pick", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{dir})
+	assert.Error(t, err)
+	assert.Regexp(t, "No solution files found in the directory", err.Error())
 }
 
 func TestSubmitFiles(t *testing.T) {
@@ -145,7 +238,7 @@ func TestSubmitFiles(t *testing.T) {
 	assert.NoError(t, err)
 
 	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
-	os.MkdirAll(filepath.Join(dir, "subdir"), os.FileMode(0755))
+	os.MkdirAll(filepath.Join(dir, "subdir", "deeper"), os.FileMode(0755))
 	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
 
 	file1 := filepath.Join(dir, "file-1.txt")
@@ -156,6 +249,10 @@ func TestSubmitFiles(t *testing.T) {
 	err = ioutil.WriteFile(file2, []byte("This is file 2."), os.FileMode(0755))
 	assert.NoError(t, err)
 
+	file3 := filepath.Join(dir, "subdir", "deeper", "file-3.txt")
+	err = ioutil.WriteFile(file3, []byte("This is file 3."), os.FileMode(0755))
+	assert.NoError(t, err)
+
 	// We don't filter *.md files if you explicitly pass the file path.
 	readme := filepath.Join(dir, "README.md")
 	err = ioutil.WriteFile(readme, []byte("This is the readme."), os.FileMode(0755))
@@ -173,15 +270,19 @@ func TestSubmitFiles(t *testing.T) {
 	}
 
 	files := []string{
-		file1, file2, readme,
+		file1, file2, file3, readme,
 	}
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), files)
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, files)
 	assert.NoError(t, err)
 
-	assert.Equal(t, 3, len(submittedFiles))
+	assert.Equal(t, 4, len(submittedFiles))
 
 	assert.Equal(t, "This is file 1.", submittedFiles["file-1.txt"])
 	assert.Equal(t, "This is file 2.", submittedFiles["subdir/file-2.txt"])
+	assert.Equal(t, "This is file 3.", submittedFiles["subdir/deeper/file-3.txt"])
 	assert.Equal(t, "This is the readme.", submittedFiles["README.md"])
 }
 
@@ -224,7 +325,10 @@ func TestSubmitWithEmptyFile(t *testing.T) {
 	file2 := filepath.Join(dir, "file-2.txt")
 	err = ioutil.WriteFile(file2, []byte("This is file 2."), os.FileMode(0755))
 
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), []string{file1, file2})
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file1, file2})
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(submittedFiles))
@@ -273,7 +377,10 @@ func TestSubmitFilesForTeamExercise(t *testing.T) {
 	files := []string{
 		file1, file2,
 	}
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), files)
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, files)
 	assert.NoError(t, err)
 
 	assert.Equal(t, 2, len(submittedFiles))
@@ -313,7 +420,10 @@ func TestSubmitOnlyEmptyFile(t *testing.T) {
 	file := filepath.Join(dir, "file.txt")
 	err = ioutil.WriteFile(file, []byte(""), os.FileMode(0755))
 
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), []string{file})
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
 	assert.Error(t, err)
 	assert.Regexp(t, "No files found", err.Error())
 }
@@ -349,13 +459,20 @@ func TestSubmitFilesFromDifferentSolutions(t *testing.T) {
 		UserViperConfig: v,
 	}
 
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), []string{file1, file2})
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file1, file2})
 	assert.Error(t, err)
 	assert.Regexp(t, "different solutions", err.Error())
 }
 
 func fakeSubmitServer(t *testing.T, submittedFiles map[string]string) *httptest.Server {
 	handler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
 		err := r.ParseMultipartForm(2 << 10)
 		if err != nil {
 			t.Fatal(err)
@@ -373,7 +490,13 @@ func fakeSubmitServer(t *testing.T, submittedFiles map[string]string) *httptest.
 			if err != nil {
 				t.Fatal(err)
 			}
-			submittedFiles[fileHeader.Filename] = string(body)
+			// Newer versions of Go strip the directory from fileHeader.Filename,
+			// so read the full path from the header.
+			_, params, err := mime.ParseMediaType(fileHeader.Header.Get("Content-Disposition"))
+			if err != nil {
+				t.Fatal(err)
+			}
+			submittedFiles[params["filename"]] = string(body)
 		}
 	})
 	return httptest.NewServer(handler)
@@ -414,10 +537,17 @@ func TestSubmitRelativePath(t *testing.T) {
 
 	err = ioutil.WriteFile(filepath.Join(dir, "file.txt"), []byte("This is a file."), os.FileMode(0755))
 
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+
 	err = os.Chdir(dir)
 	assert.NoError(t, err)
 
-	err = runSubmit(cfg, pflag.NewFlagSet("fake", pflag.PanicOnError), []string{"file.txt"})
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{"file.txt"})
 	assert.NoError(t, err)
 
 	assert.Equal(t, 1, len(submittedFiles))
@@ -435,3 +565,3736 @@ func writeFakeSolution(t *testing.T, dir, trackID, exerciseSlug string) {
 	err := solution.Write(dir)
 	assert.NoError(t, err)
 }
+
+func TestSubmitWithTrace(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var buf bytes.Buffer
+	Err = &buf
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-trace")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("hooks.presubmit", []interface{}{
+		map[string]interface{}{"track": "bogus-track", "command": "exit 0"},
+	})
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("trace", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+
+	// The phases are listed in the order they happen.
+	var phases []string
+	for _, line := range strings.Split(buf.String(), "\n") {
+		if i := strings.Index(line, "  "); i > 0 && !strings.HasPrefix(line, " ") {
+			phases = append(phases, line[:i])
+		}
+	}
+	expected := []string{
+		"Phase",
+		"resolve arguments",
+		"run pre-submit command",
+		"read files",
+		"check files",
+		"check API capabilities",
+		"hash files",
+		"upload",
+		"report",
+		"total",
+	}
+	assert.Equal(t, expected, phases)
+}
+
+func TestSubmitAlsoSubmitTo(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			requests = append(requests, r.URL.Path)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "also-submit-to")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	for _, slug := range []string{"bogus-exercise", "bogus-variant"} {
+		dir := filepath.Join(tmpDir, "bogus-track", slug)
+		os.MkdirAll(dir, os.FileMode(0755))
+		solution := &workspace.Solution{
+			ID:          slug + "-uuid",
+			Track:       "bogus-track",
+			Exercise:    slug,
+			IsRequester: true,
+		}
+		err = solution.Write(dir)
+		assert.NoError(t, err)
+	}
+
+	file := filepath.Join(tmpDir, "bogus-track", "bogus-exercise", "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("also-submit-to", "bogus-track/bogus-variant")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"/solutions/bogus-exercise-uuid", "/solutions/bogus-variant-uuid"}, requests)
+
+	// The submission is recorded for every solution it went to.
+	for _, slug := range []string{"bogus-exercise", "bogus-variant"} {
+		solution, err := workspace.NewSolution(filepath.Join(tmpDir, "bogus-track", slug))
+		assert.NoError(t, err)
+		assert.NotNil(t, solution.SubmittedAt, slug)
+		assert.Contains(t, solution.Checksums, "file.txt", slug)
+	}
+
+	// Nothing is submitted if a target can't be resolved.
+	requests = nil
+	for _, target := range []string{"bogus-track/no-such-exercise", "not-a-target"} {
+		flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		flags.Set("also-submit-to", "bogus-track/bogus-variant")
+		flags.Set("also-submit-to", target)
+
+		err = runSubmit(context.Background(), cfg, flags, []string{file})
+		assert.Error(t, err, target)
+		assert.Equal(t, 0, len(requests), target)
+	}
+}
+
+func TestSubmitNonASCIIFilenames(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	// The fake endpoint will populate this when it receives the call from the command.
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-non-ascii")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "日本語"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file1 := filepath.Join(dir, "café.txt")
+	err = ioutil.WriteFile(file1, []byte("This is file 1."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	file2 := filepath.Join(dir, "日本語", "ファイル.txt")
+	err = ioutil.WriteFile(file2, []byte("This is file 2."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file1, file2})
+	assert.NoError(t, err)
+
+	assert.Equal(t, 2, len(submittedFiles))
+	assert.Equal(t, "This is file 1.", submittedFiles["café.txt"])
+	assert.Equal(t, "This is file 2.", submittedFiles["日本語/ファイル.txt"])
+}
+
+func TestEncodeRFC5987(t *testing.T) {
+	testCases := []struct {
+		in, out string
+	}{
+		{"plain.txt", "plain.txt"},
+		{"café.txt", "caf%C3%A9.txt"},
+		{"dir/ファイル.txt", "dir%2F%E3%83%95%E3%82%A1%E3%82%A4%E3%83%AB.txt"},
+		{"with space.txt", "with%20space.txt"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.out, encodeRFC5987(tc.in), tc.in)
+	}
+}
+
+func TestSubmitReplace(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	partialUpdate := true
+	var submitted []string
+	var replaced bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			fmt.Fprintf(w, `{"capabilities": {"partial_update": %t}}`, partialUpdate)
+			return
+		}
+		err := r.ParseMultipartForm(2 << 10)
+		assert.NoError(t, err)
+		submitted = nil
+		for _, fh := range r.MultipartForm.File["files[]"] {
+			_, params, err := mime.ParseMediaType(fh.Header.Get("Content-Disposition"))
+			assert.NoError(t, err)
+			submitted = append(submitted, params["filename"])
+		}
+		replaced = r.FormValue("replace") == "true"
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-replace")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file1 := filepath.Join(dir, "file-1.txt")
+	err = ioutil.WriteFile(file1, []byte("This is file 1."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	file2 := filepath.Join(dir, "file-2.txt")
+	err = ioutil.WriteFile(file2, []byte("This is file 2."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func(replace bool) {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		flags.Set("refresh-capabilities", "true")
+		if replace {
+			flags.Set("replace", "true")
+		}
+		err := runSubmit(context.Background(), cfg, flags, []string{file1, file2})
+		assert.NoError(t, err)
+	}
+
+	submit(false)
+	assert.Equal(t, []string{"file-1.txt", "file-2.txt"}, submitted)
+	assert.False(t, replaced)
+
+	err = ioutil.WriteFile(file2, []byte("This is file 2, changed."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	submit(true)
+	assert.Equal(t, []string{"file-2.txt"}, submitted)
+	assert.True(t, replaced)
+
+	// It falls back to a full submission when the API can't do partial updates.
+	partialUpdate = false
+	err = ioutil.WriteFile(file1, []byte("This is file 1, changed."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	submit(true)
+	assert.Equal(t, []string{"file-1.txt", "file-2.txt"}, submitted)
+	assert.False(t, replaced)
+}
+
+func TestSubmitCancelled(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var buf bytes.Buffer
+	Err = &buf
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		ioutil.ReadAll(r.Body)
+		// Simulate an interrupt while the upload is in flight.
+		cancel()
+		select {
+		case <-r.Context().Done():
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-cancelled")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(ctx, cfg, flags, []string{file})
+	assert.Equal(t, errInterrupted, err)
+	assert.Regexp(t, "Submission cancelled", buf.String())
+	assert.NotRegexp(t, "submitted successfully", buf.String())
+}
+
+func TestSubmitBinaryFiles(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	tmpDir, err := ioutil.TempDir("", "submit-binary")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	text := filepath.Join(dir, "text.txt")
+	err = ioutil.WriteFile(text, []byte("This is text. Ça va? 日本語"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	invalid := filepath.Join(dir, "invalid.txt")
+	err = ioutil.WriteFile(invalid, []byte{0x66, 0x6f, 0xff, 0xfe, 0x6f}, os.FileMode(0755))
+	assert.NoError(t, err)
+
+	binary := filepath.Join(dir, "binary.bin")
+	err = ioutil.WriteFile(binary, []byte{0x7f, 0x45, 0x4c, 0x46, 0x00, 0x01}, os.FileMode(0755))
+	assert.NoError(t, err)
+
+	image := filepath.Join(dir, "image.png")
+	err = ioutil.WriteFile(image, []byte("\x89PNG\x0D\x0A\x1A\x0A\x00\x00\x00\x0DIHDR"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc      string
+		file      string
+		flags     []string
+		submitted bool
+		warning   bool
+		refusal   string
+	}{
+		{desc: "text", file: text, submitted: true},
+		{desc: "invalid UTF-8", file: invalid, submitted: true, warning: true},
+		{desc: "invalid UTF-8, strict", file: invalid, flags: []string{"--strict"}, refusal: "not UTF-8 text"},
+		{desc: "binary", file: binary, refusal: "looks like an executable"},
+		{desc: "image", file: image, refusal: "looks like an image \\(image/png\\)"},
+		{desc: "binary, strict", file: binary, flags: []string{"--strict"}, refusal: "looks like an executable"},
+		{desc: "binary, allowed", file: binary, flags: []string{"--allow-binary"}, submitted: true},
+		{desc: "binary, allowed and strict", file: binary, flags: []string{"--allow-binary", "--strict"}, submitted: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			var buf bytes.Buffer
+			Err = &buf
+
+			submittedFiles := map[string]string{}
+			ts := fakeSubmitServer(t, submittedFiles)
+			defer ts.Close()
+
+			v := viper.New()
+			v.Set("token", "abc123")
+			v.Set("workspace", tmpDir)
+			v.Set("apibaseurl", ts.URL)
+
+			cfg := config.Config{
+				Persister:       config.InMemoryPersister{},
+				UserViperConfig: v,
+			}
+
+			flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+			setupSubmitFlags(flags)
+			// The same files are submitted more than once.
+			flags.Set("force", "true")
+			err := flags.Parse(tc.flags)
+			assert.NoError(t, err)
+
+			err = runSubmit(context.Background(), cfg, flags, []string{tc.file})
+			if tc.submitted {
+				assert.NoError(t, err)
+				assert.Equal(t, 1, len(submittedFiles))
+			} else {
+				assert.Error(t, err)
+				assert.Regexp(t, tc.refusal, err.Error())
+				assert.Equal(t, 0, len(submittedFiles))
+			}
+			if tc.warning {
+				assert.Regexp(t, "WARNING: Submitting a file that is not UTF-8 text", buf.String())
+			} else {
+				assert.NotRegexp(t, "WARNING", buf.String())
+			}
+		})
+	}
+}
+
+func TestSubmitIfNewer(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedAt := time.Date(2018, 8, 20, 10, 0, 0, 0, time.UTC)
+
+	var submitted bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.URL.Path == "/capabilities":
+			w.WriteHeader(http.StatusNotFound)
+		case r.Method == "GET":
+			fmt.Fprintf(w, `{"solution": {"id": "bogus-solution-uuid", "iteration": {"submitted_at": "%s"}}}`, submittedAt.Format(time.RFC3339))
+		default:
+			submitted = true
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-if-newer")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	testCases := []struct {
+		desc      string
+		modified  time.Time
+		submitted bool
+	}{
+		{desc: "older than the last submission", modified: submittedAt.Add(-time.Hour), submitted: false},
+		{desc: "newer than the last submission", modified: submittedAt.Add(time.Hour), submitted: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			submitted = false
+			err := os.Chtimes(file, tc.modified, tc.modified)
+			assert.NoError(t, err)
+
+			flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+			setupSubmitFlags(flags)
+			flags.Set("if-newer", "true")
+
+			err = runSubmit(context.Background(), cfg, flags, []string{file})
+			if tc.submitted {
+				assert.NoError(t, err)
+			} else if assert.Error(t, err) {
+				assert.Regexp(t, "None of the files have been modified since your last submission", err.Error())
+			}
+			assert.Equal(t, tc.submitted, submitted)
+		})
+	}
+}
+
+func TestSubmitPrintDiff(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-print-diff")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("one\ntwo\nthree\n"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func(flag string) string {
+		var buf bytes.Buffer
+		Err = &buf
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		flags.Set(flag, "true")
+		err := runSubmit(context.Background(), cfg, flags, []string{file})
+		assert.NoError(t, err)
+		return buf.String()
+	}
+
+	// There is nothing to compare against on the first submission.
+	output := submit("print-diff")
+	assert.Regexp(t, "file.txt: not submitted before", output)
+	assert.Equal(t, "one\ntwo\nthree\n", submittedFiles["file.txt"])
+
+	err = ioutil.WriteFile(file, []byte("one\n2\nthree\n"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	delete(submittedFiles, "file.txt")
+	output = submit("print-diff-only")
+	assert.Regexp(t, "(?m)^-two$", output)
+	assert.Regexp(t, "(?m)^\\+2$", output)
+	assert.NotRegexp(t, "submitted successfully", output)
+	assert.Empty(t, submittedFiles)
+}
+
+func TestSubmitWithInvalidMetadata(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "submit-invalid-metadata")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+
+	err = ioutil.WriteFile(filepath.Join(dir, ".solution.json"), []byte(`{"id": "abc", "is_requester": "yes"}`), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	if assert.Error(t, err) {
+		assert.Regexp(t, "field 'is_requester' must be true or false", err.Error())
+		assert.Regexp(t, "download --exercise=bogus-exercise --track=bogus-track", err.Error())
+	}
+}
+
+func TestSubmitToTeam(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var path string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		path = r.URL.Path
+		if r.URL.Path != "/teams/my-class/solutions/bogus-solution-uuid" {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-team")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc       string
+		flag       string
+		configured string
+		path       string
+		err        string
+	}{
+		{desc: "from the flag", flag: "my-class", path: "/teams/my-class/solutions/bogus-solution-uuid"},
+		{desc: "from the config", configured: "my-class", path: "/teams/my-class/solutions/bogus-solution-uuid"},
+		{desc: "flag overrides config", flag: "my-class", configured: "other-class", path: "/teams/my-class/solutions/bogus-solution-uuid"},
+		{desc: "not a member", flag: "other-class", path: "/teams/other-class/solutions/bogus-solution-uuid", err: "you are not a member"},
+		{desc: "invalid slug", flag: "My Class", err: "invalid team slug"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			path = ""
+			var buf bytes.Buffer
+			Err = &buf
+
+			v := viper.New()
+			v.Set("token", "abc123")
+			v.Set("workspace", tmpDir)
+			v.Set("apibaseurl", ts.URL)
+			if tc.configured != "" {
+				v.Set("team", tc.configured)
+			}
+			cfg := config.Config{
+				Persister:       config.InMemoryPersister{},
+				UserViperConfig: v,
+			}
+
+			flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+			setupSubmitFlags(flags)
+			// The same files are submitted more than once.
+			flags.Set("force", "true")
+			if tc.flag != "" {
+				flags.Set("team", tc.flag)
+			}
+
+			err := runSubmit(context.Background(), cfg, flags, []string{file})
+			assert.Equal(t, tc.path, path)
+			if tc.err != "" {
+				if assert.Error(t, err) {
+					assert.Regexp(t, tc.err, err.Error())
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.Regexp(t, "submitted successfully to the team 'my-class'", buf.String())
+		})
+	}
+}
+
+func TestSubmitWithTokenFile(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var auth string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			auth = r.Header.Get("Authorization")
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-token-file")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	tokenFile := filepath.Join(tmpDir, "token")
+	err = ioutil.WriteFile(tokenFile, []byte("secret-token\n"), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	// No token in the config.
+	v := viper.New()
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("token-file", tokenFile)
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer secret-token", auth)
+}
+
+func TestMultipartBoundary(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "multipart-boundary")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	// The content contains something that looks just like a boundary.
+	path := filepath.Join(tmpDir, "file.txt")
+	err = ioutil.WriteFile(path, []byte("before\r\n--colliding-boundary\r\nafter"), os.FileMode(0644))
+	assert.NoError(t, err)
+	doc, err := workspace.NewDocument(tmpDir, path)
+	assert.NoError(t, err)
+	docs := []workspace.Document{doc}
+
+	oldRandomBoundary := randomBoundary
+	defer func() {
+		randomBoundary = oldRandomBoundary
+	}()
+	candidates := []string{"colliding-boundary", "safe-boundary"}
+	randomBoundary = func() string {
+		b := candidates[0]
+		candidates = candidates[1:]
+		return b
+	}
+
+	boundary, err := multipartBoundary("", docs)
+	assert.NoError(t, err)
+	assert.Equal(t, "safe-boundary", boundary)
+
+	boundary, err = multipartBoundary("custom-boundary", docs)
+	assert.NoError(t, err)
+	assert.Equal(t, "custom-boundary", boundary)
+
+	_, err = multipartBoundary("colliding-boundary", docs)
+	if assert.Error(t, err) {
+		assert.Regexp(t, "occurs in the files", err.Error())
+	}
+
+	randomBoundary = func() string { return "colliding-boundary" }
+	_, err = multipartBoundary("", docs)
+	assert.Error(t, err)
+}
+
+func TestSubmitWithCustomBoundary(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var contentType string
+	submittedFiles := map[string]string{}
+	fake := fakeSubmitServer(t, submittedFiles)
+	defer fake.Close()
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			contentType = r.Header.Get("Content-Type")
+		}
+		fake.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-boundary")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("multipart-boundary", "fixed-boundary-for-tests")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, "multipart/form-data; boundary=fixed-boundary-for-tests", contentType)
+	assert.Equal(t, "This is a file.", submittedFiles["file.txt"])
+}
+
+func TestSubmitWithFormat(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-format")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	var buf bytes.Buffer
+	Out = &buf
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("format", "json")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+
+	var results []submitResult
+	err = json.Unmarshal(buf.Bytes(), &results)
+	assert.NoError(t, err)
+	assert.Equal(t, []submitResult{
+		{
+			Track:      "bogus-track",
+			Exercise:   "bogus-exercise",
+			SolutionID: "bogus-solution-uuid",
+			URL:        "http://example.com/bogus-url",
+			Files:      []string{"file.txt"},
+		},
+	}, results)
+
+	flags.Set("format", "xml")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.Error(t, err)
+}
+
+func TestSubmitContinueOnError(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var requests []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		requests = append(requests, r.URL.Path)
+		if r.URL.Path == "/solutions/bravo-uuid" {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "continue-on-error")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	for _, slug := range []string{"alpha", "bravo", "charlie"} {
+		dir := filepath.Join(tmpDir, "bogus-track", slug)
+		os.MkdirAll(dir, os.FileMode(0755))
+		solution := &workspace.Solution{
+			ID:          slug + "-uuid",
+			Track:       "bogus-track",
+			Exercise:    slug,
+			IsRequester: true,
+		}
+		err = solution.Write(dir)
+		assert.NoError(t, err)
+	}
+
+	file := filepath.Join(tmpDir, "bogus-track", "alpha", "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func(continueOnError bool) (string, error) {
+		requests = nil
+		var buf bytes.Buffer
+		Err = &buf
+
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		// The same files are submitted more than once.
+		flags.Set("force", "true")
+		flags.Set("also-submit-to", "bogus-track/bravo")
+		flags.Set("also-submit-to", "bogus-track/charlie")
+		if continueOnError {
+			flags.Set("continue-on-error", "true")
+		}
+		err := runSubmit(context.Background(), cfg, flags, []string{file})
+		return buf.String(), err
+	}
+
+	// Fail fast by default.
+	_, err = submit(false)
+	if assert.Error(t, err) {
+		assert.Regexp(t, "500", err.Error())
+	}
+	assert.Equal(t, []string{"/solutions/alpha-uuid", "/solutions/bravo-uuid"}, requests)
+
+	output, err := submit(true)
+	if assert.Error(t, err) {
+		assert.Equal(t, "1 of 3 submissions failed", err.Error())
+	}
+	assert.Equal(t, []string{"/solutions/alpha-uuid", "/solutions/bravo-uuid", "/solutions/charlie-uuid"}, requests)
+	assert.Regexp(t, "Submitted to 2 of 3 exercises", output)
+	assert.Regexp(t, "bogus-track/bravo: API returned 500", output)
+	assert.NotRegexp(t, "bogus-track/charlie:", output)
+}
+
+func TestSubmitExplain(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var patched bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "PATCH" {
+			patched = true
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-explain")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "lib"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "lib", "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	empty := filepath.Join(dir, "empty.txt")
+	err = ioutil.WriteFile(empty, []byte{}, os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	var buf bytes.Buffer
+	Err = &buf
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("explain", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file, empty})
+	assert.NoError(t, err)
+	assert.False(t, patched)
+
+	output := buf.String()
+	assert.Regexp(t, "1. The files belong to bogus-track/bogus-exercise", output)
+	assert.Regexp(t, "Include .*file.txt, uploaded as lib/file.txt", output)
+	assert.Regexp(t, "Skip .*empty.txt, because it is empty", output)
+	assert.Regexp(t, "Send a PATCH request to "+ts.URL+"/solutions/bogus-solution-uuid with 1 file", output)
+	assert.Regexp(t, "Nothing was sent", output)
+}
+
+func TestSubmitMaxFiles(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-max-files")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	var files []string
+	for i := 0; i < 5; i++ {
+		file := filepath.Join(dir, fmt.Sprintf("file-%d.txt", i))
+		err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+		assert.NoError(t, err)
+		files = append(files, file)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	testCases := []struct {
+		maxFiles  string
+		submitted int
+	}{
+		{maxFiles: "4", submitted: 0},
+		{maxFiles: "5", submitted: 5},
+		{maxFiles: "0", submitted: 5},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.maxFiles, func(t *testing.T) {
+			for k := range submittedFiles {
+				delete(submittedFiles, k)
+			}
+
+			flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+			setupSubmitFlags(flags)
+			// The same files are submitted more than once.
+			flags.Set("force", "true")
+			flags.Set("max-files", tc.maxFiles)
+
+			err := runSubmit(context.Background(), cfg, flags, files)
+			if tc.submitted == 0 {
+				if assert.Error(t, err) {
+					assert.Regexp(t, "submit 5 files, which is more than the limit of 4", err.Error())
+				}
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.submitted, len(submittedFiles))
+		})
+	}
+}
+
+func TestSubmitIncludesTrackAndExercise(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	fields := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		err := r.ParseMultipartForm(2 << 10)
+		assert.NoError(t, err)
+		fields[r.URL.Path] = r.FormValue("track") + "/" + r.FormValue("exercise")
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-fields")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	for _, slug := range []string{"bogus-exercise", "bogus-variant"} {
+		dir := filepath.Join(tmpDir, "bogus-track", slug)
+		os.MkdirAll(dir, os.FileMode(0755))
+		solution := &workspace.Solution{
+			ID:          slug + "-uuid",
+			Track:       "bogus-track",
+			Exercise:    slug,
+			IsRequester: true,
+		}
+		err = solution.Write(dir)
+		assert.NoError(t, err)
+	}
+
+	file := filepath.Join(tmpDir, "bogus-track", "bogus-exercise", "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("also-submit-to", "bogus-track/bogus-variant")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"/solutions/bogus-exercise-uuid": "bogus-track/bogus-exercise",
+		"/solutions/bogus-variant-uuid":  "bogus-track/bogus-variant",
+	}, fields)
+}
+
+func TestSubmitResubmitLast(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-resubmit-last")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "lib"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file1 := filepath.Join(dir, "file-1.txt")
+	err = ioutil.WriteFile(file1, []byte("first version"), os.FileMode(0755))
+	assert.NoError(t, err)
+	file2 := filepath.Join(dir, "lib", "file-2.txt")
+	err = ioutil.WriteFile(file2, []byte("helper"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	resubmit := func() error {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		flags.Set("resubmit-last", "true")
+		return runSubmit(context.Background(), cfg, flags, []string{dir})
+	}
+
+	// Nothing has been submitted yet.
+	err = resubmit()
+	assert.Error(t, err)
+	assert.Regexp(t, "no record of a previous submission", err.Error())
+	assert.Empty(t, submittedFiles)
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	err = runSubmit(context.Background(), cfg, flags, []string{file1, file2})
+	assert.NoError(t, err)
+
+	err = ioutil.WriteFile(file1, []byte("second version"), os.FileMode(0755))
+	assert.NoError(t, err)
+	os.Remove(file2)
+
+	for k := range submittedFiles {
+		delete(submittedFiles, k)
+	}
+	err = resubmit()
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"file-1.txt":     "first version",
+		"lib/file-2.txt": "helper",
+	}, submittedFiles)
+
+	// The local changes are left alone.
+	b, err := ioutil.ReadFile(file1)
+	assert.NoError(t, err)
+	assert.Equal(t, "second version", string(b))
+}
+
+func TestSubmitSuggestsNextExercise(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var payload string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, payload)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-next-exercise")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	solution := &workspace.Solution{
+		ID:          "bogus-solution-uuid",
+		Track:       "bogus-track",
+		Exercise:    "bogus-exercise",
+		IsRequester: true,
+		AutoApprove: true,
+	}
+	err = solution.Write(dir)
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func() string {
+		var out bytes.Buffer
+		Out = &out
+		Err = ioutil.Discard
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		// The same files are submitted more than once.
+		flags.Set("force", "true")
+		err := runSubmit(context.Background(), cfg, flags, []string{file})
+		assert.NoError(t, err)
+		return out.String()
+	}
+
+	payload = `{"next_exercise": {"id": "next-exercise", "track": {"id": "bogus-track"}}}`
+	assert.Regexp(t, "download --exercise=next-exercise --track=bogus-track", submit())
+
+	for _, payload = range []string{"", "{}", `{"next_exercise": null}`, "not json"} {
+		assert.NotRegexp(t, "download", submit(), payload)
+	}
+}
+
+func TestSubmitMinInterval(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-min-interval")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func(args ...string) error {
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		err := flags.Parse(args)
+		assert.NoError(t, err)
+		return runSubmit(context.Background(), cfg, flags, []string{file})
+	}
+
+	// The first submission has nothing to wait for.
+	assert.NoError(t, submit("--min-interval", "60"))
+	assert.Equal(t, "This is a file.", submittedFiles["file.txt"])
+
+	delete(submittedFiles, "file.txt")
+	err = submit("--min-interval", "60")
+	assert.Error(t, err)
+	assert.Regexp(t, "minimum interval of 60 seconds", err.Error())
+	assert.Empty(t, submittedFiles)
+
+	// Without a minimum interval, or with --force, it goes ahead.
+	err = ioutil.WriteFile(file, []byte("This is a changed file."), os.FileMode(0755))
+	assert.NoError(t, err)
+	assert.NoError(t, submit())
+	assert.NoError(t, submit("--min-interval", "60", "--force"))
+	assert.Equal(t, "This is a changed file.", submittedFiles["file.txt"])
+}
+
+func TestSubmitGzipsLargeTextFiles(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	type part struct {
+		encoding string
+		contents string
+	}
+	var gzipParts bool
+	var parts map[string]part
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			fmt.Fprintf(w, `{"capabilities": {"gzip_parts": %t}}`, gzipParts)
+			return
+		}
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		reader := multipart.NewReader(r.Body, params["boundary"])
+		for {
+			p, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			if p.FormName() != "files[]" {
+				continue
+			}
+			var body io.Reader = p
+			encoding := p.Header.Get("Content-Encoding")
+			if encoding == "gzip" {
+				zr, err := gzip.NewReader(p)
+				assert.NoError(t, err)
+				body = zr
+			}
+			b, err := ioutil.ReadAll(body)
+			assert.NoError(t, err)
+			parts[p.FileName()] = part{encoding: encoding, contents: string(b)}
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-gzip-parts")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	contents := map[string]string{
+		"small.txt": "This is a small file.",
+		"large.txt": strings.Repeat("This is a large file.\n", 100),
+		"large.bin": strings.Repeat("\x00\x01\x02", 1000),
+	}
+	var files []string
+	for name, s := range contents {
+		file := filepath.Join(dir, name)
+		err = ioutil.WriteFile(file, []byte(s), os.FileMode(0755))
+		assert.NoError(t, err)
+		files = append(files, file)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func() {
+		parts = map[string]part{}
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		// The same files are submitted more than once.
+		flags.Set("force", "true")
+		flags.Set("allow-binary", "true")
+		flags.Set("gzip-threshold", "1k")
+		flags.Set("refresh-capabilities", "true")
+		err := runSubmit(context.Background(), cfg, flags, files)
+		assert.NoError(t, err)
+		for name, s := range contents {
+			assert.Equal(t, s, parts[name].contents, name)
+		}
+	}
+
+	gzipParts = true
+	submit()
+	assert.Equal(t, "", parts["small.txt"].encoding)
+	assert.Equal(t, "gzip", parts["large.txt"].encoding)
+	assert.Equal(t, "", parts["large.bin"].encoding)
+
+	// Nothing is compressed if the API doesn't support it.
+	gzipParts = false
+	submit()
+	assert.Equal(t, "", parts["large.txt"].encoding)
+}
+
+func TestSubmitArchiveDir(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-archive-dir")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "lib"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file1 := filepath.Join(dir, "file-1.txt")
+	err = ioutil.WriteFile(file1, []byte("This is file 1."), os.FileMode(0755))
+	assert.NoError(t, err)
+	file2 := filepath.Join(dir, "lib", "file-2.txt")
+	err = ioutil.WriteFile(file2, []byte("This is file 2."), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	archiveDir := filepath.Join(tmpDir, "archive")
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("archivedir", archiveDir)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	Err = ioutil.Discard
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	err = runSubmit(context.Background(), cfg, flags, []string{file1, file2})
+	assert.NoError(t, err)
+
+	archives, err := filepath.Glob(filepath.Join(archiveDir, "bogus-track", "bogus-exercise", "*"))
+	assert.NoError(t, err)
+	if assert.Len(t, archives, 1) {
+		b, err := ioutil.ReadFile(filepath.Join(archives[0], "file-1.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "This is file 1.", string(b))
+		b, err = ioutil.ReadFile(filepath.Join(archives[0], "lib", "file-2.txt"))
+		assert.NoError(t, err)
+		assert.Equal(t, "This is file 2.", string(b))
+	}
+
+	// Failing to archive only warns, since the submission went through.
+	blocked := filepath.Join(tmpDir, "blocked")
+	err = ioutil.WriteFile(blocked, []byte("not a directory"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	var buf bytes.Buffer
+	Err = &buf
+	flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("archive-dir", blocked)
+	err = runSubmit(context.Background(), cfg, flags, []string{file1})
+	assert.NoError(t, err)
+	assert.Regexp(t, "Unable to archive the submission", buf.String())
+	assert.Regexp(t, "submitted successfully", buf.String())
+}
+
+func TestSubmitPayloadTooLarge(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusRequestEntityTooLarge)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-too-large")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "node_modules"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	sizes := map[string]int{
+		"solution.js":             100,
+		"node_modules/huge.js":    3 * 1024 * 1024,
+		"node_modules/largish.js": 2048,
+		"node_modules/tiny.js":    10,
+	}
+	var files []string
+	for name, size := range sizes {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		err = ioutil.WriteFile(file, []byte(strings.Repeat("x", size)), os.FileMode(0644))
+		assert.NoError(t, err)
+		files = append(files, file)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("max-file-size", "0")
+	err = runSubmit(context.Background(), cfg, flags, files)
+	assert.Error(t, err)
+	assert.Regexp(t, "too large", err.Error())
+	assert.Regexp(t, `3\.0 MB  node_modules/huge\.js\n\s+2\.0 KB  node_modules/largish\.js\n\s+100 bytes  solution\.js\n\s+10 bytes  node_modules/tiny\.js`, err.Error())
+}
+
+func TestSubmitOnlyChanged(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	partialUpdate := true
+	submittedFiles := map[string]string{}
+	var replaced bool
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			fmt.Fprintf(w, `{"capabilities": {"partial_update": %t}}`, partialUpdate)
+			return
+		}
+		err := r.ParseMultipartForm(2 << 10)
+		assert.NoError(t, err)
+		for _, fh := range r.MultipartForm.File["files[]"] {
+			_, params, err := mime.ParseMediaType(fh.Header.Get("Content-Disposition"))
+			assert.NoError(t, err)
+			f, err := fh.Open()
+			assert.NoError(t, err)
+			body, err := ioutil.ReadAll(f)
+			f.Close()
+			assert.NoError(t, err)
+			submittedFiles[params["filename"]] = string(body)
+		}
+		replaced = r.FormValue("replace") == "true"
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-only-changed")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	write := func(name, contents string) string {
+		file := filepath.Join(dir, name)
+		err := ioutil.WriteFile(file, []byte(contents), os.FileMode(0644))
+		assert.NoError(t, err)
+		return file
+	}
+	file1 := write("file-1.txt", "one")
+	file2 := write("file-2.txt", "two")
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func(files ...string) map[string]string {
+		for k := range submittedFiles {
+			delete(submittedFiles, k)
+		}
+		replaced = false
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		flags.Set("refresh-capabilities", "true")
+		flags.Set("only-changed", "true")
+		err := runSubmit(context.Background(), cfg, flags, files)
+		assert.NoError(t, err)
+		return submittedFiles
+	}
+
+	// Without prior submissions, everything is new.
+	assert.Equal(t, map[string]string{"file-1.txt": "one", "file-2.txt": "two"}, submit(file1, file2))
+	assert.True(t, replaced)
+
+	// Unchanged files are skipped, with nothing left to submit.
+	assert.Empty(t, submit(file1, file2))
+
+	// Changed and new files are submitted, unchanged ones are not.
+	write("file-1.txt", "uno")
+	file3 := write("file-3.txt", "three")
+	assert.Equal(t, map[string]string{"file-1.txt": "uno", "file-3.txt": "three"}, submit(file1, file2, file3))
+	// The API keeps the files that were left out of the last submission.
+	assert.True(t, replaced)
+
+	// The files that were left out are still known to be unchanged.
+	assert.Empty(t, submit(file1, file2, file3))
+
+	// It falls back to a full submission when the API can't do partial updates.
+	partialUpdate = false
+	write("file-2.txt", "dos")
+	assert.Equal(t, map[string]string{"file-1.txt": "uno", "file-2.txt": "dos", "file-3.txt": "three"}, submit(file1, file2, file3))
+	assert.False(t, replaced)
+}
+
+func TestExpandGlobs(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "expand-globs")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	for _, name := range []string{"a.py", "b.py", "c.txt", "[literal].py"} {
+		err = ioutil.WriteFile(filepath.Join(tmpDir, name), []byte(name), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	args, err := expandGlobs([]string{
+		filepath.Join(tmpDir, "c.txt"),
+		filepath.Join(tmpDir, "?.py"),
+		filepath.Join(tmpDir, "[literal].py"),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(tmpDir, "c.txt"),
+		filepath.Join(tmpDir, "a.py"),
+		filepath.Join(tmpDir, "b.py"),
+		filepath.Join(tmpDir, "[literal].py"),
+	}, args)
+
+	_, err = expandGlobs([]string{filepath.Join(tmpDir, "*.rb")})
+	assert.Error(t, err)
+	assert.Regexp(t, "No files match the pattern", err.Error())
+
+	_, err = expandGlobs([]string{filepath.Join(tmpDir, "[.py")})
+	assert.Error(t, err)
+	assert.Regexp(t, "invalid pattern", err.Error())
+}
+
+func TestSubmitGlob(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-glob")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "src"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	for _, name := range []string{"src/a.py", "src/b.py", "src/c.txt"} {
+		err = ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(name), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	err = runSubmit(context.Background(), cfg, flags, []string{filepath.Join(dir, "src", "*.py")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"src/a.py": "src/a.py", "src/b.py": "src/b.py"}, submittedFiles)
+}
+
+func TestSubmitFromExerciseDir(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+
+	tmpDir, err := ioutil.TempDir("", "submit-from-exercise-dir")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+	tmpDir, err = filepath.EvalSymlinks(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "lib"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	files := map[string]string{
+		"bogus.go":      "package bogus",
+		"bogus_test.go": "package bogus",
+		"lib/helper.go": "package lib",
+	}
+	for name, contents := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(contents), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	assert.NoError(t, os.Chdir(filepath.Join(dir, "lib")))
+
+	submit := func() (map[string]string, error) {
+		submittedFiles := map[string]string{}
+		ts := fakeSubmitServer(t, submittedFiles)
+		defer ts.Close()
+
+		v := viper.New()
+		v.Set("token", "abc123")
+		v.Set("workspace", tmpDir)
+		v.Set("apibaseurl", ts.URL)
+
+		cfg := config.Config{
+			Persister:       config.InMemoryPersister{},
+			UserViperConfig: v,
+		}
+
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		flags.Set("no-pick", "true")
+
+		err := runSubmit(context.Background(), cfg, flags, []string{})
+		return submittedFiles, err
+	}
+
+	// Without an exercise config, the likely solution files are submitted.
+	submittedFiles, err := submit()
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(submittedFiles))
+	assert.Equal(t, "package bogus", submittedFiles["bogus.go"])
+	assert.Equal(t, "package lib", submittedFiles["lib/helper.go"])
+
+	// The exercise config decides which files are part of the solution.
+	os.MkdirAll(filepath.Join(dir, workspace.MetadataDirName), os.FileMode(0755))
+	err = ioutil.WriteFile(workspace.ExerciseConfigPath(dir), []byte(`{"files": {"solution": ["bogus.go"]}}`), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	submittedFiles, err = submit()
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(submittedFiles))
+	assert.Equal(t, "package bogus", submittedFiles["bogus.go"])
+
+	// Outside of an exercise there is nothing to submit.
+	assert.NoError(t, os.Chdir(tmpDir))
+	_, err = submit()
+	assert.Error(t, err)
+	assert.Regexp(t, "No files found to submit", err.Error())
+}
+
+func TestSubmitDryRun(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var buf bytes.Buffer
+	Out = &buf
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Errorf("unexpected request to %s", r.URL.Path)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-dry-run")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "subdir"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file1 := filepath.Join(dir, "file-1.txt")
+	err = ioutil.WriteFile(file1, []byte("This is file 1."), os.FileMode(0644))
+	assert.NoError(t, err)
+	file2 := filepath.Join(dir, "subdir", "file-2.txt")
+	err = ioutil.WriteFile(file2, []byte("This is file 2."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+		Dir:             tmpDir,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("dry-run", "true")
+	flags.Set("if-newer", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file1, file2})
+	assert.NoError(t, err)
+
+	out := buf.String()
+	assert.Regexp(t, "Would submit 2 file\\(s\\) to http://example.com/bogus-url", out)
+	assert.Regexp(t, "file-1.txt +"+regexp.QuoteMeta(file1)+" +15 bytes", out)
+	assert.Regexp(t, "subdir/file-2.txt +"+regexp.QuoteMeta(file2)+" +15 bytes", out)
+	assert.Regexp(t, "Total payload: ", out)
+	assert.Regexp(t, "Nothing was sent", out)
+
+	// Nothing about the submission was recorded.
+	solution, err := workspace.NewSolution(dir)
+	assert.NoError(t, err)
+	assert.Nil(t, solution.SubmittedAt)
+}
+
+func TestSubmitStreamsLargeFiles(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	// Big enough that buffering it would show, but quick to write.
+	contents := bytes.Repeat([]byte("All work and no play makes Jack a dull boy.\n"), 200*1024)
+
+	var contentLength int64
+	received := map[string]int{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		contentLength = r.ContentLength
+		reader, err := r.MultipartReader()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			b, err := ioutil.ReadAll(part)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if part.FormName() == "files[]" {
+				assert.Equal(t, contents, b)
+				received[part.FileName()] = len(b)
+			}
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-large")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file1 := filepath.Join(dir, "asset-1.txt")
+	err = ioutil.WriteFile(file1, contents, os.FileMode(0644))
+	assert.NoError(t, err)
+	file2 := filepath.Join(dir, "asset-2.txt")
+	err = ioutil.WriteFile(file2, contents, os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("max-file-size", "0")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file1, file2})
+	assert.NoError(t, err)
+
+	// The body was streamed, so its length wasn't known up front.
+	assert.Equal(t, int64(-1), contentLength)
+	assert.Equal(t, map[string]int{"asset-1.txt": len(contents), "asset-2.txt": len(contents)}, received)
+}
+
+func TestFileContains(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "file-contains")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	// The needle straddles the first two chunks that are read.
+	path := filepath.Join(tmpDir, "file.txt")
+	contents := append(bytes.Repeat([]byte("a"), 32*1024+2), []byte("needle")...)
+	err = ioutil.WriteFile(path, contents, os.FileMode(0644))
+	assert.NoError(t, err)
+
+	ok, err := fileContains(path, []byte("needle"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = fileContains(path, []byte("haystack"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestSubmitRetriesOnlyUnsentSubmissions(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+
+	var attempts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		attempts++
+		// Read the body, so that the client sees the status rather than a broken pipe.
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(http.StatusBadGateway)
+		fmt.Fprint(w, `{"error": {"type": "upstream_failure", "message": "Lost the connection to the database"}}`)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-retries")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	// The same files are submitted more than once.
+	flags.Set("force", "true")
+	flags.Set("retry-backoff", "1ms")
+
+	// The API got the submission, and may have made an iteration of it,
+	// so it isn't sent again.
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.Error(t, err)
+	assert.Regexp(t, "502 Bad Gateway", err.Error())
+	assert.Equal(t, 1, attempts)
+	assert.NotRegexp(t, "Retrying", errBuf.String())
+
+	// It's sent again when it couldn't be sent at all.
+	unreachable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	unreachable.Close()
+	v.Set("apibaseurl", unreachable.URL)
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.Error(t, err)
+	assert.Regexp(t, "Submitting failed: .*connection refused", errBuf.String())
+	assert.Regexp(t, "Retrying in 1ms \\(retry 1 of 2\\)", errBuf.String())
+	assert.Regexp(t, "Retrying in 2ms \\(retry 2 of 2\\)", errBuf.String())
+
+	// Without retries, the first failure is final.
+	errBuf.Reset()
+	flags.Set("retries", "0")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.Error(t, err)
+	assert.NotRegexp(t, "Retrying", errBuf.String())
+}
+
+func TestSubmitRetriesGatewayErrors(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+
+	submittedFiles := map[string]string{}
+	submitted := fakeSubmitServer(t, submittedFiles)
+	defer submitted.Close()
+
+	var attempts int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/capabilities" {
+			attempts++
+		}
+		if r.URL.Path != "/capabilities" && attempts == 1 {
+			// The gateway turns the submission away without passing it on.
+			ioutil.ReadAll(r.Body)
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		submitted.Config.Handler.ServeHTTP(w, r)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-gateway-retries")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("retry-backoff", "1ms")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, attempts)
+	assert.Regexp(t, "Submitting failed: API returned 502 Bad Gateway", errBuf.String())
+	assert.Regexp(t, "Retrying in 1ms \\(retry 1 of 2\\)", errBuf.String())
+	assert.Equal(t, "This is a file.", submittedFiles["file.txt"])
+}
+
+func TestSubmitRespectsIgnoreFile(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-ignore")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "out"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	files := map[string]string{
+		"main.c":      "int main() {}",
+		"main.o":      "object code",
+		"out/main.c":  "generated",
+		"helper.c":    "void help() {}",
+		"scratch.txt": "notes",
+	}
+	for name, contents := range files {
+		err = ioutil.WriteFile(filepath.Join(dir, filepath.FromSlash(name)), []byte(contents), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+	err = ioutil.WriteFile(filepath.Join(dir, workspace.IgnoreFilename), []byte("*.o\nout/\nscratch.txt\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("no-pick", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{dir, filepath.Join(dir, "scratch.txt")})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"main.c": "int main() {}", "helper.c": "void help() {}"}, submittedFiles)
+}
+
+func TestSubmitLargeFiles(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldIn := In
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		In = oldIn
+	}()
+
+	tmpDir, err := ioutil.TempDir("", "submit-large-files")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	small := filepath.Join(dir, "small.txt")
+	err = ioutil.WriteFile(small, []byte("small"), os.FileMode(0644))
+	assert.NoError(t, err)
+	large := filepath.Join(dir, "large.txt")
+	err = ioutil.WriteFile(large, bytes.Repeat([]byte("large\n"), 1024), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc      string
+		input     string
+		force     bool
+		submitted int
+		err       string
+	}{
+		{desc: "confirmed", input: "y\n", submitted: 2},
+		{desc: "declined", input: "n\n", submitted: 0},
+		{desc: "no answer", input: "", err: "aborted"},
+		{desc: "forced", force: true, submitted: 2},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			submittedFiles := map[string]string{}
+			ts := fakeSubmitServer(t, submittedFiles)
+			defer ts.Close()
+
+			var errBuf bytes.Buffer
+			Err = &errBuf
+			In = strings.NewReader(tc.input)
+
+			v := viper.New()
+			v.Set("token", "abc123")
+			v.Set("workspace", tmpDir)
+			v.Set("apibaseurl", ts.URL)
+
+			cfg := config.Config{
+				Persister:       config.InMemoryPersister{},
+				UserViperConfig: v,
+			}
+
+			flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+			setupSubmitFlags(flags)
+			flags.Set("max-file-size", "4k")
+			if tc.force {
+				flags.Set("force", "true")
+			}
+
+			err := runSubmit(context.Background(), cfg, flags, []string{small, large})
+			if tc.err != "" {
+				assert.Error(t, err)
+				assert.Regexp(t, tc.err, err.Error())
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.submitted, len(submittedFiles))
+			if tc.force {
+				assert.NotRegexp(t, "larger than", errBuf.String())
+			} else {
+				assert.Regexp(t, "larger than 4.0 KB", errBuf.String())
+				assert.Regexp(t, "6.0 KB  large.txt", errBuf.String())
+			}
+		})
+	}
+}
+
+func TestSubmitOpen(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldOpenBrowser := openBrowser
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		openBrowser = oldOpenBrowser
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-open")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	var opened []string
+	openBrowser = func(url string) error {
+		opened = append(opened, url)
+		return nil
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	// The same files are submitted more than once.
+	flags.Set("force", "true")
+
+	// Nothing is opened by default.
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(opened))
+
+	flags.Set("open", "true")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"http://example.com/bogus-url"}, opened)
+
+	// The submission still succeeds when the browser can't be opened.
+	openBrowser = func(url string) error {
+		return errors.New("no browser here")
+	}
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Regexp(t, "Unable to open http://example.com/bogus-url in the browser", errBuf.String())
+	assert.Regexp(t, "no browser here", errBuf.String())
+}
+
+func TestSubmitWithMessage(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var messages []string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		err := r.ParseMultipartForm(2 << 10)
+		assert.NoError(t, err)
+		_, ok := r.MultipartForm.Value["message"]
+		if ok {
+			messages = append(messages, r.FormValue("message"))
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-message")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	// The same files are submitted more than once.
+	flags.Set("force", "true")
+
+	// Without a message, there's no message field.
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(messages))
+
+	flags.Set("message", "  Extracted a helper, as suggested.\n")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"Extracted a helper, as suggested."}, messages)
+}
+
+func TestSubmitIdenticalIteration(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-identical")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file1 := filepath.Join(dir, "file-1.txt")
+	err = ioutil.WriteFile(file1, []byte("This is file 1."), os.FileMode(0644))
+	assert.NoError(t, err)
+	file2 := filepath.Join(dir, "file-2.txt")
+	err = ioutil.WriteFile(file2, []byte("This is file 2."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func(files []string, args ...string) error {
+		for k := range submittedFiles {
+			delete(submittedFiles, k)
+		}
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		err := flags.Parse(args)
+		assert.NoError(t, err)
+		return runSubmit(context.Background(), cfg, flags, files)
+	}
+
+	assert.NoError(t, submit([]string{file1, file2}))
+	assert.Equal(t, 2, len(submittedFiles))
+
+	err = submit([]string{file1, file2})
+	assert.Error(t, err)
+	assert.Regexp(t, "identical to your last iteration, submitted .* ago", err.Error())
+	assert.Empty(t, submittedFiles)
+
+	// A subset of the files is a different iteration.
+	assert.NoError(t, submit([]string{file1}))
+	assert.Equal(t, 1, len(submittedFiles))
+
+	// So is a change to a file.
+	err = ioutil.WriteFile(file1, []byte("This is file 1, changed."), os.FileMode(0644))
+	assert.NoError(t, err)
+	assert.NoError(t, submit([]string{file1}))
+
+	// --force submits a duplicate anyway.
+	assert.NoError(t, submit([]string{file1}, "--force"))
+	assert.Equal(t, "This is file 1, changed.", submittedFiles["file-1.txt"])
+}
+
+func TestSubmitPicksFilesByDefault(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldIn := In
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		In = oldIn
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-pick")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+	for name, content := range map[string]string{"solution.go": "package bogus", "solution_test.go": "package bogus_test"} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte(content), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("force", "true")
+
+	// Scripted input counts as someone at a terminal, who selects all the files.
+	In = strings.NewReader("a\n\n")
+	err = runSubmit(context.Background(), cfg, flags, []string{dir})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"solution.go": "package bogus", "solution_test.go": "package bogus_test"}, submittedFiles)
+
+	// Scripts can skip the list.
+	for k := range submittedFiles {
+		delete(submittedFiles, k)
+	}
+	In = strings.NewReader("a\n\n")
+	flags.Set("no-pick", "true")
+	err = runSubmit(context.Background(), cfg, flags, []string{dir})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"solution.go": "package bogus"}, submittedFiles)
+
+	flags.Set("pick", "true")
+	err = runSubmit(context.Background(), cfg, flags, []string{dir})
+	if assert.Error(t, err) {
+		assert.Regexp(t, "--pick and --no-pick can't be used together", err.Error())
+	}
+}
+
+func TestSubmitOutsideWorkspace(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-outside-workspace")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+	tmpDir, err = filepath.EvalSymlinks(tmpDir)
+	assert.NoError(t, err)
+
+	wsDir := filepath.Join(tmpDir, "workspace")
+	os.MkdirAll(wsDir, os.FileMode(0755))
+
+	// The exercise was cloned into a repository of its own.
+	dir := filepath.Join(tmpDir, "repos", "my-solution")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "solution.go")
+	err = ioutil.WriteFile(file, []byte("package bogus"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", wsDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("force", "true")
+	flags.Set("no-pick", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"solution.go": "package bogus"}, submittedFiles)
+
+	// It's found from the current directory too.
+	delete(submittedFiles, "solution.go")
+	assert.NoError(t, os.Chdir(dir))
+	err = runSubmit(context.Background(), cfg, flags, []string{})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"solution.go": "package bogus"}, submittedFiles)
+
+	// Files without solution metadata still can't be submitted.
+	stray := filepath.Join(tmpDir, "stray.txt")
+	err = ioutil.WriteFile(stray, []byte("stray"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = runSubmit(context.Background(), cfg, flags, []string{stray})
+	assert.Error(t, err)
+	assert.Regexp(t, "doesn't have the necessary metadata", err.Error())
+}
+
+func TestSubmitShowsIterationStatus(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	oldInterval := testsPollInterval
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+		testsPollInterval = oldInterval
+	}()
+	testsPollInterval = time.Millisecond
+
+	var polls int
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == "PATCH":
+			ioutil.ReadAll(r.Body)
+			fmt.Fprint(w, `{"iteration": {"number": 2, "tests_status": "queued"}}`)
+		case r.Method == "GET" && r.URL.Path == "/solutions/bogus-solution-uuid/iterations":
+			fmt.Fprint(w, `{"iterations": [{"number": 1, "tests_status": "failed"}, {"number": 2, "tests_status": "passed"}]}`)
+		case r.Method == "GET" && r.URL.Path == "/solutions/bogus-solution-uuid":
+			polls++
+			status := "running"
+			if polls > 1 {
+				status = "passed"
+			}
+			fmt.Fprintf(w, `{"solution": {"iteration": {"number": 2, "tests_status": "%s"}}}`, status)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-iteration-status")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	var errBuf bytes.Buffer
+	Out = ioutil.Discard
+	Err = &errBuf
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	// The same files are submitted more than once.
+	flags.Set("force", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, polls)
+	assert.Regexp(t, "This is iteration 2. The automated tests passed.", errBuf.String())
+
+	// Without waiting, the status from the submission is shown.
+	errBuf.Reset()
+	polls = 0
+	flags.Set("wait-for-tests", "0")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, polls)
+	assert.Regexp(t, "This is iteration 2. The automated tests are queued.", errBuf.String())
+
+	// The recent iterations are listed on request.
+	errBuf.Reset()
+	flags.Set("all-iterations-list", "true")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Regexp(t, `ITERATION\s+SUBMITTED\s+TESTS`, errBuf.String())
+	assert.Regexp(t, `1\s+-\s+failed`, errBuf.String())
+	assert.Regexp(t, `2\s+-\s+passed\s+\(this one\)`, errBuf.String())
+
+	var buf bytes.Buffer
+	Out = &buf
+	flags.Set("format", "json")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+
+	var results []submitResult
+	err = json.Unmarshal(buf.Bytes(), &results)
+	assert.NoError(t, err)
+	if assert.Equal(t, 1, len(results)) {
+		assert.Equal(t, 2, results[0].Iteration)
+		assert.Equal(t, "queued", results[0].TestsStatus)
+		assert.Equal(t, 2, len(results[0].Iterations))
+	}
+}
+
+func TestSubmitNormalizesLineEndings(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-normalize")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("\xef\xbb\xbfline one\r\nline two\r\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	// The same files are submitted more than once.
+	flags.Set("force", "true")
+
+	// Files are sent as they are by default.
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, "\xef\xbb\xbfline one\r\nline two\r\n", submittedFiles["file.txt"])
+
+	flags.Set("normalize", "true")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, "line one\nline two\n", submittedFiles["file.txt"])
+
+	// The local file is left alone.
+	b, err := ioutil.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "\xef\xbb\xbfline one\r\nline two\r\n", string(b))
+
+	// It can be turned on in the config instead, and off again with the flag.
+	v.Set("normalize", true)
+	flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("force", "true")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, "line one\nline two\n", submittedFiles["file.txt"])
+
+	flags.Set("normalize", "false")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, "\xef\xbb\xbfline one\r\nline two\r\n", submittedFiles["file.txt"])
+}
+
+func TestSubmitChecksFilesAgainstExerciseConfig(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-exercise-config")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, workspace.MetadataDirName), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	err = ioutil.WriteFile(workspace.ExerciseConfigPath(dir), []byte(`{"files": {"solution": ["bogus.go"], "test": ["bogus_test.go"]}}`), os.FileMode(0644))
+	assert.NoError(t, err)
+	for _, name := range []string{"bogus.go", "bogus_test.go", "notes.txt", ".bogus.go.swp"} {
+		err = ioutil.WriteFile(filepath.Join(dir, name), []byte("contents of "+name), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	// The same files are submitted more than once.
+	flags.Set("force", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{filepath.Join(dir, "bogus.go")})
+	assert.NoError(t, err)
+	assert.NotRegexp(t, "WARNING", errBuf.String())
+
+	// Test files and files outside the solution are submitted with a warning.
+	err = runSubmit(context.Background(), cfg, flags, []string{filepath.Join(dir, "bogus.go"), filepath.Join(dir, "bogus_test.go"), filepath.Join(dir, "notes.txt")})
+	assert.NoError(t, err)
+	assert.Regexp(t, "WARNING: Submitting one of the exercise's test files\\s+"+regexp.QuoteMeta(filepath.Join(dir, "bogus_test.go")), errBuf.String())
+	assert.Regexp(t, "WARNING: Submitting a file that is not one of the exercise's solution files\\s+"+regexp.QuoteMeta(filepath.Join(dir, "notes.txt")), errBuf.String())
+	assert.Equal(t, 3, len(submittedFiles))
+
+	// Editor files and metadata are refused.
+	for _, file := range []string{filepath.Join(dir, ".bogus.go.swp"), workspace.ExerciseConfigPath(dir)} {
+		err = runSubmit(context.Background(), cfg, flags, []string{filepath.Join(dir, "bogus.go"), file})
+		if assert.Error(t, err, file) {
+			assert.Regexp(t, "not part of your solution", err.Error())
+		}
+	}
+
+	// With a custom metadata directory, the exercise config is read from
+	// there, and the snapshots go there too.
+	defer workspace.SetMetadataDirName("")
+	v.Set("metadatadir", ".exercism-cli")
+	assert.NoError(t, applyUserSettings(cfg))
+	err = os.Rename(filepath.Join(dir, ".exercism"), filepath.Join(dir, ".exercism-cli"))
+	assert.NoError(t, err)
+	errBuf.Reset()
+	err = runSubmit(context.Background(), cfg, flags, []string{filepath.Join(dir, "bogus.go"), filepath.Join(dir, "bogus_test.go")})
+	assert.NoError(t, err)
+	assert.Regexp(t, "WARNING: Submitting one of the exercise's test files", errBuf.String())
+	_, err = os.Stat(filepath.Join(dir, ".exercism-cli", "snapshots", "bogus.go.gz"))
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, ".exercism"))
+	assert.True(t, os.IsNotExist(err))
+	err = runSubmit(context.Background(), cfg, flags, []string{filepath.Join(dir, "bogus.go"), workspace.ExerciseConfigPath(dir)})
+	if assert.Error(t, err) {
+		assert.Regexp(t, "not part of your solution", err.Error())
+	}
+}
+
+func TestSubmitWarnsWhenUnableToKeepSnapshots(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-snapshot-warning")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+	file := filepath.Join(dir, "bogus.go")
+	err = ioutil.WriteFile(file, []byte("package bogus"), os.FileMode(0644))
+	assert.NoError(t, err)
+	// The snapshots can't be written where their directory should be.
+	err = os.MkdirAll(filepath.Join(dir, workspace.MetadataDirName), os.FileMode(0755))
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(filepath.Join(dir, workspace.MetadataDirName, "snapshots"), []byte("in the way"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+
+	// The submission went through, so it isn't reported as a failure.
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	assert.Equal(t, "package bogus", submittedFiles["bogus.go"])
+	assert.Regexp(t, "WARNING: Unable to keep a snapshot of\\s+"+regexp.QuoteMeta(file), errBuf.String())
+}
+
+func TestSubmitGzipBody(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var gzipSupported bool
+	var encoding, partEncoding string
+	files := map[string]string{}
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/capabilities" {
+			fmt.Fprintf(w, `{"capabilities": {"gzip": %t, "gzip_parts": true}}`, gzipSupported)
+			return
+		}
+		encoding = r.Header.Get("Content-Encoding")
+		var body io.Reader = r.Body
+		if encoding == "gzip" {
+			zr, err := gzip.NewReader(r.Body)
+			assert.NoError(t, err)
+			body = zr
+		}
+		_, params, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+		assert.NoError(t, err)
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			p, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+			if p.FormName() != "files[]" {
+				continue
+			}
+			partEncoding = p.Header.Get("Content-Encoding")
+			var r io.Reader = p
+			if partEncoding == "gzip" {
+				zr, err := gzip.NewReader(p)
+				assert.NoError(t, err)
+				r = zr
+			}
+			b, err := ioutil.ReadAll(r)
+			assert.NoError(t, err)
+			files[p.FileName()] = string(b)
+		}
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-gzip-body")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	contents := strings.Repeat("This is a large file.\n", 100)
+	file := filepath.Join(dir, "large.txt")
+	err = ioutil.WriteFile(file, []byte(contents), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	testCases := []struct {
+		desc      string
+		supported bool
+		mode      string
+		setting   string
+		expected  string
+	}{
+		{"supported", true, "", "", "gzip"},
+		{"not supported", false, "", "", ""},
+		{"never", true, "never", "", ""},
+		{"always", false, "always", "", "gzip"},
+		{"never in the config", true, "", "never", ""},
+		{"flag overrides the config", true, "auto", "never", "gzip"},
+	}
+
+	for _, tc := range testCases {
+		gzipSupported = tc.supported
+		encoding = ""
+		partEncoding = ""
+		files = map[string]string{}
+		v.Set("gzip", tc.setting)
+
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		// The same files are submitted more than once.
+		flags.Set("force", "true")
+		flags.Set("refresh-capabilities", "true")
+		flags.Set("gzip-threshold", "1k")
+		if tc.mode != "" {
+			flags.Set("gzip", tc.mode)
+		}
+
+		err = runSubmit(context.Background(), cfg, flags, []string{file})
+		assert.NoError(t, err, tc.desc)
+		assert.Equal(t, tc.expected, encoding, tc.desc)
+		assert.Equal(t, contents, files["large.txt"], tc.desc)
+		// Files are only compressed on their own when the body isn't.
+		if tc.expected == "gzip" {
+			assert.Equal(t, "", partEncoding, tc.desc)
+		} else {
+			assert.Equal(t, "gzip", partEncoding, tc.desc)
+		}
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("force", "true")
+	flags.Set("gzip", "sometimes")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	if assert.Error(t, err) {
+		assert.Regexp(t, "invalid --gzip", err.Error())
+	}
+}
+
+func TestSubmitNestedExercises(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-nested")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	outer := filepath.Join(tmpDir, "bogus-track", "outer-exercise")
+	inner := filepath.Join(outer, "inner-exercise")
+	os.MkdirAll(inner, os.FileMode(0755))
+	writeFakeSolution(t, outer, "bogus-track", "outer-exercise")
+	writeFakeSolution(t, inner, "bogus-track", "inner-exercise")
+
+	file := filepath.Join(inner, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func(trackID, exerciseSlug string) error {
+		for k := range submittedFiles {
+			delete(submittedFiles, k)
+		}
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		// The same files are submitted more than once.
+		flags.Set("force", "true")
+		flags.Set("track", trackID)
+		flags.Set("exercise", exerciseSlug)
+		return runSubmit(context.Background(), cfg, flags, []string{file})
+	}
+
+	// The innermost exercise is picked by default.
+	err = submit("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"file.txt": "This is a file."}, submittedFiles)
+
+	err = submit("bogus-track", "outer-exercise")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"inner-exercise/file.txt": "This is a file."}, submittedFiles)
+
+	err = submit("", "inner-exercise")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"file.txt": "This is a file."}, submittedFiles)
+
+	err = submit("other-track", "outer-exercise")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "None of the exercises that this belongs to is the exercise 'outer-exercise' in the track 'other-track'", err.Error())
+	}
+	err = submit("other-track", "")
+	if assert.Error(t, err) {
+		assert.Regexp(t, "is an exercise in the track 'other-track'", err.Error())
+	}
+}
+
+func TestSubmitJSON(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var outBuf, errBuf bytes.Buffer
+	Out = &outBuf
+	Err = &errBuf
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-json")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+	empty := filepath.Join(dir, "empty.txt")
+	err = ioutil.WriteFile(empty, []byte{}, os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("json", "true")
+
+	err = runSubmit(context.Background(), cfg, flags, []string{file, empty})
+	assert.NoError(t, err)
+
+	// Stdout has nothing but the report.
+	var report map[string]interface{}
+	err = json.Unmarshal(outBuf.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.Equal(t, "bogus-solution-uuid", report["solution_id"])
+	assert.Equal(t, "http://example.com/bogus-url", report["url"])
+	assert.Equal(t, []interface{}{"file.txt"}, report["files"])
+	assert.Equal(t, []interface{}{"Skipping empty file " + empty}, report["warnings"])
+	assert.Regexp(t, "WARNING: Skipping empty file", errBuf.String())
+
+	// There are no warnings this time, but the list is still there.
+	outBuf.Reset()
+	flags.Set("force", "true")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.NoError(t, err)
+	report = nil
+	err = json.Unmarshal(outBuf.Bytes(), &report)
+	assert.NoError(t, err)
+	assert.Equal(t, []interface{}{}, report["warnings"])
+
+	flags.Set("format", "yaml")
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	assert.Error(t, err)
+}
+
+func TestSubmitJSONReportsFailures(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-json-failures")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	for _, slug := range []string{"alpha", "bravo"} {
+		dir := filepath.Join(tmpDir, "bogus-track", slug)
+		os.MkdirAll(dir, os.FileMode(0755))
+		solution := &workspace.Solution{
+			ID:          slug + "-uuid",
+			Track:       "bogus-track",
+			Exercise:    slug,
+			IsRequester: true,
+		}
+		err = solution.Write(dir)
+		assert.NoError(t, err)
+	}
+
+	file := filepath.Join(tmpDir, "bogus-track", "alpha", "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	submit := func(continueOnError bool) (map[string]interface{}, error) {
+		var buf bytes.Buffer
+		Out = &buf
+
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		flags.Set("retries", "0")
+		flags.Set("json", "true")
+		flags.Set("also-submit-to", "bogus-track/bravo")
+		if continueOnError {
+			flags.Set("continue-on-error", "true")
+		}
+		err := runSubmit(context.Background(), cfg, flags, []string{file})
+
+		var report map[string]interface{}
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &report))
+		return report, err
+	}
+
+	// Every submission failed, and the report still says so.
+	report, err := submit(true)
+	if assert.Error(t, err) {
+		assert.Equal(t, "2 of 2 submissions failed", err.Error())
+	}
+	assert.NotContains(t, report, "solution_id")
+	if failures, ok := report["failures"].([]interface{}); assert.True(t, ok) && assert.Len(t, failures, 2) {
+		first := failures[0].(map[string]interface{})
+		assert.Equal(t, "alpha", first["exercise"])
+		assert.Regexp(t, "API returned 500", first["error"])
+		assert.Equal(t, "bravo", failures[1].(map[string]interface{})["exercise"])
+	}
+	assert.Equal(t, []interface{}{}, report["warnings"])
+
+	// Without --continue-on-error, it stops at the first failure, but still reports it.
+	report, err = submit(false)
+	if assert.Error(t, err) {
+		assert.Regexp(t, "500", err.Error())
+	}
+	if failures, ok := report["failures"].([]interface{}); assert.True(t, ok) {
+		assert.Len(t, failures, 1)
+	}
+}
+
+func TestSubmitPreSubmitHook(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+	var errBuf bytes.Buffer
+	Err = &errBuf
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-hook")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	setHook := func(command string) {
+		v.Set("hooks.presubmit", []interface{}{
+			map[string]interface{}{"track": "bogus-track", "command": command},
+		})
+	}
+	submit := func(noVerify bool) error {
+		for k := range submittedFiles {
+			delete(submittedFiles, k)
+		}
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		// The same files are submitted more than once.
+		flags.Set("force", "true")
+		if noVerify {
+			flags.Set("no-verify", "true")
+		}
+		return runSubmit(context.Background(), cfg, flags, []string{file})
+	}
+
+	// The hook runs in the exercise directory.
+	setHook("echo checked > hook-ran.txt")
+	err = submit(false)
+	assert.NoError(t, err)
+	_, err = os.Stat(filepath.Join(dir, "hook-ran.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(submittedFiles))
+
+	// Nothing is submitted when it fails.
+	setHook("echo something is wrong && exit 3")
+	err = submit(false)
+	if assert.Error(t, err) {
+		assert.Regexp(t, "The pre-submit command failed", err.Error())
+		assert.Regexp(t, "--no-verify", err.Error())
+	}
+	assert.Equal(t, 0, len(submittedFiles))
+	assert.Regexp(t, "something is wrong", errBuf.String())
+
+	err = submit(true)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(submittedFiles))
+}
+
+func TestSubmitAPIErrors(t *testing.T) {
+	oldOut := Out
+	oldErr := Err
+	Out = ioutil.Discard
+	Err = ioutil.Discard
+	defer func() {
+		Out = oldOut
+		Err = oldErr
+	}()
+
+	var status int
+	var body string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "PATCH" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		ioutil.ReadAll(r.Body)
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-api-errors")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("This is a file."), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	testCases := []struct {
+		desc     string
+		status   int
+		body     string
+		expected string
+	}{
+		{
+			desc:     "solution not found",
+			status:   http.StatusNotFound,
+			body:     `{"error": {"type": "solution_not_found", "message": "Solution not found"}}`,
+			expected: "download --exercise=bogus-exercise --track=bogus-track",
+		},
+		{
+			desc:     "not found without details",
+			status:   http.StatusNotFound,
+			body:     "",
+			expected: "doesn't know about the solution",
+		},
+		{
+			desc:     "too many iterations",
+			status:   http.StatusForbidden,
+			body:     `{"error": {"type": "too_many_iterations", "message": "You can submit 10 iterations a day."}}`,
+			expected: "too many iterations of this solution.\n    You can submit 10 iterations a day.",
+		},
+		{
+			desc:     "token revoked",
+			status:   http.StatusUnauthorized,
+			body:     `{"error": {"type": "token_revoked", "message": "Token revoked"}}`,
+			expected: "configure --token=YOUR_TOKEN",
+		},
+		{
+			desc:     "unauthorized without details",
+			status:   http.StatusUnauthorized,
+			body:     "",
+			expected: "Your API token is no longer valid",
+		},
+		{
+			desc:     "unknown error",
+			status:   http.StatusUnprocessableEntity,
+			body:     `{"error": {"type": "something_else", "message": "Something went wrong"}}`,
+			expected: "API returned 422 Unprocessable Entity: Something went wrong",
+		},
+		{
+			desc:     "not JSON",
+			status:   http.StatusBadRequest,
+			body:     "<html>Bad request</html>",
+			expected: "API returned 400 Bad Request",
+		},
+	}
+
+	for _, tc := range testCases {
+		status, body = tc.status, tc.body
+
+		flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+		setupSubmitFlags(flags)
+		err := runSubmit(context.Background(), cfg, flags, []string{file})
+		if assert.Error(t, err, tc.desc) {
+			assert.Contains(t, err.Error(), tc.expected, tc.desc)
+		}
+	}
+}
+
+func TestSubmitCaseCollisions(t *testing.T) {
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() { Err = oldErr }()
+
+	submittedFiles := map[string]string{}
+	ts := fakeSubmitServer(t, submittedFiles)
+	defer ts.Close()
+
+	tmpDir, err := ioutil.TempDir("", "submit-case-collisions")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(filepath.Join(dir, "lib"), os.FileMode(0755))
+	os.MkdirAll(filepath.Join(dir, "Lib"), os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+
+	names := []string{"bogus.go", "Bogus.go", "lib/helper.go", "Lib/Helper.go", "other.go"}
+	var files []string
+	for _, name := range names {
+		file := filepath.Join(dir, filepath.FromSlash(name))
+		err = ioutil.WriteFile(file, []byte("This is "+name), os.FileMode(0644))
+		assert.NoError(t, err)
+		files = append(files, file)
+	}
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	err = runSubmit(context.Background(), cfg, flags, files)
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "differ only in case")
+		assert.Regexp(t, `bogus\.go\s+Bogus\.go\s+lib/helper\.go\s+Lib/Helper\.go`, err.Error())
+		assert.NotContains(t, err.Error(), "other.go")
+	}
+	assert.Empty(t, submittedFiles)
+
+	// The same file named twice is not a collision.
+	flags = pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	err = runSubmit(context.Background(), cfg, flags, []string{files[0], files[0], files[4]})
+	assert.NoError(t, err)
+	assert.Len(t, submittedFiles, 2)
+}
+
+func TestSubmitTimeout(t *testing.T) {
+	oldErr := Err
+	Err = ioutil.Discard
+	defer func() { Err = oldErr }()
+
+	done := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer ts.Close()
+	defer close(done)
+
+	tmpDir, err := ioutil.TempDir("", "submit-timeout")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	os.MkdirAll(dir, os.FileMode(0755))
+	writeFakeSolution(t, dir, "bogus-track", "bogus-exercise")
+	file := filepath.Join(dir, "bogus.go")
+	err = ioutil.WriteFile(file, []byte("package bogus"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("token", "abc123")
+	v.Set("workspace", tmpDir)
+	v.Set("apibaseurl", ts.URL)
+	v.Set("timeout", "1")
+
+	cfg := config.Config{
+		Persister:       config.InMemoryPersister{},
+		UserViperConfig: v,
+	}
+
+	flags := pflag.NewFlagSet("fake", pflag.PanicOnError)
+	setupSubmitFlags(flags)
+	flags.Set("retries", "0")
+	flags.Set("refresh-capabilities", "false")
+
+	start := time.Now()
+	err = runSubmit(context.Background(), cfg, flags, []string{file})
+	if assert.Error(t, err) {
+		assert.Contains(t, err.Error(), "The submission timed out after 1s.")
+		assert.Contains(t, err.Error(), "config set timeout SECONDS")
+	}
+	assert.True(t, time.Since(start) < 5*time.Second)
+}
+
+func TestIsText(t *testing.T) {
+	dir, err := ioutil.TempDir("", "is-text")
+	defer os.RemoveAll(dir)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		desc     string
+		contents []byte
+		text     bool
+	}{
+		{"empty", []byte{}, true},
+		{"ascii", []byte("package bogus\n"), true},
+		{"utf-8", []byte("// héllo, 世界\n"), true},
+		{"nul byte", []byte("abc\x00def"), false},
+		{"invalid utf-8", []byte("abc\xffdef"), false},
+		// The invalid sequence is well past the reader's buffer.
+		{"invalid utf-8 late", append(bytes.Repeat([]byte("é"), 100000), 0xc3), false},
+		{"long text", bytes.Repeat([]byte("世"), 100000), true},
+	}
+	for i, test := range tests {
+		file := filepath.Join(dir, fmt.Sprintf("file-%d", i))
+		err := ioutil.WriteFile(file, test.contents, os.FileMode(0644))
+		assert.NoError(t, err)
+
+		ok, err := isText(file)
+		assert.NoError(t, err, test.desc)
+		assert.Equal(t, test.text, ok, test.desc)
+	}
+}
+
+func TestTextFilesReadsEachFileOnce(t *testing.T) {
+	dir, err := ioutil.TempDir("", "text-files")
+	defer os.RemoveAll(dir)
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("text"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	texts := newTextFiles()
+	ok, err := texts.isText(file)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// It's remembered, so a change to the file in between makes no difference.
+	err = ioutil.WriteFile(file, []byte("\x00"), os.FileMode(0644))
+	assert.NoError(t, err)
+	ok, err = texts.isText(filepath.Join(dir, ".", "file.txt"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+}
@@ -0,0 +1,80 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// throttledReader limits how fast bytes can be read from the underlying reader.
+type throttledReader struct {
+	r     io.Reader
+	rate  int64
+	start time.Time
+	read  int64
+}
+
+// newThrottledReader wraps r so that it is read at no more than rate bytes per second.
+// A rate of zero or less leaves r untouched.
+func newThrottledReader(r io.Reader, rate int64) io.Reader {
+	if rate <= 0 {
+		return r
+	}
+	return &throttledReader{r: r, rate: rate}
+}
+
+func (t *throttledReader) Read(p []byte) (int, error) {
+	if t.start.IsZero() {
+		t.start = time.Now()
+	}
+
+	// Read in small chunks so that the throughput stays smooth.
+	chunk := t.rate / 10
+	if chunk < 1 {
+		chunk = 1
+	}
+	if int64(len(p)) > chunk {
+		p = p[:chunk]
+	}
+
+	n, err := t.r.Read(p)
+	t.read += int64(n)
+
+	expected := time.Duration(t.read * int64(time.Second) / t.rate)
+	if elapsed := time.Since(t.start); expected > elapsed {
+		time.Sleep(expected - elapsed)
+	}
+	return n, err
+}
+
+// parseByteRate parses a rate in bytes per second, such as 2048, 500k, or 1.5m.
+func parseByteRate(s string) (int64, error) {
+	n, err := parseByteSize(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid rate '%s'. Use a number of bytes per second, optionally with a k or m suffix, e.g. 500k", s)
+	}
+	return n, nil
+}
+
+// parseByteSize parses a number of bytes, such as 2048, 500k, or 1.5m.
+// The k and m suffixes are multiples of 1024.
+func parseByteSize(s string) (int64, error) {
+	str := strings.ToLower(strings.TrimSpace(s))
+	multiplier := 1.0
+	switch {
+	case strings.HasSuffix(str, "k"):
+		multiplier = 1024
+		str = strings.TrimSuffix(str, "k")
+	case strings.HasSuffix(str, "m"):
+		multiplier = 1024 * 1024
+		str = strings.TrimSuffix(str, "m")
+	}
+
+	n, err := strconv.ParseFloat(str, 64)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("'%s' is not a size. Use a number of bytes, optionally with a k or m suffix, e.g. 64k", s)
+	}
+	return int64(n * multiplier), nil
+}
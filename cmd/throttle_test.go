@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"bytes"
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseByteRate(t *testing.T) {
+	testCases := []struct {
+		input    string
+		expected int64
+	}{
+		{input: "0", expected: 0},
+		{input: "2048", expected: 2048},
+		{input: "500k", expected: 500 * 1024},
+		{input: "500K", expected: 500 * 1024},
+		{input: "1.5m", expected: 1536 * 1024},
+	}
+
+	for _, tc := range testCases {
+		rate, err := parseByteRate(tc.input)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, rate, tc.input)
+	}
+
+	for _, input := range []string{"", "fast", "-1", "10g"} {
+		_, err := parseByteRate(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestThrottledReader(t *testing.T) {
+	data := bytes.Repeat([]byte("x"), 3000)
+
+	start := time.Now()
+	b, err := ioutil.ReadAll(newThrottledReader(bytes.NewReader(data), 10000))
+	elapsed := time.Since(start)
+
+	assert.NoError(t, err)
+	assert.Equal(t, data, b)
+	// 3000 bytes at 10000 bytes/sec takes at least 300ms.
+	assert.True(t, elapsed >= 250*time.Millisecond, "read too fast: %s", elapsed)
+	assert.True(t, elapsed < 2*time.Second, "read too slow: %s", elapsed)
+}
+
+func TestThrottledReaderUnlimited(t *testing.T) {
+	r := bytes.NewReader([]byte("x"))
+	assert.Equal(t, r, newThrottledReader(r, 0))
+}
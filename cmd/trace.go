@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+)
+
+// phaseTimer records how long each phase of a command takes.
+type phaseTimer struct {
+	start  time.Time
+	last   time.Time
+	phases []phase
+}
+
+type phase struct {
+	name     string
+	duration time.Duration
+}
+
+func newPhaseTimer() *phaseTimer {
+	now := time.Now()
+	return &phaseTimer{start: now, last: now}
+}
+
+// Mark ends the current phase, recording it under the given name.
+// The next phase starts immediately.
+func (t *phaseTimer) Mark(name string) {
+	now := time.Now()
+	t.phases = append(t.phases, phase{name: name, duration: now.Sub(t.last)})
+	t.last = now
+}
+
+// Print writes a table of the recorded phases.
+func (t *phaseTimer) Print(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	defer tw.Flush()
+
+	fmt.Fprintln(tw, "")
+	fmt.Fprintln(tw, "Phase\tDuration")
+	for _, p := range t.phases {
+		fmt.Fprintf(tw, "%s\t%s\n", p.name, p.duration)
+	}
+	fmt.Fprintf(tw, "total\t%s\n", t.last.Sub(t.start))
+	fmt.Fprintln(tw, "")
+}
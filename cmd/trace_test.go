@@ -0,0 +1,29 @@
+package cmd
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPhaseTimer(t *testing.T) {
+	timer := newPhaseTimer()
+	time.Sleep(5 * time.Millisecond)
+	timer.Mark("first")
+	timer.Mark("second")
+
+	if assert.Equal(t, 2, len(timer.phases)) {
+		assert.Equal(t, "first", timer.phases[0].name)
+		assert.True(t, timer.phases[0].duration >= 5*time.Millisecond)
+		assert.Equal(t, "second", timer.phases[1].name)
+	}
+
+	var buf bytes.Buffer
+	timer.Print(&buf)
+	assert.Regexp(t, "Phase +Duration", buf.String())
+	assert.Regexp(t, "first +[0-9.]+m?s", buf.String())
+	assert.Regexp(t, "second +", buf.String())
+	assert.Regexp(t, "total +", buf.String())
+}
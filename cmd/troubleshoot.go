@@ -32,14 +32,17 @@ command into a GitHub issue so we can help figure out what's going on.
 		cli.TimeoutInSeconds = cli.TimeoutInSeconds * 2
 		c := cli.New(Version)
 
-		cfg := config.NewConfig()
+		cfg, err := newConfig(false)
+		if err != nil {
+			return err
+		}
 
 		v := viper.New()
 		v.AddConfigPath(cfg.Dir)
 		v.SetConfigName("user")
 		v.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = v.ReadInConfig()
+		// A missing file is fine.
+		readConfigFile(v, cfg.Dir, "user")
 
 		cfg.UserViperConfig = v
 
@@ -179,7 +182,7 @@ func newSystemStatus() systemStatus {
 func newConfigurationStatus(status *Status) configurationStatus {
 	v := status.cfg.UserViperConfig
 
-	workspace := v.GetString("workspace")
+	workspace := config.WorkspaceFor(v)
 	if workspace == "" {
 		workspace = fmt.Sprintf("%s (default)", config.DefaultWorkspaceDir(status.cfg))
 	}
@@ -213,6 +216,9 @@ func (ping *apiPing) Call(wg *sync.WaitGroup) {
 }
 
 func redact(token string) string {
+	if len(token) < 8 {
+		return strings.Repeat("*", len(token))
+	}
 	str := token[4 : len(token)-3]
 	redaction := strings.Repeat("*", len(str))
 	return string(token[:4]) + redaction + string(token[len(token)-3:])
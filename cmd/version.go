@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
 
 	"github.com/exercism/cli/cli"
 	"github.com/spf13/cobra"
@@ -14,6 +16,15 @@ const Version = "3.0.9"
 // checkLatest flag for version command.
 var checkLatest bool
 
+// checkVersion and versionJSON flags for version command.
+var (
+	checkVersion bool
+	versionJSON  bool
+)
+
+// changelogURL is where to read about what changed, if a release doesn't link to its own notes.
+const changelogURL = "https://github.com/exercism/cli/blob/master/CHANGELOG.md"
+
 // versionCmd outputs the version of the CLI.
 var versionCmd = &cobra.Command{
 	Use:     "version",
@@ -23,9 +34,21 @@ var versionCmd = &cobra.Command{
 
 To check for the latest available version, call the command with the
 --latest flag.
+
+To find out whether this version is up to date, and where to read about
+what's new if it isn't, call the command with the --check flag.
+Add --json to get the answer in a form that's easy to use from scripts.
 	`,
 
 	RunE: func(cmd *cobra.Command, args []string) error {
+		if checkVersion || versionJSON {
+			var c *cli.CLI
+			if checkVersion {
+				c = cli.New(Version)
+			}
+			return runVersionCheck(Out, c, versionJSON)
+		}
+
 		fmt.Println(currentVersion())
 
 		if checkLatest {
@@ -66,7 +89,72 @@ func checkForUpdate(c *cli.CLI) (string, error) {
 
 }
 
+// versionCheck describes how the current version compares to the latest release.
+type versionCheck struct {
+	Current      string `json:"current"`
+	Latest       string `json:"latest,omitempty"`
+	UpToDate     *bool  `json:"upToDate,omitempty"`
+	ChangelogURL string `json:"changelogUrl,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// runVersionCheck reports the current version, and if c is not nil,
+// how it compares to the latest release.
+// Failing to reach the releases API is reported, but isn't an error.
+func runVersionCheck(w io.Writer, c *cli.CLI, asJSON bool) error {
+	status := versionCheck{Current: Version}
+	if c != nil {
+		ok, err := c.IsUpToDate()
+		if err != nil {
+			status.Error = err.Error()
+		} else {
+			status.Latest = c.LatestRelease.Version()
+			status.UpToDate = &ok
+			if !ok {
+				status.ChangelogURL = changelogURL
+				if c.LatestRelease.Location != "" {
+					status.ChangelogURL = c.LatestRelease.Location
+				}
+			}
+		}
+	}
+
+	if asJSON {
+		b, err := json.MarshalIndent(status, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(w, "%s\n", b)
+		return nil
+	}
+
+	fmt.Fprintln(w, currentVersion())
+	switch {
+	case status.Error != "":
+		msg := `
+    Unable to check for the latest version:
+
+        %s
+
+    Check your network connection, and try again later.
+
+`
+		fmt.Fprintf(Err, msg, status.Error)
+	case status.UpToDate == nil:
+		// Nothing was checked.
+	case *status.UpToDate:
+		fmt.Fprintln(w, "Your CLI version is up to date.")
+	default:
+		fmt.Fprintf(w, "A new CLI version is available: %s\n", status.Latest)
+		fmt.Fprintf(w, "Read about what's new at %s\n", status.ChangelogURL)
+		fmt.Fprintf(w, "Run `%s upgrade` to update.\n", BinaryName)
+	}
+	return nil
+}
+
 func init() {
 	RootCmd.AddCommand(versionCmd)
 	versionCmd.Flags().BoolVarP(&checkLatest, "latest", "l", false, "check latest available version")
+	versionCmd.Flags().BoolVarP(&checkVersion, "check", "", false, "check whether this is the latest version")
+	versionCmd.Flags().BoolVarP(&versionJSON, "json", "", false, "print the version information as JSON")
 }
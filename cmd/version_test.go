@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
@@ -61,3 +63,72 @@ func TestVersionUpdateCheck(t *testing.T) {
 		})
 	}
 }
+
+func TestVersionCheck(t *testing.T) {
+	oldErr := Err
+	defer func() {
+		Err = oldErr
+	}()
+
+	fakeEndpoint := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, `{"tag_name": "v2.0.0", "html_url": "https://example.com/releases/v2.0.0"}`)
+	})
+	ts := httptest.NewServer(fakeEndpoint)
+	defer ts.Close()
+	oldReleaseURL := cli.ReleaseURL
+	cli.ReleaseURL = ts.URL
+	defer func() {
+		cli.ReleaseURL = oldReleaseURL
+	}()
+
+	var out bytes.Buffer
+	err := runVersionCheck(&out, &cli.CLI{Version: "1.0.0"}, false)
+	assert.NoError(t, err)
+	assert.Regexp(t, "A new CLI version is available: 2.0.0", out.String())
+	assert.Regexp(t, "https://example.com/releases/v2.0.0", out.String())
+
+	out.Reset()
+	err = runVersionCheck(&out, &cli.CLI{Version: "2.0.0"}, false)
+	assert.NoError(t, err)
+	assert.Regexp(t, "up to date", out.String())
+
+	out.Reset()
+	err = runVersionCheck(&out, &cli.CLI{Version: "1.0.0"}, true)
+	assert.NoError(t, err)
+	var status map[string]interface{}
+	err = json.Unmarshal(out.Bytes(), &status)
+	assert.NoError(t, err)
+	assert.Equal(t, Version, status["current"])
+	assert.Equal(t, "2.0.0", status["latest"])
+	assert.Equal(t, false, status["upToDate"])
+}
+
+func TestVersionCheckOffline(t *testing.T) {
+	oldErr := Err
+	var errOut bytes.Buffer
+	Err = &errOut
+	defer func() {
+		Err = oldErr
+	}()
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	oldReleaseURL := cli.ReleaseURL
+	cli.ReleaseURL = ts.URL
+	defer func() {
+		cli.ReleaseURL = oldReleaseURL
+	}()
+	// Nothing is listening anymore.
+	ts.Close()
+
+	var out bytes.Buffer
+	err := runVersionCheck(&out, &cli.CLI{Version: "1.0.0"}, false)
+	assert.NoError(t, err)
+	assert.Regexp(t, "exercism version", out.String())
+	assert.Regexp(t, "Unable to check for the latest version", errOut.String())
+
+	out.Reset()
+	err = runVersionCheck(&out, &cli.CLI{Version: "1.0.0"}, true)
+	assert.NoError(t, err)
+	assert.Regexp(t, `"error": `, out.String())
+	assert.NotRegexp(t, "upToDate", out.String())
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// warnings prints warnings for people to read, and keeps a one-line summary
+// of each for structured output.
+type warnings []string
+
+func (ws *warnings) print(w io.Writer, msg string, args ...interface{}) {
+	s := fmt.Sprintf(msg, args...)
+	fmt.Fprint(w, s)
+	*ws = append(*ws, summarizeWarning(s))
+}
+
+// printAfterSubmit warns that something went wrong after the submission
+// went through. That can't be undone, so it isn't an error: failing the
+// command would only make people submit the same files again.
+func (ws *warnings) printAfterSubmit(w io.Writer, what string, err error) {
+	msg := `
+
+    WARNING: Unable to %s
+             %s
+
+`
+	ws.print(w, msg, what, err)
+}
+
+// summarizeWarning reduces a warning to its first paragraph, on one line.
+// The paragraphs after it are advice, which is left out.
+func summarizeWarning(s string) string {
+	s = strings.TrimSpace(s)
+	if i := strings.Index(s, "\n\n"); i >= 0 {
+		s = s[:i]
+	}
+	s = strings.TrimPrefix(s, "WARNING: ")
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWarnings(t *testing.T) {
+	var buf bytes.Buffer
+	var warned warnings
+
+	msg := `
+
+    WARNING: Submitting a file that is not UTF-8 text
+             %s
+
+    Pass --allow-binary to silence this warning.
+
+`
+	warned.print(&buf, msg, "/path/to/file")
+	warned.print(&buf, "\n    WARNING: Unable to open %s in the browser.\n", "http://example.com")
+
+	assert.Regexp(t, "Pass --allow-binary to silence this warning", buf.String())
+	assert.Equal(t, warnings{
+		"Submitting a file that is not UTF-8 text /path/to/file",
+		"Unable to open http://example.com in the browser.",
+	}, warned)
+}
+
+func TestWarningsAfterSubmit(t *testing.T) {
+	var buf bytes.Buffer
+	var warned warnings
+
+	warned.printAfterSubmit(&buf, "record the submission in the history", errors.New("disk full"))
+
+	assert.Equal(t, "\n\n    WARNING: Unable to record the submission in the history\n             disk full\n\n", buf.String())
+	assert.Equal(t, warnings{"Unable to record the submission in the history disk full"}, warned)
+}
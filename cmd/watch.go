@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+var (
+	// watchInterval is how often watched files are checked for changes.
+	watchInterval = 500 * time.Millisecond
+	// watchDebounce is how long files have to stay unchanged before they
+	// are submitted, so that a burst of saves leads to a single submission.
+	watchDebounce = time.Second
+)
+
+// fileStamp is what we compare to tell whether a file has changed.
+type fileStamp struct {
+	modTime time.Time
+	size    int64
+}
+
+// stampFiles records the state of the given files and of the files in the
+// given directories. Hidden files and directories are skipped, since that's
+// where the solution metadata and editors' scratch files live.
+func stampFiles(paths []string) (map[string]fileStamp, error) {
+	stamps := map[string]fileStamp{}
+	for _, path := range paths {
+		err := filepath.Walk(path, func(file string, info os.FileInfo, err error) error {
+			if err != nil {
+				// The file may have been removed while we were looking.
+				if os.IsNotExist(err) {
+					return nil
+				}
+				return err
+			}
+			if file != path && strings.HasPrefix(info.Name(), ".") {
+				if info.IsDir() {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if !info.IsDir() {
+				stamps[file] = fileStamp{modTime: info.ModTime(), size: info.Size()}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return stamps, nil
+}
+
+func sameStamps(a, b map[string]fileStamp) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, stamp := range a {
+		other, ok := b[path]
+		if !ok || !stamp.modTime.Equal(other.modTime) || stamp.size != other.size {
+			return false
+		}
+	}
+	return true
+}
+
+// watchAndSubmit submits once, and then again every time the watched files
+// change, until the context is cancelled. Failed submissions are reported,
+// but don't stop the watching.
+func watchAndSubmit(ctx context.Context, paths []string, submit func() error) error {
+	if len(paths) == 0 {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return err
+		}
+		paths = []string{cwd}
+	}
+	// Patterns are expanded once, as they are for the submission, so
+	// files that match them later on aren't watched.
+	paths, err := expandGlobs(paths)
+	if err != nil {
+		return err
+	}
+
+	stamps, err := stampFiles(paths)
+	if err != nil {
+		return err
+	}
+
+	for {
+		if err := submit(); err != nil {
+			if err == errInterrupted || ctx.Err() != nil {
+				return nil
+			}
+			fmt.Fprintf(Err, "\nSubmitting failed: %s\n", err)
+		}
+		fmt.Fprint(Err, "\nWatching for changes. Press Ctrl+C to stop.\n")
+
+		if stamps, err = awaitChanges(ctx, paths, stamps); err != nil {
+			return err
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+	}
+}
+
+// awaitChanges waits until the watched files change, and then until they
+// have stopped changing for watchDebounce. It returns their new state.
+func awaitChanges(ctx context.Context, paths []string, stamps map[string]fileStamp) (map[string]fileStamp, error) {
+	changed := false
+	var lastChange time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return stamps, nil
+		case <-time.After(watchInterval):
+		}
+
+		current, err := stampFiles(paths)
+		if err != nil {
+			return nil, err
+		}
+		if !sameStamps(stamps, current) {
+			changed = true
+			lastChange = time.Now()
+			stamps = current
+			continue
+		}
+		if changed && time.Since(lastChange) >= watchDebounce {
+			return stamps, nil
+		}
+	}
+}
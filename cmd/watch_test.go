@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchAndSubmit(t *testing.T) {
+	oldErr := Err
+	oldInterval := watchInterval
+	oldDebounce := watchDebounce
+	Err = ioutil.Discard
+	defer func() {
+		Err = oldErr
+		watchInterval = oldInterval
+		watchDebounce = oldDebounce
+	}()
+	watchInterval = 5 * time.Millisecond
+	watchDebounce = 20 * time.Millisecond
+
+	tmpDir, err := ioutil.TempDir("", "watch")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	file := filepath.Join(tmpDir, "file.txt")
+	err = ioutil.WriteFile(file, []byte("one"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	submits := make(chan int)
+	var n int
+	submit := func() error {
+		n++
+		// Writing metadata must not count as a change.
+		ioutil.WriteFile(filepath.Join(tmpDir, ".solution.json"), []byte{byte(n)}, os.FileMode(0644))
+		submits <- n
+		// A failed submission doesn't stop the watching.
+		return errors.New("boom")
+	}
+
+	done := make(chan error)
+	go func() {
+		done <- watchAndSubmit(ctx, []string{tmpDir}, submit)
+	}()
+
+	// It submits right away.
+	assert.Equal(t, 1, <-submits)
+
+	// A burst of changes is submitted once.
+	for _, contents := range []string{"two", "three!", "four!!"} {
+		err = ioutil.WriteFile(file, []byte(contents), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+	assert.Equal(t, 2, <-submits)
+
+	select {
+	case n := <-submits:
+		t.Fatalf("unexpected submission %d without any changes", n)
+	case <-time.After(10 * watchDebounce):
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestWatchAndSubmitGlobs(t *testing.T) {
+	oldErr := Err
+	oldInterval := watchInterval
+	oldDebounce := watchDebounce
+	Err = ioutil.Discard
+	defer func() {
+		Err = oldErr
+		watchInterval = oldInterval
+		watchDebounce = oldDebounce
+	}()
+	watchInterval = 5 * time.Millisecond
+	watchDebounce = 20 * time.Millisecond
+
+	tmpDir, err := ioutil.TempDir("", "watch-globs")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	file := filepath.Join(tmpDir, "file.go")
+	err = ioutil.WriteFile(file, []byte("one"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	submit := func() error {
+		t.Fatal("unexpected submission")
+		return nil
+	}
+
+	// A pattern that matches nothing fails right away.
+	err = watchAndSubmit(context.Background(), []string{filepath.Join(tmpDir, "*.rb")}, submit)
+	if assert.Error(t, err) {
+		assert.Regexp(t, "No files match the pattern", err.Error())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	submits := make(chan struct{})
+	done := make(chan error)
+	go func() {
+		done <- watchAndSubmit(ctx, []string{filepath.Join(tmpDir, "*.go")}, func() error {
+			submits <- struct{}{}
+			return nil
+		})
+	}()
+	<-submits
+
+	// The files that the pattern matches are watched.
+	err = ioutil.WriteFile(file, []byte("two!"), os.FileMode(0644))
+	assert.NoError(t, err)
+	select {
+	case <-submits:
+	case <-time.After(time.Second):
+		t.Fatal("changing a file that matches the pattern wasn't submitted")
+	}
+
+	cancel()
+	assert.NoError(t, <-done)
+}
+
+func TestStampFiles(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "stamp-files")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	for _, name := range []string{"file.txt", ".hidden", filepath.Join(".exercism", "config.json"), filepath.Join("lib", "helper.txt")} {
+		path := filepath.Join(tmpDir, name)
+		os.MkdirAll(filepath.Dir(path), os.FileMode(0755))
+		err = ioutil.WriteFile(path, []byte("x"), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	stamps, err := stampFiles([]string{tmpDir})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(stamps))
+	assert.Contains(t, stamps, filepath.Join(tmpDir, "file.txt"))
+	assert.Contains(t, stamps, filepath.Join(tmpDir, "lib", "helper.txt"))
+
+	// Missing files are left out rather than failing.
+	stamps, err = stampFiles([]string{filepath.Join(tmpDir, "missing.txt")})
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(stamps))
+}
@@ -27,16 +27,19 @@ need to be on the same drive as your workspace directory. Otherwise
 nothing will happen.
 	`,
 	RunE: func(cmd *cobra.Command, args []string) error {
-		cfg := config.NewConfig()
+		cfg, err := newConfig(true)
+		if err != nil {
+			return err
+		}
 
 		v := viper.New()
 		v.AddConfigPath(cfg.Dir)
 		v.SetConfigName("user")
 		v.SetConfigType("json")
-		// Ignore error. If the file doesn't exist, that is fine.
-		_ = v.ReadInConfig()
+		// A missing file is fine.
+		readConfigFile(v, cfg.Dir, "user")
 
-		fmt.Fprintf(Out, "%s\n", v.GetString("workspace"))
+		fmt.Fprintf(Out, "%s\n", config.WorkspaceFor(v))
 		return nil
 	},
 }
@@ -0,0 +1,29 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFileAtomic(t *testing.T) {
+	dir, err := ioutil.TempDir("", "atomic")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "user.json")
+	assert.NoError(t, WriteFileAtomic(path, []byte("old"), os.FileMode(0644)))
+	assert.NoError(t, WriteFileAtomic(path, []byte("new"), os.FileMode(0644)))
+
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, "new", string(b))
+
+	// No temporary files are left behind.
+	entries, err := ioutil.ReadDir(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+}
@@ -1,7 +1,12 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -25,6 +30,7 @@ type Config struct {
 	Dir             string
 	DefaultBaseURL  string
 	DefaultDirName  string
+	Profile         string
 	UserViperConfig *viper.Viper
 	Persister       Persister
 }
@@ -132,3 +138,62 @@ func InferSiteURL(apiURL string) string {
 func SettingsURL(apiURL string) string {
 	return fmt.Sprintf("%s%s", InferSiteURL(apiURL), "/my/settings")
 }
+
+var teamSlugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// ValidateTeamSlug ensures that a team slug is well formed.
+// Slugs are lowercase letters and digits, separated by single dashes.
+func ValidateTeamSlug(slug string) error {
+	if !teamSlugPattern.MatchString(slug) {
+		return fmt.Errorf("invalid team slug '%s'. Use lowercase letters, digits, and dashes, e.g. my-class", slug)
+	}
+	return nil
+}
+
+// TeamSolutionURL is the endpoint for submitting a solution on behalf of a team.
+func TeamSolutionURL(apiURL, team, solutionID string) string {
+	if apiURL == "" {
+		apiURL = defaultBaseURL
+	}
+	return fmt.Sprintf("%s/teams/%s/solutions/%s", apiURL, team, solutionID)
+}
+
+// NewConfigFromReader provides a configuration whose user config is read
+// from r, e.g. standard input, rather than from the config dir.
+// Changes to it are never written to disk.
+func NewConfigFromReader(r io.Reader) (Config, error) {
+	v, err := ReadUserConfig(r)
+	if err != nil {
+		return Config{}, err
+	}
+	cfg := NewConfig()
+	cfg.UserViperConfig = v
+	cfg.Persister = InMemoryPersister{}
+	return cfg, nil
+}
+
+// ReadUserConfig parses a user config in JSON format.
+func ReadUserConfig(r io.Reader) (*viper.Viper, error) {
+	b, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(b)) == 0 {
+		return nil, errors.New("the config is empty. Expected a JSON object")
+	}
+
+	var settings map[string]interface{}
+	if err := json.Unmarshal(b, &settings); err != nil {
+		if e, ok := err.(*json.SyntaxError); ok {
+			return nil, fmt.Errorf("the config is not valid JSON (at byte %d): %s", e.Offset, e)
+		}
+		return nil, errors.New("the config must be a JSON object")
+	}
+
+	v := viper.New()
+	v.SetConfigType("json")
+	if err := v.ReadConfig(bytes.NewReader(b)); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,3 +23,41 @@ func TestInferSiteURL(t *testing.T) {
 		assert.Equal(t, InferSiteURL(tc.api), tc.url)
 	}
 }
+
+func TestValidateTeamSlug(t *testing.T) {
+	for _, slug := range []string{"team", "my-class", "cs101", "cs-101-fall"} {
+		assert.NoError(t, ValidateTeamSlug(slug), slug)
+	}
+	for _, slug := range []string{"", "My-Class", "my_class", "-class", "class-", "my--class", "my/class"} {
+		assert.Error(t, ValidateTeamSlug(slug), slug)
+	}
+}
+
+func TestTeamSolutionURL(t *testing.T) {
+	assert.Equal(t, "http://example.com/api/v1/teams/my-class/solutions/abc", TeamSolutionURL("http://example.com/api/v1", "my-class", "abc"))
+	assert.Equal(t, "https://api.exercism.io/v1/teams/my-class/solutions/abc", TeamSolutionURL("", "my-class", "abc"))
+}
+
+func TestNewConfigFromReader(t *testing.T) {
+	cfg, err := NewConfigFromReader(strings.NewReader(`{"token": "abc123", "workspace": "/tmp/exercism", "apibaseurl": "http://example.com"}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "abc123", cfg.UserViperConfig.GetString("token"))
+	assert.Equal(t, "/tmp/exercism", cfg.UserViperConfig.GetString("workspace"))
+	assert.Equal(t, "http://example.com", cfg.UserViperConfig.GetString("apibaseurl"))
+	assert.Equal(t, InMemoryPersister{}, cfg.Persister)
+
+	testCases := []struct {
+		input string
+		err   string
+	}{
+		{input: "", err: "the config is empty"},
+		{input: `{"token": "abc123",}`, err: "not valid JSON"},
+		{input: `["abc123"]`, err: "must be a JSON object"},
+	}
+	for _, tc := range testCases {
+		_, err := NewConfigFromReader(strings.NewReader(tc.input))
+		if assert.Error(t, err, tc.input) {
+			assert.Regexp(t, tc.err, err.Error())
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package config
+
+import "github.com/spf13/viper"
+
+// preSubmitKey holds the commands to run before submitting, such as a
+// formatter or the tests. Each is tied to a track, or to a single exercise.
+// They're stored as a list for the same reason as the per-API workspaces.
+const preSubmitKey = "hooks.presubmit"
+
+// postDownloadKey holds the commands to run after downloading, such as
+// installing the dependencies of the exercise. They're listed the same
+// way as the pre-submit commands.
+const postDownloadKey = "hooks.postdownload"
+
+// commandHook ties a command to a track, or to an exercise in a track.
+// A hook without a track applies to every track.
+type commandHook struct {
+	Track    string `json:"track"`
+	Exercise string `json:"exercise"`
+	Command  string `json:"command"`
+}
+
+// readHooks reads the hooks under key from the config.
+// When read from a file, they come back as generic maps.
+func readHooks(v *viper.Viper, key string) []commandHook {
+	var hooks []commandHook
+	switch items := v.Get(key).(type) {
+	case []commandHook:
+		hooks = append(hooks, items...)
+	case []interface{}:
+		for _, item := range items {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			track, _ := m["track"].(string)
+			exercise, _ := m["exercise"].(string)
+			command, _ := m["command"].(string)
+			hooks = append(hooks, commandHook{Track: track, Exercise: exercise, Command: command})
+		}
+	}
+	return hooks
+}
+
+// PreSubmitCommand provides the command to run before submitting an exercise.
+// A hook for the exercise takes precedence over one for its track, which
+// takes precedence over one for every track. It's empty if there is none.
+func PreSubmitCommand(v *viper.Viper, track, exercise string) string {
+	return hookCommand(readHooks(v, preSubmitKey), track, exercise)
+}
+
+// PostDownloadCommand provides the command to run after downloading an
+// exercise, with the same precedence as PreSubmitCommand.
+func PostDownloadCommand(v *viper.Viper, track, exercise string) string {
+	return hookCommand(readHooks(v, postDownloadKey), track, exercise)
+}
+
+// hookCommand picks the most specific of the hooks that apply to the exercise.
+func hookCommand(hooks []commandHook, track, exercise string) string {
+	var command string
+	best := -1
+	for _, hook := range hooks {
+		if hook.Command == "" || (hook.Track != "" && hook.Track != track) {
+			continue
+		}
+		if hook.Exercise != "" && (hook.Track == "" || hook.Exercise != exercise) {
+			continue
+		}
+		rank := 0
+		if hook.Track != "" {
+			rank++
+		}
+		if hook.Exercise != "" {
+			rank++
+		}
+		if rank > best {
+			command, best = hook.Command, rank
+		}
+	}
+	return command
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreSubmitCommand(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("json")
+	err := v.ReadConfig(strings.NewReader(`{
+		"hooks": {
+			"presubmit": [
+				{"track": "go", "exercise": "bob", "command": "go test"},
+				{"track": "go", "command": "gofmt -l ."},
+				{"command": "echo checking"},
+				{"track": "rust", "command": ""},
+				{"exercise": "bob", "command": "not tied to a track"}
+			]
+		}
+	}`))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		track, exercise string
+		expected        string
+	}{
+		{"go", "bob", "go test"},
+		{"go", "leap", "gofmt -l ."},
+		{"rust", "bob", "echo checking"},
+		{"python", "leap", "echo checking"},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, PreSubmitCommand(v, tc.track, tc.exercise), tc.track+"/"+tc.exercise)
+	}
+
+	assert.Equal(t, "", PreSubmitCommand(viper.New(), "go", "bob"))
+}
+
+func TestPostDownloadCommand(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("json")
+	err := v.ReadConfig(strings.NewReader(`{
+		"hooks": {
+			"presubmit": [{"track": "go", "command": "go test"}],
+			"postdownload": [
+				{"track": "go", "command": "go mod tidy"},
+				{"track": "javascript", "command": "npm install"},
+				{"track": "javascript", "exercise": "hello-world", "command": "npm ci"}
+			]
+		}
+	}`))
+	assert.NoError(t, err)
+
+	assert.Equal(t, "go mod tidy", PostDownloadCommand(v, "go", "bob"))
+	assert.Equal(t, "npm install", PostDownloadCommand(v, "javascript", "bob"))
+	assert.Equal(t, "npm ci", PostDownloadCommand(v, "javascript", "hello-world"))
+	assert.Equal(t, "", PostDownloadCommand(v, "ruby", "bob"))
+}
@@ -0,0 +1,126 @@
+package config
+
+import (
+	"bytes"
+	"io/ioutil"
+	"path/filepath"
+
+	"github.com/spf13/viper"
+)
+
+// ReadInConfig reads the JSON config file <dir>/<basename>.json into v.
+// Hand-edited files sometimes contain comments or trailing commas,
+// which aren't valid JSON. Rather than rejecting them, they are read
+// without those, and lenient is true. Saving the config afterwards
+// writes it back as strict JSON.
+func ReadInConfig(v *viper.Viper, dir, basename string) (lenient bool, err error) {
+	b, err := ioutil.ReadFile(filepath.Join(dir, basename+".json"))
+	if err != nil {
+		return false, err
+	}
+
+	v.SetConfigType("json")
+	err = v.ReadConfig(bytes.NewReader(b))
+	if err == nil {
+		return false, nil
+	}
+
+	stripped := stripJSONExtensions(b)
+	if bytes.Equal(stripped, b) {
+		return false, err
+	}
+	if err := v.ReadConfig(bytes.NewReader(stripped)); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// stripJSONExtensions removes // and /* */ comments,
+// and commas that directly precede a closing brace or bracket.
+// Anything inside strings is left alone.
+func stripJSONExtensions(b []byte) []byte {
+	return stripTrailingCommas(stripComments(b))
+}
+
+func stripComments(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	inString := false
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if inString {
+			out = append(out, c)
+			switch c {
+			case '\\':
+				if i+1 < len(b) {
+					i++
+					out = append(out, b[i])
+				}
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch {
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(b) && b[i+1] == '/':
+			// Keep the newline, so that line numbers in errors stay correct.
+			for i < len(b) && b[i] != '\n' {
+				i++
+			}
+			if i < len(b) {
+				out = append(out, '\n')
+			}
+		case c == '/' && i+1 < len(b) && b[i+1] == '*':
+			i += 2
+			for i < len(b) && !(b[i] == '*' && i+1 < len(b) && b[i+1] == '/') {
+				if b[i] == '\n' {
+					out = append(out, '\n')
+				}
+				i++
+			}
+			i++
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func stripTrailingCommas(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	inString := false
+	for i := 0; i < len(b); i++ {
+		c := b[i]
+		if inString {
+			out = append(out, c)
+			switch c {
+			case '\\':
+				if i+1 < len(b) {
+					i++
+					out = append(out, b[i])
+				}
+			case '"':
+				inString = false
+			}
+			continue
+		}
+
+		if c == '"' {
+			inString = true
+		}
+		if c == ',' {
+			j := i + 1
+			for j < len(b) && (b[j] == ' ' || b[j] == '\t' || b[j] == '\r' || b[j] == '\n') {
+				j++
+			}
+			if j < len(b) && (b[j] == '}' || b[j] == ']') {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
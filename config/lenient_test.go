@@ -0,0 +1,87 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStripJSONExtensions(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		input    string
+		expected string
+	}{
+		{
+			desc:     "line comments",
+			input:    "{\n  // the token\n  \"token\": \"abc\" // trailing\n}",
+			expected: "{\n  \n  \"token\": \"abc\" \n}",
+		},
+		{
+			desc:     "block comments",
+			input:    "{/* a\nb */\"token\": \"abc\"}",
+			expected: "{\n\"token\": \"abc\"}",
+		},
+		{
+			desc:     "trailing commas",
+			input:    "{\"a\": [1, 2,], \"b\": 3,\n}",
+			expected: "{\"a\": [1, 2], \"b\": 3\n}",
+		},
+		{
+			desc:     "strings are left alone",
+			input:    `{"url": "http://example.com/*x*/", "s": "a,}", "q": "\"//"}`,
+			expected: `{"url": "http://example.com/*x*/", "s": "a,}", "q": "\"//"}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			assert.Equal(t, tc.expected, string(stripJSONExtensions([]byte(tc.input))))
+		})
+	}
+}
+
+func TestReadInConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "lenient-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	write := func(contents string) {
+		err := ioutil.WriteFile(filepath.Join(dir, "user.json"), []byte(contents), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	// Strict JSON.
+	write(`{"token": "abc123", "workspace": "/exercism"}`)
+	v := viper.New()
+	lenient, err := ReadInConfig(v, dir, "user")
+	assert.NoError(t, err)
+	assert.False(t, lenient)
+	assert.Equal(t, "abc123", v.GetString("token"))
+
+	// Hand-edited, with comments and a trailing comma.
+	write(`{
+  // Find the token on the website.
+  "token": "abc123",
+  /* "workspace": "/old", */
+  "workspace": "/exercism",
+}`)
+	v = viper.New()
+	lenient, err = ReadInConfig(v, dir, "user")
+	assert.NoError(t, err)
+	assert.True(t, lenient)
+	assert.Equal(t, "abc123", v.GetString("token"))
+	assert.Equal(t, "/exercism", v.GetString("workspace"))
+
+	// Broken beyond repair.
+	write(`{"token": `)
+	_, err = ReadInConfig(viper.New(), dir, "user")
+	assert.Error(t, err)
+
+	_, err = ReadInConfig(viper.New(), filepath.Join(dir, "missing"), "user")
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bufio"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// NetrcEnvVar names the environment variable that can point to a .netrc file
+// in a non-default location.
+const NetrcEnvVar = "NETRC"
+
+// netrcPath is the location of the user's .netrc file.
+func netrcPath(cfg Config) string {
+	if path := os.Getenv(NetrcEnvVar); path != "" {
+		return Resolve(path, cfg.Home)
+	}
+	name := ".netrc"
+	if runtime.GOOS == "windows" {
+		name = "_netrc"
+	}
+	return filepath.Join(cfg.Home, name)
+}
+
+// NetrcToken looks for a token for the API's host in the user's .netrc file.
+// The token is the password of the machine entry for that host.
+// Entries for other hosts, including the default entry, are ignored.
+// A missing .netrc file is not an error; the token is simply empty.
+func NetrcToken(cfg Config, apiURL string) (string, error) {
+	u, err := url.Parse(apiURL)
+	if err != nil || u.Hostname() == "" {
+		return "", nil
+	}
+
+	f, err := os.Open(netrcPath(cfg))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	return netrcPassword(f, u.Hostname())
+}
+
+// netrcPassword finds the password for a host in .netrc formatted input.
+func netrcPassword(r io.Reader, host string) (string, error) {
+	var tokens []string
+	scanner := bufio.NewScanner(r)
+	inMacro := false
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Macro definitions run until the next blank line.
+		if inMacro {
+			inMacro = strings.TrimSpace(line) != ""
+			continue
+		}
+		if strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+		fields := strings.Fields(line)
+		for i, field := range fields {
+			if field == "macdef" {
+				fields = fields[:i]
+				inMacro = true
+				break
+			}
+		}
+		tokens = append(tokens, fields...)
+	}
+	if err := scanner.Err(); err != nil {
+		return "", err
+	}
+
+	var machine, password string
+	found := false
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "machine", "default":
+			if found {
+				return password, nil
+			}
+			machine = ""
+			if tokens[i] == "machine" && i+1 < len(tokens) {
+				i++
+				machine = tokens[i]
+			}
+			found = strings.EqualFold(machine, host)
+			password = ""
+		case "login", "account", "password":
+			if i+1 < len(tokens) {
+				i++
+				if tokens[i-1] == "password" {
+					password = tokens[i]
+				}
+			}
+		}
+	}
+	if found {
+		return password, nil
+	}
+	return "", nil
+}
@@ -0,0 +1,98 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+const sampleNetrc = `# A comment about github.com
+machine github.com login octocat password gh-token
+
+machine api.exercism.io
+  login exerciser
+  password exercism-token
+  account whatever
+
+macdef init
+machine api.exercism.io password macro-token
+
+default login anonymous password default-token
+`
+
+func TestNetrcPassword(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		netrc    string
+		host     string
+		password string
+	}{
+		{desc: "multi-line entry", netrc: sampleNetrc, host: "api.exercism.io", password: "exercism-token"},
+		{desc: "single-line entry", netrc: sampleNetrc, host: "github.com", password: "gh-token"},
+		{desc: "host is case insensitive", netrc: sampleNetrc, host: "API.Exercism.io", password: "exercism-token"},
+		{desc: "default is ignored", netrc: sampleNetrc, host: "example.com", password: ""},
+		{desc: "entry without password", netrc: "machine api.exercism.io login exerciser", host: "api.exercism.io", password: ""},
+		{desc: "empty", netrc: "", host: "api.exercism.io", password: ""},
+		{desc: "last entry", netrc: "machine a.example.com password a\nmachine api.exercism.io password b", host: "api.exercism.io", password: "b"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			password, err := netrcPassword(strings.NewReader(tc.netrc), tc.host)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.password, password)
+		})
+	}
+}
+
+func TestResolveTokenFromNetrc(t *testing.T) {
+	dir, err := ioutil.TempDir("", "netrc")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	oldEnv, hadEnv := os.LookupEnv(NetrcEnvVar)
+	oldTokenEnv, hadTokenEnv := os.LookupEnv(TokenFileEnvVar)
+	defer func() {
+		if hadEnv {
+			os.Setenv(NetrcEnvVar, oldEnv)
+		} else {
+			os.Unsetenv(NetrcEnvVar)
+		}
+		if hadTokenEnv {
+			os.Setenv(TokenFileEnvVar, oldTokenEnv)
+		} else {
+			os.Unsetenv(TokenFileEnvVar)
+		}
+	}()
+	os.Unsetenv(TokenFileEnvVar)
+
+	path := filepath.Join(dir, "netrc")
+	err = ioutil.WriteFile(path, []byte(sampleNetrc), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	v := viper.New()
+	v.Set("apibaseurl", "https://api.exercism.io/v1")
+	cfg := Config{UserViperConfig: v, Home: dir}
+
+	// There is no .netrc in the home directory.
+	os.Unsetenv(NetrcEnvVar)
+	token, err := ResolveToken("", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "", token)
+
+	os.Setenv(NetrcEnvVar, path)
+	token, err = ResolveToken("", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "exercism-token", token)
+
+	// A configured token takes precedence.
+	v.Set("token", "from-config")
+	token, err = ResolveToken("", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-config", token)
+}
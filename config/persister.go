@@ -1,6 +1,7 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -30,13 +31,26 @@ func (p FilePersister) Save(v *viper.Viper, basename string) error {
 		}
 	}
 
-	// WriteConfig is broken.
-	// Someone proposed a fix in https://github.com/spf13/viper/pull/503,
-	// but the fix doesn't work yet.
-	// When it's fixed and merged we can get rid of `path`
-	// and use viperConfig.WriteConfig() directly.
+	// We write the file ourselves rather than with viper, so that it is
+	// indented and its keys are sorted, which makes it easy to read and diff,
+	// e.g. for people who keep their dotfiles in version control.
+	// It replaces the old file in one go, so that the token is never lost.
+	b, err := marshalConfig(v)
+	if err != nil {
+		return err
+	}
 	path := filepath.Join(p.Dir, fmt.Sprintf("%s.json", basename))
-	return v.WriteConfigAs(path)
+	return WriteFileAtomic(path, b, os.FileMode(0644))
+}
+
+// marshalConfig formats the settings of a viper config as indented JSON.
+// Keys are sorted at every level, since encoding/json sorts map keys.
+func marshalConfig(v *viper.Viper) ([]byte, error) {
+	b, err := json.MarshalIndent(v.AllSettings(), "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(b, '\n'), nil
 }
 
 // InMemoryPersister is a noop persister for use in unit tests.
@@ -0,0 +1,41 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilePersisterWritesSortedIndentedJSON(t *testing.T) {
+	dir, err := ioutil.TempDir("", "persister")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	v := viper.New()
+	v.Set("workspace", "/exercism")
+	v.Set("token", "abc123")
+	v.Set("apibaseurl", "https://api.exercism.io/v1")
+	v.Set("nested", map[string]string{"zebra": "z", "aardvark": "a"})
+
+	err = FilePersister{Dir: filepath.Join(dir, "config")}.Save(v, "user")
+	assert.NoError(t, err)
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "config", "user.json"))
+	assert.NoError(t, err)
+
+	expected := `{
+  "apibaseurl": "https://api.exercism.io/v1",
+  "nested": {
+    "aardvark": "a",
+    "zebra": "z"
+  },
+  "token": "abc123",
+  "workspace": "/exercism"
+}
+`
+	assert.Equal(t, expected, string(b))
+}
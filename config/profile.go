@@ -0,0 +1,115 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// DefaultProfileName is the profile whose settings are kept in the config
+// dir itself, as they were before there were profiles.
+const DefaultProfileName = "default"
+
+// profilesDirName holds a config dir of its own for each named profile,
+// so that each has its own token, workspace, API, and caches.
+const profilesDirName = "profiles"
+
+// defaultProfileFilename names the profile to use when none is given.
+const defaultProfileFilename = "default-profile"
+
+var profileNamePattern = regexp.MustCompile(`\A[a-z0-9]+(-[a-z0-9]+)*\z`)
+
+// ValidateProfileName ensures that a profile name is well formed.
+// Names are lowercase letters and digits, separated by single dashes.
+func ValidateProfileName(name string) error {
+	if !profileNamePattern.MatchString(name) {
+		return fmt.Errorf("invalid profile name '%s'. Use lowercase letters, digits, and dashes, e.g. work", name)
+	}
+	return nil
+}
+
+// ProfileDir is the config dir of the named profile, within the config dir.
+func ProfileDir(dir, name string) string {
+	if name == "" || name == DefaultProfileName {
+		return dir
+	}
+	return filepath.Join(dir, profilesDirName, name)
+}
+
+// ProfileExists determines whether the named profile has been configured.
+func ProfileExists(dir, name string) bool {
+	if name == "" || name == DefaultProfileName {
+		return true
+	}
+	_, err := os.Stat(filepath.Join(ProfileDir(dir, name), "user.json"))
+	return err == nil
+}
+
+// Profiles lists the profiles in the config dir, starting with the default one.
+func Profiles(dir string) ([]string, error) {
+	profiles := []string{DefaultProfileName}
+	entries, err := ioutil.ReadDir(filepath.Join(dir, profilesDirName))
+	if os.IsNotExist(err) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, entry := range entries {
+		if entry.IsDir() && ProfileExists(dir, entry.Name()) {
+			names = append(names, entry.Name())
+		}
+	}
+	sort.Strings(names)
+	return append(profiles, names...), nil
+}
+
+// DefaultProfile is the profile to use when none is given.
+func DefaultProfile(dir string) string {
+	b, err := ioutil.ReadFile(filepath.Join(dir, defaultProfileFilename))
+	if err != nil {
+		return DefaultProfileName
+	}
+	name := strings.TrimSpace(string(b))
+	if ValidateProfileName(name) != nil {
+		return DefaultProfileName
+	}
+	return name
+}
+
+// SetDefaultProfile makes the named profile the one to use when none is given.
+func SetDefaultProfile(dir, name string) error {
+	path := filepath.Join(dir, defaultProfileFilename)
+	if name == DefaultProfileName {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := os.MkdirAll(dir, os.FileMode(0755)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, []byte(name+"\n"), os.FileMode(0644))
+}
+
+// UseProfile points the config at the named profile, or at the default
+// profile if there's no name.
+func (c *Config) UseProfile(name string) error {
+	if name == "" {
+		name = DefaultProfile(c.Dir)
+	}
+	if err := ValidateProfileName(name); err != nil {
+		return err
+	}
+	c.Profile = name
+	c.Dir = ProfileDir(c.Dir, name)
+	if _, ok := c.Persister.(FilePersister); ok {
+		c.Persister = FilePersister{Dir: c.Dir}
+	}
+	return nil
+}
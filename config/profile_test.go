@@ -0,0 +1,86 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateProfileName(t *testing.T) {
+	for _, name := range []string{"work", "self-hosted", "site2"} {
+		assert.NoError(t, ValidateProfileName(name), name)
+	}
+	for _, name := range []string{"", "Work", "-work", "work-", "a--b", "../work", "a b"} {
+		assert.Error(t, ValidateProfileName(name), name)
+	}
+}
+
+func TestProfiles(t *testing.T) {
+	dir, err := ioutil.TempDir("", "profiles")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	profiles, err := Profiles(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfileName}, profiles)
+
+	for _, name := range []string{"work", "home"} {
+		assert.NoError(t, os.MkdirAll(ProfileDir(dir, name), os.FileMode(0755)))
+		assert.NoError(t, ioutil.WriteFile(filepath.Join(ProfileDir(dir, name), "user.json"), []byte("{}"), os.FileMode(0644)))
+	}
+	// A profile that was never configured isn't listed.
+	assert.NoError(t, os.MkdirAll(ProfileDir(dir, "unused"), os.FileMode(0755)))
+
+	profiles, err = Profiles(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{DefaultProfileName, "home", "work"}, profiles)
+	assert.True(t, ProfileExists(dir, "work"))
+	assert.False(t, ProfileExists(dir, "unused"))
+	assert.True(t, ProfileExists(dir, DefaultProfileName))
+}
+
+func TestDefaultProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "default-profile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	assert.Equal(t, DefaultProfileName, DefaultProfile(dir))
+
+	assert.NoError(t, SetDefaultProfile(dir, "work"))
+	assert.Equal(t, "work", DefaultProfile(dir))
+
+	assert.NoError(t, SetDefaultProfile(dir, DefaultProfileName))
+	assert.Equal(t, DefaultProfileName, DefaultProfile(dir))
+	_, err = os.Stat(filepath.Join(dir, defaultProfileFilename))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestUseProfile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "use-profile")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	cfg := Config{Dir: dir, Persister: FilePersister{Dir: dir}}
+	assert.NoError(t, cfg.UseProfile(""))
+	assert.Equal(t, DefaultProfileName, cfg.Profile)
+	assert.Equal(t, dir, cfg.Dir)
+
+	assert.NoError(t, SetDefaultProfile(dir, "work"))
+	cfg = Config{Dir: dir, Persister: FilePersister{Dir: dir}}
+	assert.NoError(t, cfg.UseProfile(""))
+	assert.Equal(t, "work", cfg.Profile)
+	assert.Equal(t, filepath.Join(dir, "profiles", "work"), cfg.Dir)
+	assert.Equal(t, FilePersister{Dir: cfg.Dir}, cfg.Persister)
+
+	// A profile that's given wins over the default one.
+	cfg = Config{Dir: dir, Persister: InMemoryPersister{}}
+	assert.NoError(t, cfg.UseProfile(DefaultProfileName))
+	assert.Equal(t, dir, cfg.Dir)
+	assert.Equal(t, InMemoryPersister{}, cfg.Persister)
+
+	cfg = Config{Dir: dir}
+	assert.Error(t, cfg.UseProfile("../elsewhere"))
+}
@@ -0,0 +1,42 @@
+package config
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// TokenFileEnvVar names the environment variable that can point to a token file.
+const TokenFileEnvVar = "EXERCISM_TOKEN_FILE"
+
+// ReadTokenFile reads an API token from a file, such as a mounted secret.
+// Trailing whitespace, including the final newline, is ignored.
+func ReadTokenFile(path string) (string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimRight(string(b), " \t\r\n")
+	if token == "" {
+		return "", fmt.Errorf("the token file %s is empty", path)
+	}
+	return token, nil
+}
+
+// ResolveToken determines which token to use.
+// A token file given explicitly takes precedence, then one named by the
+// EXERCISM_TOKEN_FILE environment variable, then the configured token,
+// and finally the password for the API host in the user's .netrc file.
+func ResolveToken(tokenFile string, cfg Config) (string, error) {
+	if tokenFile == "" {
+		tokenFile = os.Getenv(TokenFileEnvVar)
+	}
+	if tokenFile != "" {
+		return ReadTokenFile(Resolve(tokenFile, cfg.Home))
+	}
+	if token := cfg.UserViperConfig.GetString("token"); token != "" {
+		return token, nil
+	}
+	return NetrcToken(cfg, cfg.UserViperConfig.GetString("apibaseurl"))
+}
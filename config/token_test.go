@@ -0,0 +1,90 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReadTokenFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "token-file")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	testCases := []struct {
+		desc     string
+		contents string
+		token    string
+	}{
+		{desc: "bare", contents: "abc123", token: "abc123"},
+		{desc: "trailing newline", contents: "abc123\n", token: "abc123"},
+		{desc: "windows line ending", contents: "abc123\r\n", token: "abc123"},
+		{desc: "trailing whitespace", contents: "abc123 \t\n\n", token: "abc123"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			path := filepath.Join(dir, "token")
+			err := ioutil.WriteFile(path, []byte(tc.contents), os.FileMode(0600))
+			assert.NoError(t, err)
+
+			token, err := ReadTokenFile(path)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.token, token)
+		})
+	}
+
+	path := filepath.Join(dir, "empty")
+	err = ioutil.WriteFile(path, []byte("\n"), os.FileMode(0600))
+	assert.NoError(t, err)
+	_, err = ReadTokenFile(path)
+	assert.Error(t, err)
+
+	_, err = ReadTokenFile(filepath.Join(dir, "missing"))
+	assert.True(t, os.IsNotExist(err))
+}
+
+func TestResolveToken(t *testing.T) {
+	dir, err := ioutil.TempDir("", "resolve-token")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	flagFile := filepath.Join(dir, "flag-token")
+	err = ioutil.WriteFile(flagFile, []byte("from-flag\n"), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	envFile := filepath.Join(dir, "env-token")
+	err = ioutil.WriteFile(envFile, []byte("from-env\n"), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	oldEnv, hadEnv := os.LookupEnv(TokenFileEnvVar)
+	defer func() {
+		if hadEnv {
+			os.Setenv(TokenFileEnvVar, oldEnv)
+		} else {
+			os.Unsetenv(TokenFileEnvVar)
+		}
+	}()
+
+	v := viper.New()
+	v.Set("token", "from-config")
+	cfg := Config{UserViperConfig: v, Home: dir}
+
+	os.Unsetenv(TokenFileEnvVar)
+	token, err := ResolveToken("", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-config", token)
+
+	os.Setenv(TokenFileEnvVar, envFile)
+	token, err = ResolveToken("", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-env", token)
+
+	token, err = ResolveToken("~/flag-token", cfg)
+	assert.NoError(t, err)
+	assert.Equal(t, "from-flag", token)
+}
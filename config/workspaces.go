@@ -0,0 +1,68 @@
+package config
+
+import (
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// workspacesKey holds workspace directories that are tied to an API base URL,
+// so that e.g. exercises downloaded from staging don't mix with production.
+// They're stored as a list rather than a map keyed by URL, because viper
+// treats the dots in a key as nesting.
+const workspacesKey = "workspaces"
+
+// apiWorkspace ties a workspace to an API base URL.
+type apiWorkspace struct {
+	API       string `json:"api"`
+	Workspace string `json:"workspace"`
+}
+
+func sameAPI(a, b string) bool {
+	return strings.EqualFold(strings.TrimRight(a, "/"), strings.TrimRight(b, "/"))
+}
+
+// apiWorkspaces reads the per-API workspaces from the config.
+// When read from a file, they come back as generic maps.
+func apiWorkspaces(v *viper.Viper) []apiWorkspace {
+	var workspaces []apiWorkspace
+	switch items := v.Get(workspacesKey).(type) {
+	case []apiWorkspace:
+		workspaces = append(workspaces, items...)
+	case []interface{}:
+		for _, item := range items {
+			m, ok := item.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			api, _ := m["api"].(string)
+			dir, _ := m["workspace"].(string)
+			workspaces = append(workspaces, apiWorkspace{API: api, Workspace: dir})
+		}
+	}
+	return workspaces
+}
+
+// WorkspaceFor provides the workspace for the configured API base URL.
+// If no workspace is tied to that API, it falls back to the default workspace.
+func WorkspaceFor(v *viper.Viper) string {
+	apiURL := v.GetString("apibaseurl")
+	for _, w := range apiWorkspaces(v) {
+		if sameAPI(w.API, apiURL) && w.Workspace != "" {
+			return w.Workspace
+		}
+	}
+	return v.GetString("workspace")
+}
+
+// SetWorkspaceFor ties a workspace to an API base URL,
+// replacing any workspace that was tied to it before.
+func SetWorkspaceFor(v *viper.Viper, apiURL, dir string) {
+	workspaces := []apiWorkspace{{API: strings.TrimRight(apiURL, "/"), Workspace: dir}}
+	for _, w := range apiWorkspaces(v) {
+		if !sameAPI(w.API, apiURL) {
+			workspaces = append(workspaces, w)
+		}
+	}
+	v.Set(workspacesKey, workspaces)
+}
@@ -0,0 +1,57 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWorkspaceFor(t *testing.T) {
+	v := viper.New()
+	v.Set("workspace", "/default")
+
+	v.Set("apibaseurl", "https://api.exercism.io/v1")
+	assert.Equal(t, "/default", WorkspaceFor(v))
+
+	SetWorkspaceFor(v, "https://staging.exercism.io/api/v1/", "/staging")
+	SetWorkspaceFor(v, "http://localhost:3000/api/v1", "/local")
+
+	testCases := []struct {
+		apiURL   string
+		expected string
+	}{
+		{"https://api.exercism.io/v1", "/default"},
+		{"https://staging.exercism.io/api/v1", "/staging"},
+		{"https://Staging.Exercism.io/api/v1/", "/staging"},
+		{"http://localhost:3000/api/v1", "/local"},
+	}
+	for _, tc := range testCases {
+		v.Set("apibaseurl", tc.apiURL)
+		assert.Equal(t, tc.expected, WorkspaceFor(v), tc.apiURL)
+	}
+}
+
+func TestWorkspaceForFromFile(t *testing.T) {
+	v := viper.New()
+	v.SetConfigType("json")
+	err := v.ReadConfig(strings.NewReader(`{
+		"workspace": "/default",
+		"apibaseurl": "https://staging.exercism.io/api/v1",
+		"workspaces": [{"api": "https://staging.exercism.io/api/v1", "workspace": "/staging"}]
+	}`))
+	assert.NoError(t, err)
+	assert.Equal(t, "/staging", WorkspaceFor(v))
+}
+
+func TestSetWorkspaceForReplaces(t *testing.T) {
+	v := viper.New()
+	v.Set("apibaseurl", "https://staging.exercism.io/api/v1")
+
+	SetWorkspaceFor(v, "https://staging.exercism.io/api/v1", "/old")
+	SetWorkspaceFor(v, "https://staging.exercism.io/api/v1/", "/new")
+
+	assert.Equal(t, "/new", WorkspaceFor(v))
+	assert.Len(t, apiWorkspaces(v), 1)
+}
@@ -37,11 +37,16 @@ func DumpRequest(req *http.Request) {
 		return
 	}
 
+	// Bodies of unknown length are streamed, so leave them for the server.
+	dumpBody := req.Body != nil && req.ContentLength > 0
+
 	var bodyCopy bytes.Buffer
-	body := io.TeeReader(req.Body, &bodyCopy)
-	req.Body = ioutil.NopCloser(body)
+	if dumpBody {
+		body := io.TeeReader(req.Body, &bodyCopy)
+		req.Body = ioutil.NopCloser(body)
+	}
 
-	dump, err := httputil.DumpRequest(req, req.ContentLength > 0)
+	dump, err := httputil.DumpRequest(req, dumpBody)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -51,7 +56,9 @@ func DumpRequest(req *http.Request) {
 	Println("========================= END DumpRequest =========================")
 	Println("")
 
-	req.Body = ioutil.NopCloser(&bodyCopy)
+	if dumpBody {
+		req.Body = ioutil.NopCloser(&bodyCopy)
+	}
 }
 
 // DumpResponse dumps out the provided http.Response
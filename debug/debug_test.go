@@ -2,6 +2,9 @@ package debug
 
 import (
 	"bytes"
+	"io"
+	"io/ioutil"
+	"net/http"
 	"testing"
 )
 
@@ -26,3 +29,30 @@ func TestVerboseDisabled(t *testing.T) {
 		t.Error("expected '' got", b.String())
 	}
 }
+
+func TestDumpRequestLeavesStreamedBodyAlone(t *testing.T) {
+	b := &bytes.Buffer{}
+	output = b
+	Verbose = true
+	defer func() { Verbose = false }()
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.Write([]byte("streamed"))
+		pw.Close()
+	}()
+	req, err := http.NewRequest("PATCH", "http://example.com", pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	DumpRequest(req)
+
+	body, err := ioutil.ReadAll(req.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "streamed" {
+		t.Error("expected 'streamed' got", string(body))
+	}
+}
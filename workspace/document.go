@@ -1,6 +1,14 @@
 package workspace
 
-import "path/filepath"
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
 
 // Document is a file in a directory.
 type Document struct {
@@ -10,18 +18,67 @@ type Document struct {
 
 // NewDocument creates a document from the filepath.
 // The root is typically the root of the exercise, and
-// path is the absolute path to the file.
+// path is the path to the file, either absolute or relative
+// to the current working directory.
+// Files in nested directories keep their position in the hierarchy,
+// so that e.g. root/lib/helper.rb has the relative path lib/helper.rb.
 func NewDocument(root, path string) (Document, error) {
-	path, err := filepath.Rel(root, path)
+	root, err := filepath.Abs(root)
 	if err != nil {
 		return Document{}, err
 	}
+	path, err = filepath.Abs(path)
+	if err != nil {
+		return Document{}, err
+	}
+
+	rel, err := filepath.Rel(root, path)
+	if err != nil || escapesRoot(rel) {
+		// The root and the path may reach the same directory through
+		// different symlinks, e.g. /tmp and /private/tmp on macOS.
+		if r, ok := resolvedRel(root, path); ok {
+			rel, err = r, nil
+		}
+	}
+	if err != nil {
+		return Document{}, err
+	}
+	if rel == "." {
+		return Document{}, fmt.Errorf("%s is the directory itself, not a file within it", path)
+	}
+	if escapesRoot(rel) {
+		return Document{}, fmt.Errorf("%s is not within %s", path, root)
+	}
 	return Document{
 		Root:         root,
-		RelativePath: path,
+		RelativePath: rel,
 	}, nil
 }
 
+// escapesRoot determines whether a relative path points outside of its root.
+func escapesRoot(rel string) bool {
+	return rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// resolvedRel computes the relative path after resolving symlinks in the root
+// and in the directory containing the file.
+// The file itself may be a symlink, and is left alone.
+func resolvedRel(root, path string) (string, bool) {
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", false
+	}
+	realDir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(realRoot, filepath.Join(realDir, filepath.Base(path)))
+	if err != nil || escapesRoot(rel) {
+		return "", false
+	}
+	return rel, true
+}
+
 // Filepath is the absolute path to the document on the filesystem.
 func (doc Document) Filepath() string {
 	return filepath.Join(doc.Root, doc.RelativePath)
@@ -32,3 +89,18 @@ func (doc Document) Filepath() string {
 func (doc Document) Path() string {
 	return filepath.ToSlash(doc.RelativePath)
 }
+
+// Checksum is the hex encoded SHA-256 digest of the document's contents.
+func (doc Document) Checksum() (string, error) {
+	f, err := os.Open(doc.Filepath())
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
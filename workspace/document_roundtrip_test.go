@@ -0,0 +1,108 @@
+// +build !windows
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestDocumentRoundTrip checks that every shape of input path produces a
+// document whose Path() is the logical path within the exercise, and whose
+// Filepath() refers to the original file.
+func TestDocumentRoundTrip(t *testing.T) {
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+
+	tmpDir, err := ioutil.TempDir("", "document-round-trip")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	// The workspace is reached through a symlink, as in e.g. /tmp on macOS.
+	realWorkspace := filepath.Join(tmpDir, "real-workspace")
+	linkedWorkspace := filepath.Join(tmpDir, "workspace")
+	realRoot := filepath.Join(realWorkspace, "bogus-track", "bogus-exercise")
+	linkedRoot := filepath.Join(linkedWorkspace, "bogus-track", "bogus-exercise")
+
+	err = os.MkdirAll(filepath.Join(realRoot, "lib", "deep"), os.FileMode(0755))
+	assert.NoError(t, err)
+	err = os.Symlink(realWorkspace, linkedWorkspace)
+	assert.NoError(t, err)
+
+	for _, name := range []string{"file.txt", filepath.Join("lib", "helper.rb"), filepath.Join("lib", "deep", "helper.rb")} {
+		err = ioutil.WriteFile(filepath.Join(realRoot, name), []byte(name), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	// A file outside the exercise, and a symlink to it from within.
+	shared := filepath.Join(tmpDir, "shared.txt")
+	err = ioutil.WriteFile(shared, []byte("shared"), os.FileMode(0644))
+	assert.NoError(t, err)
+	err = os.Symlink(shared, filepath.Join(realRoot, "lib", "shared.txt"))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		desc string
+		root string
+		cwd  string
+		path string
+		want string
+	}{
+		{desc: "absolute", root: realRoot, path: filepath.Join(realRoot, "file.txt"), want: "file.txt"},
+		{desc: "absolute nested", root: realRoot, path: filepath.Join(realRoot, "lib", "deep", "helper.rb"), want: "lib/deep/helper.rb"},
+		{desc: "unclean absolute", root: realRoot, path: realRoot + "/lib/./deep/../helper.rb", want: "lib/helper.rb"},
+		{desc: "relative", root: realRoot, cwd: realRoot, path: filepath.Join("lib", "helper.rb"), want: "lib/helper.rb"},
+		{desc: "relative from subdir", root: realRoot, cwd: filepath.Join(realRoot, "lib"), path: filepath.Join("deep", "helper.rb"), want: "lib/deep/helper.rb"},
+		{desc: "relative with dot-dot", root: realRoot, cwd: filepath.Join(realRoot, "lib", "deep"), path: filepath.Join("..", "..", "file.txt"), want: "file.txt"},
+		{desc: "root through symlink", root: linkedRoot, path: filepath.Join(realRoot, "lib", "helper.rb"), want: "lib/helper.rb"},
+		{desc: "path through symlink", root: realRoot, path: filepath.Join(linkedRoot, "lib", "helper.rb"), want: "lib/helper.rb"},
+		{desc: "both through symlink", root: linkedRoot, path: filepath.Join(linkedRoot, "file.txt"), want: "file.txt"},
+		{desc: "symlinked file", root: realRoot, path: filepath.Join(realRoot, "lib", "shared.txt"), want: "lib/shared.txt"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir := tc.cwd
+			if dir == "" {
+				dir = cwd
+			}
+			assert.NoError(t, os.Chdir(dir))
+
+			doc, err := NewDocument(tc.root, tc.path)
+			if !assert.NoError(t, err) {
+				return
+			}
+			assert.Equal(t, tc.want, doc.Path())
+			assert.False(t, strings.HasPrefix(doc.Path(), ".."))
+			assert.False(t, filepath.IsAbs(doc.Path()))
+			assert.False(t, strings.Contains(doc.Path(), "workspace"))
+
+			// Filepath() refers to the same file as the input.
+			expected, err := os.Stat(tc.path)
+			assert.NoError(t, err)
+			actual, err := os.Stat(doc.Filepath())
+			assert.NoError(t, err)
+			assert.True(t, os.SameFile(expected, actual), "%s is not %s", doc.Filepath(), tc.path)
+
+			// Path() leads back to Filepath() from the root.
+			assert.Equal(t, doc.Filepath(), filepath.Join(doc.Root, filepath.FromSlash(doc.Path())))
+		})
+	}
+
+	assert.NoError(t, os.Chdir(cwd))
+
+	_, err = NewDocument(realRoot, realRoot)
+	assert.Error(t, err, "the root itself is not a document")
+
+	_, err = NewDocument(realRoot, shared)
+	assert.Error(t, err, "files outside the root are not documents")
+
+	_, err = NewDocument(linkedRoot, filepath.Join(realWorkspace, "bogus-track", "other.txt"))
+	assert.Error(t, err, "symlinks don't let files escape the root")
+}
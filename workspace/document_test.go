@@ -17,6 +17,9 @@ func TestNormalizedDocumentPath(t *testing.T) {
 	err = os.MkdirAll(filepath.Join(root, "subdirectory"), os.FileMode(0755))
 	assert.NoError(t, err)
 
+	err = os.MkdirAll(filepath.Join(root, "lib", "helpers", "deep"), os.FileMode(0755))
+	assert.NoError(t, err)
+
 	testCases := []struct {
 		filepath string
 		path     string
@@ -29,6 +32,14 @@ func TestNormalizedDocumentPath(t *testing.T) {
 			filepath: filepath.Join(root, "subdirectory", "file.txt"),
 			path:     "subdirectory/file.txt",
 		},
+		{
+			filepath: filepath.Join(root, "lib", "helper.rb"),
+			path:     "lib/helper.rb",
+		},
+		{
+			filepath: filepath.Join(root, "lib", "helpers", "deep", "helper.rb"),
+			path:     "lib/helpers/deep/helper.rb",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -41,3 +52,34 @@ func TestNormalizedDocumentPath(t *testing.T) {
 		assert.Equal(t, doc.Path(), tc.path)
 	}
 }
+
+func TestDocumentOutsideRoot(t *testing.T) {
+	root := filepath.Join("path", "to", "exercise")
+
+	_, err := NewDocument(root, filepath.Join("path", "to", "other", "file.txt"))
+	assert.Error(t, err)
+
+	_, err = NewDocument(root, filepath.Join("path", "to"))
+	assert.Error(t, err)
+
+	doc, err := NewDocument(root, filepath.Join(root, "..file.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, "..file.txt", doc.Path())
+}
+
+func TestDocumentChecksum(t *testing.T) {
+	root, err := ioutil.TempDir("", "checksum")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "file.txt")
+	err = ioutil.WriteFile(path, []byte("hello"), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	doc, err := NewDocument(root, path)
+	assert.NoError(t, err)
+
+	checksum, err := doc.Checksum()
+	assert.NoError(t, err)
+	assert.Equal(t, "2cf24dba5fb0a30e26e83b2ac5b9e29e1b161e5c1fa7425e73043362938b9824", checksum)
+}
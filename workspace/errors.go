@@ -8,6 +8,9 @@ type ErrNotInWorkspace string
 // ErrNotExist signals that the target directory could not be located.
 type ErrNotExist string
 
+// ErrNotInExercise signals that the target directory is not within any exercise.
+type ErrNotInExercise string
+
 func (err ErrNotInWorkspace) Error() string {
 	return fmt.Sprintf("%s not within workspace", string(err))
 }
@@ -16,6 +19,10 @@ func (err ErrNotExist) Error() string {
 	return fmt.Sprintf("%s not found", string(err))
 }
 
+func (err ErrNotInExercise) Error() string {
+	return fmt.Sprintf("%s not within an exercise", string(err))
+}
+
 // IsNotInWorkspace checks if this is an ErrNotInWorkspace error.
 func IsNotInWorkspace(err error) bool {
 	_, ok := err.(ErrNotInWorkspace)
@@ -27,3 +34,9 @@ func IsNotExist(err error) bool {
 	_, ok := err.(ErrNotExist)
 	return ok
 }
+
+// IsNotInExercise checks if this is an ErrNotInExercise error.
+func IsNotInExercise(err error) bool {
+	_, ok := err.(ErrNotInExercise)
+	return ok
+}
@@ -14,8 +14,10 @@ type Exercise struct {
 	Documents []Document
 }
 
-// NewExerciseFromDir constructs an exercise given the exercise directory.
+// NewExerciseFromDir constructs an exercise given the exercise directory,
+// or its metadata directory.
 func NewExerciseFromDir(dir string) Exercise {
+	dir = exerciseDirOf(dir)
 	slug := filepath.Base(dir)
 	dir = filepath.Dir(dir)
 	track := filepath.Base(dir)
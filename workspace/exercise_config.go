@@ -0,0 +1,74 @@
+package workspace
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"path"
+	"path/filepath"
+)
+
+const exerciseConfigFilename = "config.json"
+
+// ExerciseConfig is the track's configuration for an exercise, which can be
+// shipped with it in the exercise config directory.
+type ExerciseConfig struct {
+	Files struct {
+		// Solution lists the files that make up the solution.
+		Solution []string `json:"solution"`
+		// Test lists the files with the tests for the exercise.
+		Test []string `json:"test"`
+		// Editor lists files that support the solution, such as headers
+		// or type definitions, which aren't meant to be changed.
+		Editor []string `json:"editor"`
+	} `json:"files"`
+}
+
+// ExerciseConfigPath is the location of the exercise config within an exercise directory.
+func ExerciseConfigPath(dir string) string {
+	return filepath.Join(dir, MetadataDirName, exerciseConfigFilename)
+}
+
+// NewExerciseConfig reads the exercise config from an exercise directory.
+// If there is none, the error satisfies os.IsNotExist.
+func NewExerciseConfig(dir string) (*ExerciseConfig, error) {
+	path := ExerciseConfigPath(dir)
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c ExerciseConfig
+	if err := json.Unmarshal(b, &c); err != nil {
+		return nil, fmt.Errorf("invalid exercise config in %s: %s", path, err)
+	}
+	return &c, nil
+}
+
+// IsSolution determines whether a file, given its path relative to the
+// exercise directory, is one of the solution files.
+// The entries may be exact paths or patterns such as *.go.
+func (c *ExerciseConfig) IsSolution(rel string) bool {
+	return matchesAny(c.Files.Solution, rel)
+}
+
+// IsTest determines whether a file, given its path relative to the
+// exercise directory, is one of the test files.
+func (c *ExerciseConfig) IsTest(rel string) bool {
+	return matchesAny(c.Files.Test, rel)
+}
+
+// IsEditor determines whether a file, given its path relative to the
+// exercise directory, is one of the editor files.
+func (c *ExerciseConfig) IsEditor(rel string) bool {
+	return matchesAny(c.Files.Editor, rel)
+}
+
+func matchesAny(patterns []string, rel string) bool {
+	rel = filepath.ToSlash(rel)
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, rel); ok {
+			return true
+		}
+	}
+	return false
+}
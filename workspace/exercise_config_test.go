@@ -0,0 +1,55 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewExerciseConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "exercise-config")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	_, err = NewExerciseConfig(dir)
+	assert.True(t, os.IsNotExist(err))
+
+	err = os.MkdirAll(filepath.Join(dir, MetadataDirName), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	err = ioutil.WriteFile(ExerciseConfigPath(dir), []byte(`{"files": {"solution": ["bogus.go", "lib/helper.go"], "test": ["bogus_test.go"]}}`), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	cfg, err := NewExerciseConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"bogus.go", "lib/helper.go"}, cfg.Files.Solution)
+	assert.Equal(t, []string{"bogus_test.go"}, cfg.Files.Test)
+
+	err = ioutil.WriteFile(ExerciseConfigPath(dir), []byte(`{"files": `), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	_, err = NewExerciseConfig(dir)
+	assert.Error(t, err)
+	assert.Regexp(t, "invalid exercise config", err.Error())
+}
+
+func TestExerciseConfigPatterns(t *testing.T) {
+	var cfg ExerciseConfig
+	cfg.Files.Solution = []string{"bob.go", "lib/*.go"}
+	cfg.Files.Test = []string{"*_test.go"}
+	cfg.Files.Editor = []string{"include/*.h"}
+
+	assert.True(t, cfg.IsSolution("bob.go"))
+	assert.True(t, cfg.IsSolution(filepath.Join("lib", "helper.go")))
+	assert.False(t, cfg.IsSolution("other.go"))
+	assert.False(t, cfg.IsSolution(filepath.Join("lib", "sub", "helper.go")))
+
+	assert.True(t, cfg.IsTest("bob_test.go"))
+	assert.False(t, cfg.IsTest("bob.go"))
+
+	assert.True(t, cfg.IsEditor(filepath.Join("include", "bob.h")))
+	assert.False(t, cfg.IsEditor("bob.h"))
+}
@@ -0,0 +1,136 @@
+package workspace
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// IgnoreFilename is the name of the file in an exercise directory that lists
+// the files to leave out of submissions, in the same syntax as .gitignore.
+const IgnoreFilename = ".exercismignore"
+
+// Ignore is a list of gitignore-style patterns.
+type Ignore struct {
+	patterns []ignorePattern
+}
+
+type ignorePattern struct {
+	// segments are the pattern's path elements. Unanchored patterns start with
+	// "**", so that they match at any depth.
+	segments []string
+	negate   bool
+	dirOnly  bool
+}
+
+// NewIgnore reads the ignore file of an exercise.
+// An exercise without one ignores nothing.
+func NewIgnore(dir string) (*Ignore, error) {
+	f, err := os.Open(filepath.Join(dir, IgnoreFilename))
+	if os.IsNotExist(err) {
+		return &Ignore{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	ig := &Ignore{}
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		ig.add(scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return ig, nil
+}
+
+// add parses a line of an ignore file.
+func (ig *Ignore) add(line string) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return
+	}
+
+	var p ignorePattern
+	if strings.HasPrefix(line, "!") {
+		p.negate = true
+		line = line[1:]
+	} else if strings.HasPrefix(line, `\`) {
+		// Escapes a leading # or !.
+		line = line[1:]
+	}
+	if strings.HasSuffix(line, "/") {
+		p.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return
+	}
+
+	// Patterns with a slash other than at the end are relative to the exercise.
+	// Any others match a name at any depth.
+	if strings.Contains(line, "/") {
+		line = strings.TrimPrefix(line, "/")
+	} else {
+		line = "**/" + line
+	}
+	p.segments = strings.Split(line, "/")
+	ig.patterns = append(ig.patterns, p)
+}
+
+// Match determines whether a path, relative to the exercise directory, is ignored.
+// As with git, a file in an ignored directory can't be included again by negating
+// a pattern for the file itself.
+func (ig *Ignore) Match(rel string, isDir bool) bool {
+	if ig == nil || len(ig.patterns) == 0 {
+		return false
+	}
+	elements := strings.Split(filepath.ToSlash(rel), "/")
+	for i := 1; i < len(elements); i++ {
+		if ig.match(elements[:i], true) {
+			return true
+		}
+	}
+	return ig.match(elements, isDir)
+}
+
+// match applies the patterns in order, so that later ones win.
+func (ig *Ignore) match(elements []string, isDir bool) bool {
+	ignored := false
+	for _, p := range ig.patterns {
+		if p.dirOnly && !isDir {
+			continue
+		}
+		if matchSegments(p.segments, elements) {
+			ignored = !p.negate
+		}
+	}
+	return ignored
+}
+
+// matchSegments matches path elements against pattern segments,
+// where "**" matches any number of elements, including none.
+func matchSegments(segments, elements []string) bool {
+	if len(segments) == 0 {
+		return len(elements) == 0
+	}
+	if segments[0] == "**" {
+		for i := 0; i <= len(elements); i++ {
+			if matchSegments(segments[1:], elements[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elements) == 0 {
+		return false
+	}
+	if ok, err := path.Match(segments[0], elements[0]); err != nil || !ok {
+		return false
+	}
+	return matchSegments(segments[1:], elements[1:])
+}
@@ -0,0 +1,82 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIgnoreMatch(t *testing.T) {
+	ig := &Ignore{}
+	lines := []string{
+		"# Build output",
+		"*.o",
+		"/build",
+		"node_modules/",
+		"docs/**/*.pdf",
+		"*.log",
+		"!keep.log",
+		`\#notes`,
+		"",
+	}
+	for _, line := range lines {
+		ig.add(line)
+	}
+
+	testCases := []struct {
+		path    string
+		isDir   bool
+		ignored bool
+	}{
+		{path: "main.go", ignored: false},
+		{path: "main.o", ignored: true},
+		{path: "lib/deep/util.o", ignored: true},
+		{path: "build", isDir: true, ignored: true},
+		{path: "build/output.bin", ignored: true},
+		{path: "lib/build/output.bin", ignored: false},
+		{path: "node_modules/left-pad/index.js", ignored: true},
+		{path: "lib/node_modules/left-pad/index.js", ignored: true},
+		{path: "node_modules", isDir: false, ignored: false},
+		{path: "docs/guide.pdf", ignored: true},
+		{path: "docs/a/b/guide.pdf", ignored: true},
+		{path: "guide.pdf", ignored: false},
+		{path: "debug.log", ignored: true},
+		{path: "keep.log", ignored: false},
+		{path: "#notes", ignored: true},
+		{path: "# Build output", ignored: false},
+	}
+	for _, tc := range testCases {
+		assert.Equal(t, tc.ignored, ig.Match(filepath.FromSlash(tc.path), tc.isDir), tc.path)
+	}
+}
+
+func TestIgnoreCannotIncludeFilesInIgnoredDirectories(t *testing.T) {
+	ig := &Ignore{}
+	ig.add("vendor/")
+	ig.add("!vendor/keep.go")
+
+	assert.True(t, ig.Match(filepath.FromSlash("vendor/keep.go"), false))
+}
+
+func TestNewIgnore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ignore")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// Without an ignore file, nothing is ignored.
+	ig, err := NewIgnore(dir)
+	assert.NoError(t, err)
+	assert.False(t, ig.Match("main.o", false))
+
+	err = ioutil.WriteFile(filepath.Join(dir, IgnoreFilename), []byte("*.o\r\ntmp/\r\n"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	ig, err = NewIgnore(dir)
+	assert.NoError(t, err)
+	assert.True(t, ig.Match("main.o", false))
+	assert.True(t, ig.Match(filepath.FromSlash("tmp/scratch.go"), false))
+	assert.False(t, ig.Match("main.go", false))
+}
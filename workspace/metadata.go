@@ -0,0 +1,144 @@
+package workspace
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// ErrInvalidMetadata signals that a solution metadata file is corrupted or malformed.
+type ErrInvalidMetadata struct {
+	Path   string
+	Reason string
+}
+
+func (err ErrInvalidMetadata) Error() string {
+	return fmt.Sprintf("invalid solution metadata in %s: %s", err.Path, err.Reason)
+}
+
+// IsInvalidMetadata checks if this is an ErrInvalidMetadata error.
+func IsInvalidMetadata(err error) bool {
+	_, ok := err.(ErrInvalidMetadata)
+	return ok
+}
+
+type metadataKind int
+
+const (
+	kindString metadataKind = iota
+	kindBool
+	kindNumber
+	kindTime
+	kindChecksums
+)
+
+// metadataFields describes the known fields of the solution metadata.
+// Unknown fields are ignored, so that newer metadata can be read by older versions.
+var metadataFields = []struct {
+	name     string
+	kind     metadataKind
+	required bool
+}{
+	{name: "id", kind: kindString, required: true},
+	{name: "track", kind: kindString},
+	{name: "exercise", kind: kindString},
+	{name: "team", kind: kindString},
+	{name: "url", kind: kindString},
+	{name: "handle", kind: kindString},
+	{name: "is_requester", kind: kindBool},
+	{name: "submitted_at", kind: kindTime},
+	{name: "auto_approve", kind: kindBool},
+	{name: "difficulty", kind: kindNumber},
+	{name: "blurb", kind: kindString},
+	{name: "status", kind: kindString},
+	{name: "checksums", kind: kindChecksums},
+}
+
+// ValidateMetadata checks that the solution metadata file at path is well formed.
+// The error describes exactly which field is missing or malformed.
+func ValidateMetadata(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	return validateMetadata(path, b)
+}
+
+func validateMetadata(path string, b []byte) error {
+	invalid := func(format string, a ...interface{}) error {
+		return ErrInvalidMetadata{Path: path, Reason: fmt.Sprintf(format, a...)}
+	}
+
+	if len(bytes.TrimSpace(b)) == 0 {
+		return invalid("the file is empty")
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(b, &fields); err != nil {
+		if e, ok := err.(*json.SyntaxError); ok {
+			line, col := position(b, e.Offset)
+			return invalid("malformed JSON at line %d, column %d: %s", line, col, e)
+		}
+		return invalid("expected a JSON object")
+	}
+
+	for _, field := range metadataFields {
+		raw, ok := fields[field.name]
+		if !ok || string(raw) == "null" {
+			if field.required {
+				return invalid("missing required field '%s'", field.name)
+			}
+			continue
+		}
+
+		switch field.kind {
+		case kindString:
+			var s string
+			if json.Unmarshal(raw, &s) != nil {
+				return invalid("field '%s' must be a string, got %s", field.name, raw)
+			}
+			if s == "" && field.required {
+				return invalid("field '%s' cannot be empty", field.name)
+			}
+		case kindBool:
+			var v bool
+			if json.Unmarshal(raw, &v) != nil {
+				return invalid("field '%s' must be true or false, got %s", field.name, raw)
+			}
+		case kindNumber:
+			var n int
+			if json.Unmarshal(raw, &n) != nil {
+				return invalid("field '%s' must be a whole number, got %s", field.name, raw)
+			}
+		case kindTime:
+			var t time.Time
+			if json.Unmarshal(raw, &t) != nil {
+				return invalid("field '%s' must be a timestamp like %s, got %s", field.name, time.RFC3339, raw)
+			}
+		case kindChecksums:
+			var m map[string]string
+			if json.Unmarshal(raw, &m) != nil {
+				return invalid("field '%s' must be an object mapping file paths to checksums, got %s", field.name, raw)
+			}
+		}
+	}
+	return nil
+}
+
+// position converts the offset reported by a JSON syntax error
+// into the 1-based line and column of the offending byte.
+func position(b []byte, offset int64) (int, int) {
+	idx := int(offset) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	if idx > len(b) {
+		idx = len(b)
+	}
+	before := b[:idx]
+	line := bytes.Count(before, []byte("\n")) + 1
+	col := idx - bytes.LastIndexByte(before, '\n')
+	return line, col
+}
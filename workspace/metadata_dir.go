@@ -0,0 +1,65 @@
+package workspace
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultMetadataDirName is the name of the metadata directory unless configured otherwise.
+const DefaultMetadataDirName = ".exercism"
+
+// MetadataDirName is the name of the directory within each exercise where
+// the CLI keeps its data, such as snapshots of submitted files and backups
+// of overwritten ones, and where the exercise config that the track ships
+// is kept. The workspace has one too, for downloads in progress.
+// The solution metadata stays at the root of the exercise, since that's
+// what marks a directory as an exercise.
+var MetadataDirName = DefaultMetadataDirName
+
+// SetMetadataDirName changes the name of the metadata directory.
+// An empty name restores the default.
+func SetMetadataDirName(name string) error {
+	if name == "" {
+		name = DefaultMetadataDirName
+	}
+	if err := ValidateMetadataDirName(name); err != nil {
+		return err
+	}
+	MetadataDirName = name
+	return nil
+}
+
+// ValidateMetadataDirName ensures that the name can be used for the metadata directory.
+// It must be a single path element.
+func ValidateMetadataDirName(name string) error {
+	if name == "" || name == "." || name == ".." || strings.ContainsAny(name, `/\`) {
+		return fmt.Errorf("invalid metadata directory name '%s'. It must be a plain directory name, e.g. %s", name, DefaultMetadataDirName)
+	}
+	return nil
+}
+
+// exerciseDirOf provides the exercise directory for a directory that may be
+// the metadata directory of the exercise rather than the exercise itself.
+func exerciseDirOf(dir string) string {
+	if filepath.Base(dir) == MetadataDirName {
+		return filepath.Dir(dir)
+	}
+	return dir
+}
+
+// outsideMetadataDir moves a path within the metadata directory of an
+// exercise up to the exercise directory, so that nothing in there, such as
+// the backup of an overwritten file, is taken for a solution of its own.
+func outsideMetadataDir(path string) string {
+	for dir := path; filepath.Dir(dir) != dir; dir = filepath.Dir(dir) {
+		if filepath.Base(dir) != MetadataDirName {
+			continue
+		}
+		if _, err := os.Lstat(filepath.Join(filepath.Dir(dir), solutionFilename)); err == nil {
+			return filepath.Dir(dir)
+		}
+	}
+	return path
+}
@@ -0,0 +1,91 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetMetadataDirName(t *testing.T) {
+	defer SetMetadataDirName("")
+
+	assert.NoError(t, SetMetadataDirName(".exercism-cli"))
+	assert.Equal(t, ".exercism-cli", MetadataDirName)
+
+	for _, name := range []string{".", "..", "a/b", `a\b`} {
+		assert.Error(t, SetMetadataDirName(name), name)
+		assert.Equal(t, ".exercism-cli", MetadataDirName)
+	}
+
+	assert.NoError(t, SetMetadataDirName(""))
+	assert.Equal(t, DefaultMetadataDirName, MetadataDirName)
+}
+
+func TestCustomMetadataDirName(t *testing.T) {
+	defer SetMetadataDirName("")
+	assert.NoError(t, SetMetadataDirName(".exercism-cli"))
+
+	tmpDir, err := ioutil.TempDir("", "metadata-dir")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+	ws, err := New(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(ws.Dir, "bogus-track", "bogus-exercise")
+	metadataDir := filepath.Join(dir, ".exercism-cli")
+	err = os.MkdirAll(metadataDir, os.FileMode(0755))
+	assert.NoError(t, err)
+	solution := &Solution{ID: "bogus-id", Track: "bogus-track", Exercise: "bogus-exercise", URL: "http://example.com/bogus-id", Checksums: map[string]string{"file.txt": "bogus"}}
+	assert.NoError(t, solution.Write(dir))
+
+	// The metadata directory belongs to its exercise.
+	exercise := NewExerciseFromDir(metadataDir)
+	assert.Equal(t, "bogus-track/bogus-exercise", exercise.Path())
+	s, err := NewSolution(metadataDir)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, s.Dir)
+
+	// Even what looks like a solution in there.
+	backup := filepath.Join(metadataDir, "backups", "20180101-000000")
+	err = os.MkdirAll(backup, os.FileMode(0755))
+	assert.NoError(t, err)
+	assert.NoError(t, (&Solution{ID: "older-id", Track: "bogus-track", Exercise: "older", URL: "http://example.com/older-id"}).Write(backup))
+	solutionDir, err := ws.SolutionDir(backup)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, solutionDir)
+	loc, err := Discover(backup)
+	assert.NoError(t, err)
+	assert.Equal(t, "bogus-exercise", loc.Solution.Exercise)
+
+	// The exercise config is read from the metadata directory.
+	assert.Equal(t, filepath.Join(metadataDir, "config.json"), ExerciseConfigPath(dir))
+	err = ioutil.WriteFile(filepath.Join(metadataDir, "config.json"), []byte(`{"files": {"solution": ["file.txt"]}}`), os.FileMode(0644))
+	assert.NoError(t, err)
+	c, err := NewExerciseConfig(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"file.txt"}, c.Files.Solution)
+
+	// So are the snapshots.
+	path := filepath.Join(dir, "file.txt")
+	err = ioutil.WriteFile(path, []byte("content"), os.FileMode(0644))
+	assert.NoError(t, err)
+	doc, err := NewDocument(dir, path)
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(metadataDir, "snapshots", "file.txt.gz"), doc.SnapshotPath())
+	assert.NoError(t, doc.WriteSnapshot())
+	restored, err := RestoreSnapshots(s, filepath.Join(tmpDir, "restored"))
+	assert.NoError(t, err)
+	assert.Len(t, restored, 1)
+
+	// None of it is part of the solution.
+	files, err := CollectFiles(dir, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{path}, files)
+	assert.True(t, IsMetadata(filepath.Join(".exercism-cli", "config.json")))
+
+	_, err = os.Stat(filepath.Join(dir, DefaultMetadataDirName))
+	assert.True(t, os.IsNotExist(err))
+}
@@ -0,0 +1,103 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateMetadata(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		metadata string
+		reason   string
+	}{
+		{
+			desc:     "valid",
+			metadata: `{"id":"abc","track":"bash","exercise":"bob","is_requester":true,"submitted_at":"2018-08-20T10:11:12Z","difficulty":3,"checksums":{"bob.sh":"abc123"},"unknown":[1,2,3]}`,
+		},
+		{
+			desc:     "minimal",
+			metadata: `{"id":"abc"}`,
+		},
+		{
+			desc:     "empty file",
+			metadata: "  \n",
+			reason:   "the file is empty",
+		},
+		{
+			desc:     "syntax error",
+			metadata: "{\n  \"id\": \"abc\",\n  \"track\" \"bash\"\n}",
+			reason:   "malformed JSON at line 3, column 11",
+		},
+		{
+			desc:     "not an object",
+			metadata: `["abc"]`,
+			reason:   "expected a JSON object",
+		},
+		{
+			desc:     "missing id",
+			metadata: `{"track":"bash","exercise":"bob"}`,
+			reason:   "missing required field 'id'",
+		},
+		{
+			desc:     "empty id",
+			metadata: `{"id":""}`,
+			reason:   "field 'id' cannot be empty",
+		},
+		{
+			desc:     "wrong type for string",
+			metadata: `{"id":"abc","track":42}`,
+			reason:   "field 'track' must be a string, got 42",
+		},
+		{
+			desc:     "wrong type for bool",
+			metadata: `{"id":"abc","is_requester":"yes"}`,
+			reason:   `field 'is_requester' must be true or false, got "yes"`,
+		},
+		{
+			desc:     "wrong type for number",
+			metadata: `{"id":"abc","difficulty":"hard"}`,
+			reason:   `field 'difficulty' must be a whole number, got "hard"`,
+		},
+		{
+			desc:     "malformed timestamp",
+			metadata: `{"id":"abc","submitted_at":"yesterday"}`,
+			reason:   `field 'submitted_at' must be a timestamp`,
+		},
+		{
+			desc:     "malformed checksums",
+			metadata: `{"id":"abc","checksums":["abc123"]}`,
+			reason:   `field 'checksums' must be an object`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "validate-metadata")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			path := filepath.Join(dir, solutionFilename)
+			err = ioutil.WriteFile(path, []byte(tc.metadata), os.FileMode(0600))
+			assert.NoError(t, err)
+
+			err = ValidateMetadata(path)
+			if tc.reason == "" {
+				assert.NoError(t, err)
+				return
+			}
+			if assert.Error(t, err) {
+				assert.True(t, IsInvalidMetadata(err))
+				assert.Contains(t, err.Error(), path)
+				assert.Contains(t, err.Error(), tc.reason)
+			}
+
+			_, err = NewSolution(dir)
+			assert.True(t, IsInvalidMetadata(err))
+		})
+	}
+}
@@ -0,0 +1,8 @@
+package workspace
+
+// IsNetworkPath makes a best-effort guess at whether the path lives on a
+// network filesystem, where I/O may be slow.
+// It returns false whenever it can't tell.
+func IsNetworkPath(path string) bool {
+	return isNetworkPath(path)
+}
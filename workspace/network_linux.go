@@ -0,0 +1,23 @@
+package workspace
+
+import "syscall"
+
+// Filesystem magic numbers, from statfs(2).
+var networkFilesystems = map[uint32]bool{
+	0x6969:     true, // NFS
+	0x517B:     true, // SMB
+	0xFF534D42: true, // CIFS
+	0xFE534D42: true, // SMB2
+	0x564C:     true, // NCP
+	0x5346414F: true, // AFS
+	0x73757245: true, // Coda
+	0x01021997: true, // 9P, e.g. WSL and VM shared folders
+}
+
+func isNetworkPath(path string) bool {
+	var st syscall.Statfs_t
+	if err := syscall.Statfs(path, &st); err != nil {
+		return false
+	}
+	return networkFilesystems[uint32(st.Type)]
+}
@@ -0,0 +1,8 @@
+// +build !linux,!darwin,!windows
+
+package workspace
+
+// isNetworkPath is a no-op where detection isn't supported.
+func isNetworkPath(string) bool {
+	return false
+}
@@ -0,0 +1,20 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsNetworkPath(t *testing.T) {
+	dir, err := ioutil.TempDir("", "network")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	// It never fails, even for paths that don't exist.
+	assert.False(t, IsNetworkPath(filepath.Join(dir, "no", "such", "path")))
+	assert.False(t, IsNetworkPath(""))
+}
@@ -0,0 +1,33 @@
+package workspace
+
+import (
+	"path/filepath"
+	"strings"
+	"syscall"
+	"unsafe"
+)
+
+const driveRemote = 4
+
+var getDriveType = syscall.NewLazyDLL("kernel32.dll").NewProc("GetDriveTypeW")
+
+func isNetworkPath(path string) bool {
+	volume := filepath.VolumeName(path)
+	if volume == "" {
+		return false
+	}
+	// UNC paths such as \\server\share are always on the network.
+	if strings.HasPrefix(volume, `\\`) {
+		return true
+	}
+	if getDriveType.Find() != nil {
+		return false
+	}
+
+	root, err := syscall.UTF16PtrFromString(volume + `\`)
+	if err != nil {
+		return false
+	}
+	kind, _, _ := getDriveType.Call(uintptr(unsafe.Pointer(root)))
+	return kind == driveRemote
+}
@@ -0,0 +1,94 @@
+package workspace
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+// snapshotDir is where copies of submitted files are kept, within the metadata directory.
+const snapshotDir = "snapshots"
+
+// SnapshotPath is the location of the document's snapshot on the filesystem.
+func (doc Document) SnapshotPath() string {
+	return filepath.Join(doc.Root, MetadataDirName, snapshotDir, doc.RelativePath+".gz")
+}
+
+// Snapshot returns the contents of the document as it was last snapshotted.
+// If there is no snapshot, the error satisfies os.IsNotExist.
+func (doc Document) Snapshot() ([]byte, error) {
+	f, err := os.Open(doc.SnapshotPath())
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	r, err := gzip.NewReader(f)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return ioutil.ReadAll(r)
+}
+
+// WriteSnapshot stores a compressed copy of the document's current contents.
+func (doc Document) WriteSnapshot() error {
+	src, err := os.Open(doc.Filepath())
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := io.Copy(w, src); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+
+	path := doc.SnapshotPath()
+	if err := os.MkdirAll(filepath.Dir(path), os.FileMode(0755)); err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, buf.Bytes(), os.FileMode(0644))
+}
+
+// RestoreSnapshots copies the files that were last submitted for the solution
+// out of their snapshots and into dir, keeping their relative paths.
+// The returned documents are rooted in dir.
+// If nothing was submitted, or a snapshot is missing, the error satisfies os.IsNotExist.
+func RestoreSnapshots(solution *Solution, dir string) ([]Document, error) {
+	if len(solution.Checksums) == 0 {
+		return nil, &os.PathError{Op: "restore", Path: filepath.Join(solution.Dir, MetadataDirName, snapshotDir), Err: os.ErrNotExist}
+	}
+
+	paths := make([]string, 0, len(solution.Checksums))
+	for path := range solution.Checksums {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	docs := make([]Document, 0, len(paths))
+	for _, path := range paths {
+		rel := filepath.FromSlash(path)
+		b, err := Document{Root: solution.Dir, RelativePath: rel}.Snapshot()
+		if err != nil {
+			return nil, err
+		}
+		doc := Document{Root: dir, RelativePath: rel}
+		if err := os.MkdirAll(filepath.Dir(doc.Filepath()), os.FileMode(0755)); err != nil {
+			return nil, err
+		}
+		if err := ioutil.WriteFile(doc.Filepath(), b, os.FileMode(0644)); err != nil {
+			return nil, err
+		}
+		docs = append(docs, doc)
+	}
+	return docs, nil
+}
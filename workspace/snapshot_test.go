@@ -0,0 +1,82 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDocumentSnapshot(t *testing.T) {
+	root, err := ioutil.TempDir("", "snapshot")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	path := filepath.Join(root, "lib", "file.txt")
+	err = os.MkdirAll(filepath.Dir(path), os.FileMode(0755))
+	assert.NoError(t, err)
+	err = ioutil.WriteFile(path, []byte("first version"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	doc, err := NewDocument(root, path)
+	assert.NoError(t, err)
+
+	_, err = doc.Snapshot()
+	assert.True(t, os.IsNotExist(err))
+
+	assert.NoError(t, doc.WriteSnapshot())
+
+	err = ioutil.WriteFile(path, []byte("second version"), os.FileMode(0644))
+	assert.NoError(t, err)
+
+	b, err := doc.Snapshot()
+	assert.NoError(t, err)
+	assert.Equal(t, "first version", string(b))
+	assert.Equal(t, filepath.Join(root, ".exercism", "snapshots", "lib", "file.txt.gz"), doc.SnapshotPath())
+}
+
+func TestRestoreSnapshots(t *testing.T) {
+	root, err := ioutil.TempDir("", "restore-snapshots")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	dir := filepath.Join(root, "exercise")
+	dst := filepath.Join(root, "restored")
+	solution := &Solution{Dir: dir}
+
+	_, err = RestoreSnapshots(solution, dst)
+	assert.True(t, os.IsNotExist(err))
+
+	solution.Checksums = map[string]string{}
+	for _, rel := range []string{"file.txt", "lib/helper.txt"} {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		err = os.MkdirAll(filepath.Dir(path), os.FileMode(0755))
+		assert.NoError(t, err)
+		err = ioutil.WriteFile(path, []byte("submitted "+rel), os.FileMode(0644))
+		assert.NoError(t, err)
+
+		doc, err := NewDocument(dir, path)
+		assert.NoError(t, err)
+		assert.NoError(t, doc.WriteSnapshot())
+		solution.Checksums[doc.Path()] = "whatever"
+
+		err = ioutil.WriteFile(path, []byte("changed since"), os.FileMode(0644))
+		assert.NoError(t, err)
+	}
+
+	docs, err := RestoreSnapshots(solution, dst)
+	assert.NoError(t, err)
+	if assert.Len(t, docs, 2) {
+		assert.Equal(t, "lib/helper.txt", docs[1].Path())
+		b, err := ioutil.ReadFile(docs[1].Filepath())
+		assert.NoError(t, err)
+		assert.Equal(t, "submitted lib/helper.txt", string(b))
+		assert.Equal(t, dst, docs[1].Root)
+	}
+
+	solution.Checksums["missing.txt"] = "whatever"
+	_, err = RestoreSnapshots(solution, dst)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -16,30 +16,52 @@ const solutionFilename = ".solution.json"
 
 // Solution contains metadata about a user's solution.
 type Solution struct {
-	Track       string     `json:"track"`
-	Exercise    string     `json:"exercise"`
-	ID          string     `json:"id"`
-	Team        string     `json:"team,omitempty"`
-	URL         string     `json:"url"`
-	Handle      string     `json:"handle"`
-	IsRequester bool       `json:"is_requester"`
-	SubmittedAt *time.Time `json:"submitted_at,omitempty"`
-	Dir         string     `json:"-"`
-	AutoApprove bool       `json:"auto_approve"`
+	Track       string            `json:"track"`
+	Exercise    string            `json:"exercise"`
+	ID          string            `json:"id"`
+	Team        string            `json:"team,omitempty"`
+	URL         string            `json:"url"`
+	Handle      string            `json:"handle"`
+	IsRequester bool              `json:"is_requester"`
+	SubmittedAt *time.Time        `json:"submitted_at,omitempty"`
+	Dir         string            `json:"-"`
+	AutoApprove bool              `json:"auto_approve"`
+	Difficulty  int               `json:"difficulty,omitempty"`
+	Blurb       string            `json:"blurb,omitempty"`
+	Status      string            `json:"status,omitempty"`
+	Checksums   map[string]string `json:"checksums,omitempty"`
 }
 
 // NewSolution reads solution metadata from a file in the given directory.
+// Fields that are missing from older metadata files are left as zero values.
+// Malformed metadata results in an ErrInvalidMetadata describing the problem.
+// Metadata that was read before is reused until the file changes.
+// The dir may also be the exercise's metadata directory.
 func NewSolution(dir string) (*Solution, error) {
+	dir = exerciseDirOf(dir)
 	path := filepath.Join(dir, solutionFilename)
+	info, err := os.Stat(path)
+	if err != nil {
+		return &Solution{}, err
+	}
+	if s, ok := cachedSolutionFor(path, info); ok {
+		s.Dir = dir
+		return s, nil
+	}
+
 	b, err := ioutil.ReadFile(path)
 	if err != nil {
 		return &Solution{}, err
 	}
+	if err := validateMetadata(path, b); err != nil {
+		return &Solution{}, err
+	}
 	var s Solution
 	if err := json.Unmarshal(b, &s); err != nil {
 		return &Solution{}, err
 	}
 	s.Dir = dir
+	cacheSolution(path, info, &s)
 	return &s, nil
 }
 
@@ -69,6 +91,7 @@ func (s *Solution) Write(dir string) error {
 	}
 
 	path := filepath.Join(dir, solutionFilename)
+	uncacheSolution(path)
 
 	// Hack because ioutil.WriteFile fails on hidden files
 	visibility.ShowFile(path)
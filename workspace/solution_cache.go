@@ -0,0 +1,72 @@
+package workspace
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// solutionCache keeps parsed solution metadata for the lifetime of the process,
+// so that resolving the same exercise repeatedly, e.g. in batch operations or
+// while watching files, doesn't read and parse the metadata file every time.
+// An entry is only used while the file's size and modification time are unchanged.
+var solutionCache = struct {
+	sync.Mutex
+	entries map[string]cachedSolution
+}{entries: map[string]cachedSolution{}}
+
+type cachedSolution struct {
+	size     int64
+	modTime  time.Time
+	solution Solution
+}
+
+// cachedSolutionFor returns a copy of the cached solution for the metadata file,
+// if the file hasn't changed since it was cached.
+func cachedSolutionFor(path string, info os.FileInfo) (*Solution, bool) {
+	solutionCache.Lock()
+	defer solutionCache.Unlock()
+
+	entry, ok := solutionCache.entries[path]
+	if !ok || entry.size != info.Size() || !entry.modTime.Equal(info.ModTime()) {
+		return nil, false
+	}
+	return entry.solution.copy(), true
+}
+
+func cacheSolution(path string, info os.FileInfo, s *Solution) {
+	solutionCache.Lock()
+	defer solutionCache.Unlock()
+
+	solutionCache.entries[path] = cachedSolution{
+		size:     info.Size(),
+		modTime:  info.ModTime(),
+		solution: *s.copy(),
+	}
+}
+
+// uncacheSolution forgets the metadata file, e.g. because it was just written.
+// The modification time alone can't be trusted for that,
+// since some filesystems only record it to the second.
+func uncacheSolution(path string) {
+	solutionCache.Lock()
+	defer solutionCache.Unlock()
+
+	delete(solutionCache.entries, path)
+}
+
+// copy makes a deep copy, so that callers can't change each other's solutions.
+func (s *Solution) copy() *Solution {
+	c := *s
+	if s.SubmittedAt != nil {
+		t := *s.SubmittedAt
+		c.SubmittedAt = &t
+	}
+	if s.Checksums != nil {
+		c.Checksums = make(map[string]string, len(s.Checksums))
+		for k, v := range s.Checksums {
+			c.Checksums[k] = v
+		}
+	}
+	return &c
+}
@@ -0,0 +1,82 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSolutionCache(t *testing.T) {
+	dir, err := ioutil.TempDir("", "solution-cache")
+	assert.NoError(t, err)
+	defer os.RemoveAll(dir)
+
+	s := &Solution{
+		Track:     "a-track",
+		Exercise:  "bogus-exercise",
+		ID:        "abc",
+		Checksums: map[string]string{"file.txt": "123"},
+	}
+	assert.NoError(t, s.Write(dir))
+
+	s1, err := NewSolution(dir)
+	assert.NoError(t, err)
+
+	// Changing a returned solution doesn't affect the cache.
+	s1.Checksums["file.txt"] = "changed"
+	s2, err := NewSolution(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "123", s2.Checksums["file.txt"])
+
+	// Changes made to the file behind our back are noticed.
+	path := filepath.Join(dir, solutionFilename)
+	b, err := ioutil.ReadFile(path)
+	assert.NoError(t, err)
+	// Replace the file rather than overwriting it, since it's hidden on Windows.
+	assert.NoError(t, os.Remove(path))
+	err = ioutil.WriteFile(path, []byte(string(b)[:len(b)-1]+`,"team":"a-team"}`), os.FileMode(0600))
+	assert.NoError(t, err)
+	later := time.Now().Add(time.Minute)
+	assert.NoError(t, os.Chtimes(path, later, later))
+
+	s3, err := NewSolution(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "a-team", s3.Team)
+
+	// As are changes made through Write, even within the same second.
+	s3.Team = "another-team"
+	assert.NoError(t, s3.Write(dir))
+	assert.NoError(t, os.Chtimes(path, later, later))
+
+	s4, err := NewSolution(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, "another-team", s4.Team)
+}
+
+func BenchmarkNewSolution(b *testing.B) {
+	dir, err := ioutil.TempDir("", "solution-benchmark")
+	if err != nil {
+		b.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	checksums := map[string]string{}
+	for _, name := range []string{"a.go", "b.go", "c.go", "lib/d.go"} {
+		checksums[name] = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	}
+	s := &Solution{Track: "go", Exercise: "bogus-exercise", ID: "abc", Checksums: checksums}
+	if err := s.Write(dir); err != nil {
+		b.Fatal(err)
+	}
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := NewSolution(dir); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
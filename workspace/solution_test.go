@@ -3,6 +3,7 @@ package workspace
 import (
 	"io/ioutil"
 	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -42,6 +43,60 @@ func TestSolution(t *testing.T) {
 	assert.Equal(t, s2, s3)
 }
 
+func TestSolutionMetadataFields(t *testing.T) {
+	testCases := []struct {
+		desc     string
+		metadata string
+		expected Solution
+	}{
+		{
+			desc:     "full metadata",
+			metadata: `{"track":"bash","exercise":"bob","id":"abc","url":"http://example.com","handle":"alice","is_requester":true,"auto_approve":true,"difficulty":3,"blurb":"Bob is a lackadaisical teenager.","status":"published"}`,
+			expected: Solution{
+				Track:       "bash",
+				Exercise:    "bob",
+				ID:          "abc",
+				URL:         "http://example.com",
+				Handle:      "alice",
+				IsRequester: true,
+				AutoApprove: true,
+				Difficulty:  3,
+				Blurb:       "Bob is a lackadaisical teenager.",
+				Status:      "published",
+			},
+		},
+		{
+			desc:     "older metadata without the newer fields",
+			metadata: `{"track":"bash","exercise":"bob","id":"abc","url":"http://example.com","handle":"alice","is_requester":true}`,
+			expected: Solution{
+				Track:       "bash",
+				Exercise:    "bob",
+				ID:          "abc",
+				URL:         "http://example.com",
+				Handle:      "alice",
+				IsRequester: true,
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.desc, func(t *testing.T) {
+			dir, err := ioutil.TempDir("", "solution-metadata")
+			assert.NoError(t, err)
+			defer os.RemoveAll(dir)
+
+			err = ioutil.WriteFile(filepath.Join(dir, solutionFilename), []byte(tc.metadata), os.FileMode(0600))
+			assert.NoError(t, err)
+
+			s, err := NewSolution(dir)
+			assert.NoError(t, err)
+
+			tc.expected.Dir = dir
+			assert.Equal(t, &tc.expected, s)
+		})
+	}
+}
+
 func TestSuffix(t *testing.T) {
 	testCases := []struct {
 		solution Solution
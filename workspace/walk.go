@@ -0,0 +1,175 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// CollectFiles lists the files in an exercise directory, recursively.
+// The CLI's own data, i.e. the solution metadata and the metadata directory,
+// is left out.
+//
+// Symlinked files are included. Symlinked directories are skipped unless
+// dereference is true, in which case they are followed, but each directory
+// is only visited once, so that symlink cycles can't cause an endless loop.
+func CollectFiles(root string, dereference bool) ([]string, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, err
+	}
+	c := &collector{dereference: dereference, visited: []os.FileInfo{info}}
+	if err := c.walk(root, true); err != nil {
+		return nil, err
+	}
+	return c.files, nil
+}
+
+type collector struct {
+	dereference bool
+	// visited holds the directories that have been walked.
+	// os.SameFile compares them by device and inode, or the platform's equivalent.
+	visited []os.FileInfo
+	files   []string
+}
+
+func (c *collector) seen(info os.FileInfo) bool {
+	for _, v := range c.visited {
+		if os.SameFile(v, info) {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *collector) walk(dir string, isRoot bool) error {
+	entries, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if isRoot && isMetadataName(entry.Name()) {
+			continue
+		}
+
+		info := entry
+		if entry.Mode()&os.ModeSymlink != 0 {
+			info, err = os.Stat(path)
+			if err != nil {
+				// Broken links have nothing to submit.
+				continue
+			}
+			if info.IsDir() && !c.dereference {
+				continue
+			}
+		}
+
+		if !info.IsDir() {
+			if info.Mode().IsRegular() {
+				c.files = append(c.files, path)
+			}
+			continue
+		}
+		if c.seen(info) {
+			continue
+		}
+		c.visited = append(c.visited, info)
+		if err := c.walk(path, false); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsMetadata determines whether a path relative to the exercise directory
+// is the CLI's own data, i.e. the solution metadata or the metadata directory,
+// which also holds the track's exercise config.
+func IsMetadata(rel string) bool {
+	return isMetadataName(strings.Split(filepath.ToSlash(rel), "/")[0])
+}
+
+func isMetadataName(name string) bool {
+	return name == solutionFilename || name == MetadataDirName
+}
+
+// IsEditorFile determines whether a file is one of the swap, backup, or
+// lock files that editors keep next to the files being edited.
+func IsEditorFile(rel string) bool {
+	name := filepath.Base(rel)
+	switch {
+	case strings.HasSuffix(name, "~"):
+		return true // Emacs, Vim, and gedit backups
+	case strings.HasPrefix(name, ".#"):
+		return true // Emacs locks
+	case len(name) > 2 && strings.HasPrefix(name, "#") && strings.HasSuffix(name, "#"):
+		return true // Emacs auto-saves
+	case editorSwapExtension.MatchString(filepath.Ext(name)):
+		return true // Vim swap files
+	}
+	return false
+}
+
+// editorSwapExtension matches Vim's swap file extensions, .swp, .swo, and so on.
+var editorSwapExtension = regexp.MustCompile(`^\.sw[a-p]$`)
+
+// nonSolutionDirs hold tests, dependencies, build output, or other people's
+// solutions rather than a solution.
+var nonSolutionDirs = map[string]bool{
+	"test":         true,
+	"tests":        true,
+	"spec":         true,
+	"specs":        true,
+	"__tests__":    true,
+	"__pycache__":  true,
+	"node_modules": true,
+	"vendor":       true,
+	"target":       true,
+	"build":        true,
+	"dist":         true,
+	"bin":          true,
+	"obj":          true,
+	"community":    true,
+}
+
+// nonSolutionFiles are the documentation that comes with an exercise.
+var nonSolutionFiles = map[string]bool{
+	"readme.md": true,
+	"help.md":   true,
+	"hints.md":  true,
+	// Written by download --mentor-notes.
+	"mentor_notes.md": true,
+}
+
+// testNameSuffixes mark test files, e.g. bob_test.go, bob.spec.js, or BobTest.java.
+var testNameSuffixes = []string{"_test", "_tests", "_spec", ".test", ".spec", "-test", "-spec", "Test", "Tests", "Spec"}
+
+// IsLikelySolutionFile guesses whether a file in an exercise is part of the
+// solution, given its path relative to the exercise directory.
+// Tests, documentation, hidden files, dependencies, and build output are not.
+// It is a default for when the track doesn't say which files are the solution.
+func IsLikelySolutionFile(rel string) bool {
+	parts := strings.Split(filepath.ToSlash(rel), "/")
+	for _, dir := range parts[:len(parts)-1] {
+		if strings.HasPrefix(dir, ".") || nonSolutionDirs[strings.ToLower(dir)] {
+			return false
+		}
+	}
+
+	name := parts[len(parts)-1]
+	if strings.HasPrefix(name, ".") || nonSolutionFiles[strings.ToLower(name)] {
+		return false
+	}
+	base := strings.TrimSuffix(name, filepath.Ext(name))
+	if strings.HasPrefix(strings.ToLower(base), "test_") || strings.EqualFold(base, "test") || strings.EqualFold(base, "tests") {
+		return false
+	}
+	for _, suffix := range testNameSuffixes {
+		if strings.HasSuffix(base, suffix) && base != suffix {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,44 @@
+// +build !windows
+
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectFilesSymlinks(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "collect-files-symlinks")
+	assert.NoError(t, err)
+	defer os.RemoveAll(tmpDir)
+
+	root := filepath.Join(tmpDir, "exercise")
+	shared := filepath.Join(tmpDir, "shared")
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "lib"), os.FileMode(0755)))
+	assert.NoError(t, os.MkdirAll(shared, os.FileMode(0755)))
+
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(root, "file.txt"), []byte("file"), os.FileMode(0644)))
+	assert.NoError(t, ioutil.WriteFile(filepath.Join(shared, "shared.txt"), []byte("shared"), os.FileMode(0644)))
+
+	// A symlinked directory outside the exercise, a cycle, and a broken link.
+	assert.NoError(t, os.Symlink(shared, filepath.Join(root, "shared")))
+	assert.NoError(t, os.Symlink(root, filepath.Join(root, "lib", "loop")))
+	assert.NoError(t, os.Symlink(filepath.Join(root, "missing"), filepath.Join(root, "broken.txt")))
+
+	// By default, symlinked directories are skipped.
+	files, err := CollectFiles(root, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{filepath.Join(root, "file.txt")}, files)
+
+	// When dereferencing, they are followed, but the cycle is only walked once.
+	files, err = CollectFiles(root, true)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "file.txt"),
+		filepath.Join(root, "shared", "shared.txt"),
+	}, files)
+}
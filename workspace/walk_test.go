@@ -0,0 +1,93 @@
+package workspace
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCollectFiles(t *testing.T) {
+	root, err := ioutil.TempDir("", "collect-files")
+	assert.NoError(t, err)
+	defer os.RemoveAll(root)
+
+	for _, name := range []string{
+		"file.txt",
+		"lib/helper.txt",
+		"lib/deep/deeper.txt",
+		solutionFilename,
+		filepath.Join(MetadataDirName, "snapshots", "file.txt.gz"),
+	} {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), os.FileMode(0755)))
+		assert.NoError(t, ioutil.WriteFile(path, []byte(name), os.FileMode(0644)))
+	}
+
+	files, err := CollectFiles(root, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{
+		filepath.Join(root, "file.txt"),
+		filepath.Join(root, "lib", "deep", "deeper.txt"),
+		filepath.Join(root, "lib", "helper.txt"),
+	}, files)
+}
+
+func TestIsLikelySolutionFile(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"bob.go", true},
+		{"lib/helper.rb", true},
+		{"src/main/java/Bob.java", true},
+		{"Testament.cs", true},
+		{"bob_test.go", false},
+		{"bob.spec.js", false},
+		{"bob.test.ts", false},
+		{"test_bob.py", false},
+		{"src/test/java/BobTest.java", false},
+		{"BobTests.cs", false},
+		{"tests/bob.rs", false},
+		{"README.md", false},
+		{"HELP.md", false},
+		{".gitignore", false},
+		{".idea/workspace.xml", false},
+		{"node_modules/left-pad/index.js", false},
+		{"community/alice/bob.go", false},
+		{"MENTOR_NOTES.md", false},
+		{"target/debug/bob", false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, IsLikelySolutionFile(filepath.FromSlash(tc.path)), tc.path)
+	}
+}
+
+func TestIsMetadata(t *testing.T) {
+	assert.True(t, IsMetadata(".solution.json"))
+	assert.True(t, IsMetadata(filepath.Join(MetadataDirName, "config.json")))
+	assert.False(t, IsMetadata("bob.go"))
+	assert.False(t, IsMetadata(filepath.Join("lib", ".solution.json")))
+}
+
+func TestIsEditorFile(t *testing.T) {
+	testCases := []struct {
+		path     string
+		expected bool
+	}{
+		{"bob.go", false},
+		{"lib/#notes", false},
+		{".bob.go.swp", true},
+		{"lib/.helper.rb.swo", true},
+		{"bob.go~", true},
+		{".#bob.go", true},
+		{"#bob.go#", true},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, IsEditorFile(filepath.FromSlash(tc.path)), tc.path)
+	}
+}
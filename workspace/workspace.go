@@ -97,12 +97,13 @@ func (ws Workspace) Exercises() ([]Exercise, error) {
 
 // SolutionDir determines the root directory of a solution.
 // This is the directory that contains the solution metadata file.
+// Paths within the metadata directory of an exercise belong to that exercise.
 func (ws Workspace) SolutionDir(s string) (string, error) {
 	if !strings.HasPrefix(s, ws.Dir) {
-		return "", errors.New("not in workspace")
+		return "", ErrNotInWorkspace(s)
 	}
 
-	path := s
+	path := outsideMetadataDir(s)
 	for {
 		if path == ws.Dir {
 			return "", errMissingMetadata
@@ -116,3 +117,89 @@ func (ws Workspace) SolutionDir(s string) (string, error) {
 		path = filepath.Dir(path)
 	}
 }
+
+// Location describes the solution that a path belongs to.
+type Location struct {
+	// Dir is the root directory of the solution.
+	Dir string
+	// MetadataPath is the absolute path to the solution metadata file.
+	MetadataPath string
+	Exercise     Exercise
+	Solution     *Solution
+}
+
+// Locate determines which solution a path belongs to, and loads its metadata.
+// It builds on SolutionDir, so the same errors apply.
+func (ws Workspace) Locate(path string) (Location, error) {
+	dir, err := ws.SolutionDir(path)
+	if err != nil {
+		return Location{}, err
+	}
+	return newLocation(dir)
+}
+
+// Discover determines which solution a path belongs to, wherever it is.
+// Unlike Locate, it doesn't need a workspace: it walks up from the path to the
+// nearest directory with solution metadata. This finds exercises that are kept
+// outside the workspace, e.g. in a repository of their own.
+func Discover(path string) (Location, error) {
+	return DiscoverExercise(path, "", "")
+}
+
+// DiscoverExercise is like Discover, but skips the solutions that aren't for
+// the given track and exercise, either of which may be empty to match any.
+// This picks out one exercise when exercises are nested in one another.
+func DiscoverExercise(path, track, exercise string) (Location, error) {
+	for dir := outsideMetadataDir(path); ; dir = filepath.Dir(dir) {
+		if _, err := os.Lstat(filepath.Join(dir, solutionFilename)); err == nil {
+			loc, err := newLocation(dir)
+			if err != nil {
+				return Location{}, err
+			}
+			if (track == "" || loc.Solution.Track == track) && (exercise == "" || loc.Solution.Exercise == exercise) {
+				return loc, nil
+			}
+		}
+		if filepath.Dir(dir) == dir {
+			return Location{}, errMissingMetadata
+		}
+	}
+}
+
+// newLocation loads the metadata of the solution in dir.
+func newLocation(dir string) (Location, error) {
+	solution, err := NewSolution(dir)
+	if err != nil {
+		return Location{}, err
+	}
+	return Location{
+		Dir:          dir,
+		MetadataPath: filepath.Join(dir, solutionFilename),
+		Exercise:     NewExerciseFromDir(dir),
+		Solution:     solution,
+	}, nil
+}
+
+// FindExerciseFromCwd locates the exercise that the current working directory
+// belongs to, walking up from the nearest directory with solution metadata.
+func (ws Workspace) FindExerciseFromCwd() (Location, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return Location{}, err
+	}
+	cwd, err = filepath.EvalSymlinks(cwd)
+	if err != nil {
+		return Location{}, err
+	}
+
+	rel, err := filepath.Rel(ws.Dir, cwd)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return Location{}, ErrNotInWorkspace(cwd)
+	}
+
+	loc, err := ws.Locate(cwd)
+	if IsMissingMetadata(err) {
+		return Location{}, ErrNotInExercise(cwd)
+	}
+	return loc, err
+}
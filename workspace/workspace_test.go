@@ -137,3 +137,167 @@ func TestSolutionDir(t *testing.T) {
 		assert.Equal(t, filepath.Join(ws.Dir, "exercise"), dir, test.path)
 	}
 }
+
+func TestLocate(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "locate")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	dir := filepath.Join(tmpDir, "bogus-track", "bogus-exercise")
+	err = os.MkdirAll(filepath.Join(dir, "subdir"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	solution := &Solution{
+		ID:       "bogus-id",
+		Track:    "bogus-track",
+		Exercise: "bogus-exercise",
+	}
+	err = solution.Write(dir)
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "subdir", "file.txt")
+	err = ioutil.WriteFile(file, []byte("a file"), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	ws, err := New(tmpDir)
+	assert.NoError(t, err)
+
+	loc, err := ws.Locate(filepath.Join(ws.Dir, "bogus-track", "bogus-exercise", "subdir", "file.txt"))
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join(ws.Dir, "bogus-track", "bogus-exercise"), loc.Dir)
+	assert.Equal(t, filepath.Join(loc.Dir, solutionFilename), loc.MetadataPath)
+	assert.Equal(t, "bogus-track", loc.Exercise.Track)
+	assert.Equal(t, "bogus-exercise", loc.Exercise.Slug)
+	assert.Equal(t, loc.Dir, loc.Exercise.Filepath())
+	assert.Equal(t, "bogus-id", loc.Solution.ID)
+	assert.Equal(t, loc.Dir, loc.Solution.Dir)
+
+	err = os.MkdirAll(filepath.Join(ws.Dir, "no-metadata"), os.FileMode(0755))
+	assert.NoError(t, err)
+	_, err = ws.Locate(filepath.Join(ws.Dir, "no-metadata"))
+	assert.True(t, IsMissingMetadata(err))
+}
+
+func TestDiscover(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "discover")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	// A clone of an exercise, outside of any workspace.
+	dir := filepath.Join(tmpDir, "my-clone")
+	err = os.MkdirAll(filepath.Join(dir, "src"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	solution := &Solution{
+		ID:       "bogus-id",
+		Track:    "bogus-track",
+		Exercise: "bogus-exercise",
+	}
+	err = solution.Write(dir)
+	assert.NoError(t, err)
+
+	file := filepath.Join(dir, "src", "file.txt")
+	err = ioutil.WriteFile(file, []byte("a file"), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	_, err = Workspace{Dir: filepath.Join(tmpDir, "workspace")}.Locate(file)
+	assert.True(t, IsNotInWorkspace(err))
+
+	loc, err := Discover(file)
+	assert.NoError(t, err)
+	assert.Equal(t, dir, loc.Dir)
+	assert.Equal(t, dir, loc.Exercise.Filepath())
+	assert.Equal(t, "bogus-id", loc.Solution.ID)
+
+	err = os.MkdirAll(filepath.Join(tmpDir, "no-metadata"), os.FileMode(0755))
+	assert.NoError(t, err)
+	_, err = Discover(filepath.Join(tmpDir, "no-metadata"))
+	assert.True(t, IsMissingMetadata(err))
+}
+
+func TestFindExerciseFromCwd(t *testing.T) {
+	cwd, err := os.Getwd()
+	assert.NoError(t, err)
+	defer os.Chdir(cwd)
+
+	tmpDir, err := ioutil.TempDir("", "find-exercise")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	wsDir := filepath.Join(tmpDir, "workspace")
+	dir := filepath.Join(wsDir, "bogus-track", "bogus-exercise")
+	err = os.MkdirAll(filepath.Join(dir, "src", "nested"), os.FileMode(0755))
+	assert.NoError(t, err)
+
+	solution := &Solution{
+		ID:       "bogus-id",
+		Track:    "bogus-track",
+		Exercise: "bogus-exercise",
+	}
+	err = solution.Write(dir)
+	assert.NoError(t, err)
+
+	ws, err := New(wsDir)
+	assert.NoError(t, err)
+
+	for _, path := range []string{dir, filepath.Join(dir, "src", "nested")} {
+		assert.NoError(t, os.Chdir(path))
+
+		loc, err := ws.FindExerciseFromCwd()
+		assert.NoError(t, err)
+		assert.Equal(t, filepath.Join(ws.Dir, "bogus-track", "bogus-exercise"), loc.Dir)
+		assert.Equal(t, "bogus-exercise", loc.Exercise.Slug)
+		assert.Equal(t, "bogus-id", loc.Solution.ID)
+	}
+
+	// Within the workspace, but not in an exercise.
+	assert.NoError(t, os.Chdir(filepath.Join(wsDir, "bogus-track")))
+	_, err = ws.FindExerciseFromCwd()
+	assert.True(t, IsNotInExercise(err))
+
+	// Outside the workspace entirely.
+	assert.NoError(t, os.Chdir(tmpDir))
+	_, err = ws.FindExerciseFromCwd()
+	assert.True(t, IsNotInWorkspace(err))
+}
+
+func TestDiscoverExercise(t *testing.T) {
+	tmpDir, err := ioutil.TempDir("", "discover-exercise")
+	defer os.RemoveAll(tmpDir)
+	assert.NoError(t, err)
+
+	// An exercise with another one nested in it.
+	outer := filepath.Join(tmpDir, "outer")
+	inner := filepath.Join(outer, "inner")
+	err = os.MkdirAll(inner, os.FileMode(0755))
+	assert.NoError(t, err)
+
+	for dir, exercise := range map[string]string{outer: "outer-exercise", inner: "inner-exercise"} {
+		solution := &Solution{ID: exercise + "-id", Track: "bogus-track", Exercise: exercise}
+		err = solution.Write(dir)
+		assert.NoError(t, err)
+	}
+
+	file := filepath.Join(inner, "file.txt")
+	err = ioutil.WriteFile(file, []byte("a file"), os.FileMode(0600))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		track, exercise string
+		expected        string
+	}{
+		{"", "", inner},
+		{"bogus-track", "", inner},
+		{"", "inner-exercise", inner},
+		{"", "outer-exercise", outer},
+		{"bogus-track", "outer-exercise", outer},
+	}
+	for _, tc := range testCases {
+		loc, err := DiscoverExercise(file, tc.track, tc.exercise)
+		assert.NoError(t, err)
+		assert.Equal(t, tc.expected, loc.Dir)
+	}
+
+	_, err = DiscoverExercise(file, "other-track", "outer-exercise")
+	assert.True(t, IsMissingMetadata(err))
+}